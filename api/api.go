@@ -10,15 +10,22 @@ package api
 //
 // Contract:
 //   - Functions never return Go errors — errors are inside the Response envelope.
-//   - Functions are stateless — no shared mutable state.
+//   - Functions are stateless — no shared mutable state, apart from the
+//     opt-in result cache (EnableResultCache), which never changes results.
 //   - Functions are safe to call concurrently.
 //   - The AI functions (Refine, Translate) make outbound network calls.
 //     All other functions are pure local computation.
+//   - Every function that reads a CSV or calls out (Discover, Refine, Parse,
+//     Translate, Execute, Pipeline, Snapshot, Profile) has a ...Context
+//     variant that stops when the context is cancelled or its deadline passes.
 // ============================================================================
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/spektr-org/spektr/engine"
 	"github.com/spektr-org/spektr/helpers"
@@ -30,6 +37,27 @@ import (
 // Version is the current Spektr library version.
 const Version = "1.0.0"
 
+// resultCache memoizes Execute and Pipeline results once enabled.
+var resultCache atomic.Pointer[engine.ResultCache]
+
+// EnableResultCache makes Execute and Pipeline answer repeated queries over
+// identical data from an in-memory cache bounded by opts. Calling it again
+// replaces the cache. Long-running hosts (the HTTP server, WASM) call it once
+// at startup.
+func EnableResultCache(opts engine.CacheOptions) {
+	resultCache.Store(engine.NewResultCache(opts))
+}
+
+// hostLimits caps every Execute and Pipeline query once set.
+var hostLimits atomic.Pointer[engine.Limits]
+
+// EnforceLimits caps every Execute and Pipeline query, whatever limits the
+// request asks for; the tighter of the two applies per limit. Zero fields
+// leave that limit to the request.
+func EnforceLimits(limits engine.Limits) {
+	hostLimits.Store(&limits)
+}
+
 // ============================================================================
 // Health
 // ============================================================================
@@ -43,10 +71,15 @@ const Version = "1.0.0"
 //	// result.Data.Version == "1.0.0"
 //	// result.Data.Status  == "ready"
 func Health() Response[HealthResult] {
-	return ok(HealthResult{
+	result := HealthResult{
 		Version: Version,
 		Status:  "ready",
-	})
+	}
+	if cache := resultCache.Load(); cache != nil {
+		stats := cache.Stats()
+		result.Cache = &stats
+	}
+	return ok(result)
 }
 
 // ============================================================================
@@ -72,6 +105,11 @@ func Health() Response[HealthResult] {
 //	}
 //	schema := resp.Data
 func Discover(req DiscoverRequest) DiscoverResponse {
+	return DiscoverContext(context.Background(), req)
+}
+
+// DiscoverContext is Discover with cancellation.
+func DiscoverContext(ctx context.Context, req DiscoverRequest) DiscoverResponse {
 	if strings.TrimSpace(req.CSV) == "" {
 		return fail[schema.Config]("csv is required")
 	}
@@ -80,9 +118,10 @@ func Discover(req DiscoverRequest) DiscoverResponse {
 		Name:           req.Name,
 		SampleSize:     req.SampleSize,
 		RecoverColumns: req.RecoverColumns,
+		Profile:        req.Profile,
 	}
 
-	result, err := schema.DiscoverFromCSV([]byte(req.CSV), opts)
+	result, err := schema.DiscoverFromCSVContext(ctx, []byte(req.CSV), opts)
 	if err != nil {
 		return fail[schema.Config](fmt.Sprintf("discover failed: %s", err.Error()))
 	}
@@ -115,6 +154,11 @@ func Discover(req DiscoverRequest) DiscoverResponse {
 //	}
 //	refinedSchema := resp.Data
 func Refine(req RefineRequest) RefineResponse {
+	return RefineContext(context.Background(), req)
+}
+
+// RefineContext is Refine with cancellation.
+func RefineContext(ctx context.Context, req RefineRequest) RefineResponse {
 	if req.APIKey == "" {
 		return fail[schema.Config]("apiKey is required for refine")
 	}
@@ -127,7 +171,7 @@ func Refine(req RefineRequest) RefineResponse {
 		cfg.Endpoint = req.Endpoint
 	}
 
-	result, err := schema.Refine(&req.Schema, cfg)
+	result, err := schema.RefineContext(ctx, &req.Schema, cfg)
 	if err != nil {
 		return fail[schema.Config](fmt.Sprintf("refine failed: %s", err.Error()))
 	}
@@ -156,6 +200,11 @@ func Refine(req RefineRequest) RefineResponse {
 //	records := resp.Data.Records   // pass to Execute
 //	count   := resp.Data.Count
 func Parse(req ParseRequest) ParseResponse {
+	return ParseContext(context.Background(), req)
+}
+
+// ParseContext is Parse with cancellation.
+func ParseContext(ctx context.Context, req ParseRequest) ParseResponse {
 	if strings.TrimSpace(req.CSV) == "" {
 		return fail[ParseResult]("csv is required")
 	}
@@ -163,7 +212,16 @@ func Parse(req ParseRequest) ParseResponse {
 		return fail[ParseResult]("schema must have at least one dimension or measure")
 	}
 
-	records, err := helpers.ParseCSV([]byte(req.CSV), req.Schema)
+	var opts []helpers.ParseOption
+	if req.Strict {
+		opts = append(opts, helpers.Strict())
+	}
+	var records []engine.Record
+	r := schema.ContextReader(ctx, strings.NewReader(req.CSV))
+	report, err := helpers.StreamCSV(r, req.Schema, func(rec engine.Record) error {
+		records = append(records, rec)
+		return nil
+	}, opts...)
 	if err != nil {
 		return fail[ParseResult](fmt.Sprintf("parse failed: %s", err.Error()))
 	}
@@ -171,6 +229,7 @@ func Parse(req ParseRequest) ParseResponse {
 	return ok(ParseResult{
 		Records: records,
 		Count:   len(records),
+		Report:  report,
 	})
 }
 
@@ -200,6 +259,11 @@ func Parse(req ParseRequest) ParseResponse {
 //	}
 //	spec := resp.Data.QuerySpec   // pass to Execute
 func Translate(req TranslateRequest) TranslateResponse {
+	return TranslateContext(context.Background(), req)
+}
+
+// TranslateContext is Translate with cancellation.
+func TranslateContext(ctx context.Context, req TranslateRequest) TranslateResponse {
 	if strings.TrimSpace(req.Query) == "" {
 		return fail[TranslateResult]("query is required")
 	}
@@ -218,7 +282,7 @@ func Translate(req TranslateRequest) TranslateResponse {
 		Dimensions:  req.Summary.Dimensions,
 	}
 
-	result, err := t.TranslateWithSummary(req.Query, req.Schema, &summary)
+	result, err := t.TranslateWithSummaryContext(ctx, req.Query, req.Schema, &summary)
 	if err != nil {
 		return fail[TranslateResult](fmt.Sprintf("translate failed: %s", err.Error()))
 	}
@@ -255,26 +319,87 @@ func Translate(req TranslateRequest) TranslateResponse {
 //	// result.TableData   → render a table
 //	// result.Reply       → display as text summary
 func Execute(req ExecuteRequest) ExecuteResponse {
+	return ExecuteContext(context.Background(), req)
+}
+
+// ExecuteContext is Execute with cancellation.
+func ExecuteContext(ctx context.Context, req ExecuteRequest) ExecuteResponse {
 	if len(req.Records) == 0 {
 		return fail[engine.Result]("records is required and must not be empty")
 	}
 
+	return executeView(ctx, req.Spec, engine.NewSliceView(req.Records), req.Options)
+}
+
+// executeView runs spec over view with the request options.
+func executeView(ctx context.Context, spec engine.QuerySpec, view engine.RecordView, options *ExecuteOptions) ExecuteResponse {
+	var limits engine.Limits
 	opts := []engine.Option{}
-	if req.Options != nil {
-		if req.Options.DefaultMeasure != "" {
-			opts = append(opts, engine.WithDefaultMeasure(req.Options.DefaultMeasure))
+	if options != nil {
+		if options.DefaultMeasure != "" {
+			opts = append(opts, engine.WithDefaultMeasure(options.DefaultMeasure))
 		}
-		if req.Options.BaseCurrency != "" && len(req.Options.ExchangeRates) > 0 {
-			dim := req.Options.CurrencyDimension
+		if options.BaseCurrency != "" && (len(options.ExchangeRates) > 0 || options.RateHistory != "") {
+			dim := options.CurrencyDimension
 			if dim == "" {
 				dim = "currency"
 			}
-			opts = append(opts, engine.WithCurrency(req.Options.BaseCurrency, dim, req.Options.ExchangeRates))
+			opts = append(opts, engine.WithCurrency(options.BaseCurrency, dim, options.ExchangeRates))
+		}
+		if len(options.CurrencyMeasures) > 0 {
+			opts = append(opts, engine.WithCurrencyMeasures(options.CurrencyMeasures...))
+		}
+		for measure, unit := range options.MeasureUnits {
+			opts = append(opts, engine.WithMeasureUnit(measure, unit))
+		}
+		for measure, format := range options.MeasureFormats {
+			opts = append(opts, engine.WithMeasureFormat(measure, format))
+		}
+		if options.Locale != "" {
+			locale, found := engine.LookupLocale(options.Locale)
+			if !found {
+				return fail[engine.Result](fmt.Sprintf("unknown locale %q", options.Locale))
+			}
+			opts = append(opts, engine.WithLocale(locale))
+		}
+		if options.RateHistory != "" {
+			table, err := engine.LoadRatesCSV(strings.NewReader(options.RateHistory))
+			if err != nil {
+				return fail[engine.Result](fmt.Sprintf("invalid rateHistory: %s", err.Error()))
+			}
+			opts = append(opts, engine.WithDatedRates(table, options.RateDimension, options.RateMode))
+		}
+		if options.Calendar != nil {
+			opts = append(opts, engine.WithCalendar(*options.Calendar))
 		}
+		for dim, delim := range options.MultiValue {
+			opts = append(opts, engine.WithMultiValue(dim, delim))
+		}
+		for dim, order := range options.ValueOrders {
+			opts = append(opts, engine.WithValueOrder(dim, order))
+		}
+		if options.Parallelism > 0 {
+			opts = append(opts, engine.WithParallelism(options.Parallelism))
+		}
+		if options.Limits != nil {
+			limits = *options.Limits
+		}
+		if options.Explain {
+			opts = append(opts, engine.WithExplain())
+		}
+	}
+	if host := hostLimits.Load(); host != nil {
+		limits = tighterLimits(limits, *host)
+	}
+	if limits != (engine.Limits{}) {
+		opts = append(opts, engine.WithLimits(limits))
 	}
 
-	view := engine.NewSliceView(req.Records)
-	result, err := engine.Execute(req.Spec, view, opts...)
+	execute := engine.ExecuteContext
+	if cache := resultCache.Load(); cache != nil {
+		execute = cache.ExecuteContext
+	}
+	result, err := execute(ctx, spec, view, opts...)
 	if err != nil {
 		return fail[engine.Result](fmt.Sprintf("execute failed: %s", err.Error()))
 	}
@@ -309,9 +434,17 @@ func Execute(req ExecuteRequest) ExecuteResponse {
 //	    Mode:   api.PipelineModeAI,
 //	    APIKey: "AIza...",
 //	})
+//
+// Passing Snapshot instead of CSV skips the Discover and Parse steps.
 func Pipeline(req PipelineRequest) PipelineResponse {
-	if strings.TrimSpace(req.CSV) == "" {
-		return fail[PipelineResult]("csv is required")
+	return PipelineContext(context.Background(), req)
+}
+
+// PipelineContext is Pipeline with cancellation: every step stops mid-way
+// once ctx ends.
+func PipelineContext(ctx context.Context, req PipelineRequest) PipelineResponse {
+	if strings.TrimSpace(req.CSV) == "" && len(req.Snapshot) == 0 {
+		return fail[PipelineResult]("csv or snapshot is required")
 	}
 	if strings.TrimSpace(req.Query) == "" {
 		return fail[PipelineResult]("query is required")
@@ -320,24 +453,42 @@ func Pipeline(req PipelineRequest) PipelineResponse {
 		return fail[PipelineResult]("apiKey is required when mode is 'ai'")
 	}
 
-	// Step 1: Discover schema (skip if provided)
+	// Steps 1-2: Discover schema (skip if provided) and parse records,
+	// or load both from a snapshot
 	var sch schema.Config
-	if req.Schema != nil {
-		sch = *req.Schema
+	var view engine.RecordView
+	var report *helpers.ParseReport
+	if len(req.Snapshot) > 0 {
+		snap, err := helpers.LoadSnapshot(req.Snapshot)
+		if err != nil {
+			return fail[PipelineResult](fmt.Sprintf("snapshot load failed: %s", err.Error()))
+		}
+		sch, view = snap.Schema, snap.View
+		if req.Schema != nil {
+			sch = *req.Schema
+		}
 	} else {
-		discoverResp := Discover(DiscoverRequest{CSV: req.CSV})
-		if !discoverResp.OK {
-			return fail[PipelineResult](fmt.Sprintf("discover step failed: %s", discoverResp.Error))
+		if req.Schema != nil {
+			sch = *req.Schema
+		} else {
+			discoverResp := DiscoverContext(ctx, DiscoverRequest{CSV: req.CSV})
+			if !discoverResp.OK {
+				return fail[PipelineResult](fmt.Sprintf("discover step failed: %s", discoverResp.Error))
+			}
+			sch = *discoverResp.Data
 		}
-		sch = *discoverResp.Data
+
+		parseResp := ParseContext(ctx, ParseRequest{CSV: req.CSV, Schema: sch, Strict: req.Strict})
+		if !parseResp.OK {
+			return fail[PipelineResult](fmt.Sprintf("parse step failed: %s", parseResp.Error))
+		}
+		view = engine.NewSliceView(parseResp.Data.Records)
+		report = parseResp.Data.Report
 	}
 
-	// Step 2: Parse records
-	parseResp := Parse(ParseRequest{CSV: req.CSV, Schema: sch})
-	if !parseResp.OK {
-		return fail[PipelineResult](fmt.Sprintf("parse step failed: %s", parseResp.Error))
+	if err := ctx.Err(); err != nil {
+		return fail[PipelineResult](fmt.Sprintf("pipeline cancelled: %s", err.Error()))
 	}
-	records := parseResp.Data.Records
 
 	// Step 3: Translate query → QuerySpec
 	var spec engine.QuerySpec
@@ -347,8 +498,8 @@ func Pipeline(req PipelineRequest) PipelineResponse {
 	}
 
 	if mode == PipelineModeAI {
-		summary := SummaryFromRecords(records, sch)
-		translateResp := Translate(TranslateRequest{
+		summary := translator.BuildDataSummaryFromView(view, sch)
+		translateResp := TranslateContext(ctx, TranslateRequest{
 			Query:   req.Query,
 			Schema:  sch,
 			Summary: DataSummary{RecordCount: summary.RecordCount, Dimensions: summary.Dimensions},
@@ -367,33 +518,178 @@ func Pipeline(req PipelineRequest) PipelineResponse {
 	}
 
 	// Step 4: Execute
-	executeResp := Execute(ExecuteRequest{
-		Spec:    spec,
-		Records: records,
-	})
+	if view.Len() == 0 {
+		return fail[PipelineResult]("execute step failed: records is required and must not be empty")
+	}
+	options := schemaExecuteOptions(sch)
+	if req.Explain || req.Locale != "" {
+		if options == nil {
+			options = &ExecuteOptions{}
+		}
+		options.Explain = req.Explain
+		options.Locale = req.Locale
+	}
+	executeResp := executeView(ctx, spec, view, options)
 	if !executeResp.OK {
 		return fail[PipelineResult](fmt.Sprintf("execute step failed: %s", executeResp.Error))
 	}
 
 	return ok(PipelineResult{
 		Schema:      sch,
-		RecordCount: parseResp.Data.Count,
+		RecordCount: view.Len(),
+		ParseReport: report,
 		Query:       req.Query,
 		Spec:        spec,
 		Result:      *executeResp.Data,
 	})
 }
 
+// ============================================================================
+// Snapshot
+// ============================================================================
+
+// Snapshot encodes a CSV (and its schema) as a compact binary dataset.
+// Pipeline accepts the result in place of the CSV, skipping discovery and
+// parsing on every call.
+// Maps to: POST /snapshot
+//
+// Example:
+//
+//	resp := api.Snapshot(api.SnapshotRequest{CSV: csvContent})
+//	snapshot := resp.Data.Snapshot   // store it; later:
+//	api.Pipeline(api.PipelineRequest{Snapshot: snapshot, Query: "count records by status"})
+func Snapshot(req SnapshotRequest) SnapshotResponse {
+	return SnapshotContext(context.Background(), req)
+}
+
+// SnapshotContext is Snapshot with cancellation.
+func SnapshotContext(ctx context.Context, req SnapshotRequest) SnapshotResponse {
+	if strings.TrimSpace(req.CSV) == "" {
+		return fail[SnapshotResult]("csv is required")
+	}
+
+	var sch schema.Config
+	if req.Schema != nil {
+		sch = *req.Schema
+	} else {
+		discoverResp := DiscoverContext(ctx, DiscoverRequest{CSV: req.CSV})
+		if !discoverResp.OK {
+			return fail[SnapshotResult](fmt.Sprintf("discover step failed: %s", discoverResp.Error))
+		}
+		sch = *discoverResp.Data
+	}
+
+	view, report, err := helpers.ParseCSVColumnarReader(schema.ContextReader(ctx, strings.NewReader(req.CSV)), sch)
+	if err != nil {
+		return fail[SnapshotResult](fmt.Sprintf("parse failed: %s", err.Error()))
+	}
+	var buf bytes.Buffer
+	if err := helpers.SaveSnapshot(&buf, view, sch); err != nil {
+		return fail[SnapshotResult](fmt.Sprintf("snapshot failed: %s", err.Error()))
+	}
+	snap, err := helpers.LoadSnapshot(buf.Bytes())
+	if err != nil {
+		return fail[SnapshotResult](fmt.Sprintf("snapshot failed: %s", err.Error()))
+	}
+
+	return ok(SnapshotResult{
+		Snapshot:    buf.Bytes(),
+		Schema:      sch,
+		RecordCount: view.Len(),
+		ParseReport: report,
+		Stats:       snap.Stats,
+	})
+}
+
+// ============================================================================
+// Profile
+// ============================================================================
+
+// Profile computes per-column statistics over every row of a CSV.
+// Maps to: POST /profile
+//
+// Example:
+//
+//	resp := api.Profile(api.ProfileRequest{CSV: csvContent, Schema: &schema})
+//	for _, col := range resp.Data.Columns {
+//	    fmt.Println(col.Key, col.NullRate, col.Distinct)
+//	}
+func Profile(req ProfileRequest) ProfileResponse {
+	return ProfileContext(context.Background(), req)
+}
+
+// ProfileContext is Profile with cancellation.
+func ProfileContext(ctx context.Context, req ProfileRequest) ProfileResponse {
+	if strings.TrimSpace(req.CSV) == "" {
+		return fail[schema.Profile]("csv is required")
+	}
+
+	var sch schema.Config
+	if req.Schema != nil {
+		sch = *req.Schema
+	} else {
+		discoverResp := DiscoverContext(ctx, DiscoverRequest{CSV: req.CSV})
+		if !discoverResp.OK {
+			return fail[schema.Profile](fmt.Sprintf("discover step failed: %s", discoverResp.Error))
+		}
+		sch = *discoverResp.Data
+	}
+
+	profile, err := schema.ProfileCSVContext(ctx, []byte(req.CSV), sch)
+	if err != nil {
+		return fail[schema.Profile](fmt.Sprintf("profile failed: %s", err.Error()))
+	}
+	return ok(*profile)
+}
+
 // ============================================================================
 // HELPERS
 // ============================================================================
 
+// tighterLimits combines two limit sets, keeping the smaller non-zero value
+// of each limit.
+func tighterLimits(a, b engine.Limits) engine.Limits {
+	tighter := func(x, y int) int {
+		if x == 0 || (y != 0 && y < x) {
+			return y
+		}
+		return x
+	}
+	return engine.Limits{
+		MaxRecords: tighter(a.MaxRecords, b.MaxRecords),
+		MaxGroups:  tighter(a.MaxGroups, b.MaxGroups),
+		MaxRows:    tighter(a.MaxRows, b.MaxRows),
+	}
+}
+
 // SummaryFromRecords builds a translator.DataSummary from parsed records.
 // Only dimension sample values and record count are included.
 // Raw measure values are never sent to the AI.
 func SummaryFromRecords(records []engine.Record, sch schema.Config) translator.DataSummary {
 	return *translator.BuildDataSummaryFromRecords(records, sch)
 }
+
+// schemaExecuteOptions carries schema settings the engine needs (calendar,
+// multi-value dimensions, value orders, currency measures, measure units and
+// formats) into ExecuteOptions. Returns nil when there are none.
+func schemaExecuteOptions(sch schema.Config) *ExecuteOptions {
+	opts := &ExecuteOptions{
+		MultiValue:       helpers.MultiValueDelimiters(sch),
+		ValueOrders:      helpers.ValueOrders(sch),
+		CurrencyMeasures: helpers.CurrencyMeasures(sch),
+		MeasureUnits:     helpers.MeasureUnits(sch),
+		MeasureFormats:   helpers.MeasureFormats(sch),
+	}
+	if sch.Calendar != nil {
+		cal := helpers.Calendar(*sch.Calendar)
+		opts.Calendar = &cal
+	}
+	if opts.Calendar == nil && opts.MultiValue == nil && opts.ValueOrders == nil && opts.CurrencyMeasures == nil && opts.MeasureUnits == nil && opts.MeasureFormats == nil {
+		return nil
+	}
+	return opts
+}
+
 // buildLocalSpec constructs a basic QuerySpec from a plain query string.
 // Supports simple patterns: "sum <measure> by <dimension>",
 // "count records by <dimension>", "avg <measure> by <dimension>".
@@ -422,7 +718,7 @@ func buildLocalSpec(query string, sch schema.Config) engine.QuerySpec {
 	}
 
 	// Parse aggregation keyword
-	for _, agg := range []string{"sum", "avg", "min", "max", "count"} {
+	for _, agg := range []string{"sum", "avg", "median", "min", "max", "distinct", "count"} {
 		if strings.Contains(lower, agg) {
 			spec.Aggregation = agg
 			break
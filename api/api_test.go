@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spektr-org/spektr/schema"
+)
+
+// ============================================================================
+// PARSE TESTS
+// ============================================================================
+
+const parseCSV = `Region,Amount,Region
+North,10,x
+South,abc,x
+East,5
+West,N/A,x
+`
+
+var parseSchema = schema.Config{
+	Dimensions: []schema.DimensionMeta{{Key: "region"}},
+	Measures:   []schema.MeasureMeta{{Key: "amount"}},
+}
+
+func TestParseReport(t *testing.T) {
+	resp := Parse(ParseRequest{CSV: parseCSV, Schema: parseSchema})
+	if !resp.OK {
+		t.Fatalf("Parse failed: %s", resp.Error)
+	}
+	report := resp.Data.Report
+	if resp.Data.Count != 3 || report == nil || report.Rows != 3 {
+		t.Fatalf("count = %d, report = %+v, want 3 rows", resp.Data.Count, report)
+	}
+	if report.RaggedRows != 1 || len(report.SkippedRows) != 1 || report.SkippedRows[0].Line != 4 {
+		t.Errorf("ragged = %d, skipped = %+v, want line 4", report.RaggedRows, report.SkippedRows)
+	}
+	if len(report.DuplicateHeaders) != 1 {
+		t.Errorf("DuplicateHeaders = %v, want [Region]", report.DuplicateHeaders)
+	}
+	if c := report.Columns["amount"]; c == nil || c.Unparseable != 1 || c.Empty != 1 {
+		t.Errorf("amount report = %+v, want 1 unparseable and 1 empty (N/A)", c)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	resp := Parse(ParseRequest{CSV: parseCSV, Schema: parseSchema, Strict: true})
+	if resp.OK || !strings.Contains(resp.Error, "duplicate header") {
+		t.Errorf("strict Parse = ok %v, error %q; want a duplicate header error", resp.OK, resp.Error)
+	}
+
+	resp = Parse(ParseRequest{CSV: "Region,Amount\nNorth,10\nSouth,N/A\nEast,\n", Schema: parseSchema, Strict: true})
+	if !resp.OK || resp.Data.Count != 3 {
+		t.Errorf("strict Parse with null markers = %+v, want 3 records", resp)
+	}
+}
+
+func TestSnapshotParseReport(t *testing.T) {
+	resp := Snapshot(SnapshotRequest{CSV: parseCSV, Schema: &parseSchema})
+	if !resp.OK {
+		t.Fatalf("Snapshot failed: %s", resp.Error)
+	}
+	report := resp.Data.ParseReport
+	if report == nil || report.Rows != 3 || report.RaggedRows != 1 || len(report.DuplicateHeaders) != 1 {
+		t.Fatalf("report = %+v, want 3 rows, 1 ragged, 1 duplicate header", report)
+	}
+	if got := report.Columns["amount"]; got == nil || got.Unparseable != 1 || got.Empty != 1 {
+		t.Errorf("amount report = %+v, want 1 unparseable and 1 empty", got)
+	}
+}
+
+func TestContextVariantsStopWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	csv := "Region,Amount\n" + strings.Repeat("North,10\nSouth,5\n", 5000)
+
+	errs := map[string]string{
+		"discover": DiscoverContext(ctx, DiscoverRequest{CSV: csv}).Error,
+		"parse":    ParseContext(ctx, ParseRequest{CSV: csv, Schema: parseSchema}).Error,
+		"snapshot": SnapshotContext(ctx, SnapshotRequest{CSV: csv, Schema: &parseSchema}).Error,
+		"profile":  ProfileContext(ctx, ProfileRequest{CSV: csv, Schema: &parseSchema}).Error,
+	}
+	for name, msg := range errs {
+		if !strings.Contains(msg, context.Canceled.Error()) {
+			t.Errorf("%s error = %q, want it to report cancellation", name, msg)
+		}
+	}
+	if resp := Parse(ParseRequest{CSV: csv, Schema: parseSchema}); !resp.OK || resp.Data.Count != 10000 {
+		t.Errorf("Parse without a context: ok = %v, count = %d, want 10000 rows", resp.OK, resp.Data.Count)
+	}
+}
@@ -20,6 +20,7 @@ package api
 
 import (
 	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/helpers"
 	"github.com/spektr-org/spektr/schema"
 )
 
@@ -53,6 +54,9 @@ func fail[T any](err string) Response[T] {
 type HealthResult struct {
 	Version string `json:"version"`
 	Status  string `json:"status"`
+
+	// Cache reports result cache hit/miss counters when the cache is enabled.
+	Cache *engine.CacheStats `json:"cache,omitempty"`
 }
 
 // ============================================================================
@@ -68,13 +72,16 @@ type DiscoverRequest struct {
 	// Name overrides the auto-inferred dataset name.
 	Name string `json:"name,omitempty"`
 
-	// SampleSize limits how many rows are inspected during discovery.
-	// Defaults to 1000. Set to 0 to inspect all rows.
+	// SampleSize is how many rows discovery samples from across the whole
+	// file. Defaults to 1000. Set to 0 to inspect all rows.
 	SampleSize int `json:"sampleSize,omitempty"`
 
 	// RecoverColumns is a list of column names that were auto-skipped
 	// but should be force-included as dimensions.
 	RecoverColumns []string `json:"recoverColumns,omitempty"`
+
+	// Profile attaches per-column statistics to the schema (see Profile).
+	Profile bool `json:"profile,omitempty"`
 }
 
 // DiscoverResponse is the output of the Discover function.
@@ -119,6 +126,10 @@ type ParseRequest struct {
 	// Schema describes how to classify columns. Required.
 	// Typically the output from Discover or Refine.
 	Schema schema.Config `json:"schema"`
+
+	// Strict fails the parse on the first malformed or ragged row, duplicate
+	// header or unparseable number, instead of skipping it and reporting it.
+	Strict bool `json:"strict,omitempty"`
 }
 
 // ParseResult is the data payload returned by Parse.
@@ -128,6 +139,11 @@ type ParseResult struct {
 
 	// Count is the number of records parsed.
 	Count int `json:"count"`
+
+	// Report lists, per column, how many cells were empty vs unparseable
+	// (those cells are missing, not zero, in Records), and the rows and
+	// duplicate headers that were left out.
+	Report *helpers.ParseReport `json:"report,omitempty"`
 }
 
 // ParseResponse is the output of the Parse function.
@@ -209,6 +225,69 @@ type ExecuteOptions struct {
 
 	// ExchangeRates maps currency codes to their rate relative to BaseCurrency.
 	ExchangeRates map[string]float64 `json:"exchangeRates,omitempty"`
+
+	// CurrencyMeasures lists the measures holding currency amounts. Only
+	// these are converted; others (hours, quantities) pass through. Omit to
+	// treat every measure as an amount. Pipeline fills it from the schema's
+	// isCurrency flags.
+	CurrencyMeasures []string `json:"currencyMeasures,omitempty"`
+
+	// MeasureUnits maps measures to the unit their values are stored in
+	// ("ms", "hours", "bytes", "percent", "points"). Values display
+	// auto-scaled ("3.2 h", "1.4 GB") and these measures are never
+	// currency-converted. Pipeline fills it from the schema's measure units.
+	MeasureUnits map[string]string `json:"measureUnits,omitempty"`
+
+	// MeasureFormats maps measures to number patterns ("#,##0.00", "0.0%")
+	// setting their display precision. Pipeline fills it from the schema's
+	// measure formats.
+	MeasureFormats map[string]string `json:"measureFormats,omitempty"`
+
+	// Locale formats display strings — values, table cells, replies, period
+	// labels — for a locale ("en-US", "en-IN", "de-DE", "fr-FR"). Omit for
+	// the classic "SGD 1,234.56" style.
+	Locale string `json:"locale,omitempty"`
+
+	// RateHistory is a dated rate table as CSV text in the
+	// "date,currency,rate" layout. Each record converts at the rate in force
+	// on its date; ExchangeRates, if set, is the fallback. Requires
+	// BaseCurrency. The rates used are reported in result.rateDates.
+	RateHistory string `json:"rateHistory,omitempty"`
+
+	// RateDimension is the temporal dimension dating each record for
+	// RateHistory. Defaults to the calendar dimension ("month").
+	RateDimension string `json:"rateDimension,omitempty"`
+
+	// RateMode picks a record's rate: "previous" (default, the latest rate
+	// on or before the record's date) or "average" (the mean rate within the
+	// record's period).
+	RateMode engine.RateMode `json:"rateMode,omitempty"`
+
+	// Calendar sets the fiscal year start, week start and time zone used for
+	// temporal grouping and relative time ranges. Defaults to Gregorian/ISO.
+	Calendar *engine.Calendar `json:"calendar,omitempty"`
+
+	// MultiValue maps tag-style dimensions to their delimiter
+	// (e.g. {"labels": ";"}). Filters match any tag; groups overlap.
+	MultiValue map[string]string `json:"multiValue,omitempty"`
+
+	// ValueOrders gives the ordinal order of dimension values for the
+	// "ordinal" sort (e.g. {"priority": ["P1", "P2", "P3"]}).
+	ValueOrders map[string][]string `json:"valueOrders,omitempty"`
+
+	// Parallelism shards filtering and aggregation over this many goroutines.
+	// 0 (default) runs sequentially. Results do not depend on the value.
+	Parallelism int `json:"parallelism,omitempty"`
+
+	// Limits fails the query with a limit error instead of scanning, grouping
+	// or returning more than allowed. Hosts may impose tighter limits
+	// (EnforceLimits).
+	Limits *engine.Limits `json:"limits,omitempty"`
+
+	// Explain attaches an execution trace (result.trace): the path taken,
+	// rows before and after each filter, currency rates, group counts and
+	// per-stage timings. Explain runs bypass the result cache.
+	Explain bool `json:"explain,omitempty"`
 }
 
 // ExecuteRequest is the input for the Execute function.
@@ -251,9 +330,13 @@ const (
 // Use this for stateless / one-shot requests.
 // Use the individual functions when caching schema or records between queries.
 type PipelineRequest struct {
-	// CSV is the raw CSV content. Required.
+	// CSV is the raw CSV content. Required unless Snapshot is set.
 	CSV string `json:"csv"`
 
+	// Snapshot is a dataset snapshot (see Snapshot), used in place of CSV.
+	// Its embedded schema is used unless Schema is set. Base64 in JSON.
+	Snapshot []byte `json:"snapshot,omitempty"`
+
 	// Query is the question to answer against the data. Required.
 	Query string `json:"query"`
 
@@ -269,17 +352,30 @@ type PipelineRequest struct {
 	// Schema allows the consumer to supply a pre-built or cached schema,
 	// skipping the Discover step. Optional.
 	Schema *schema.Config `json:"schema,omitempty"`
+
+	// Explain attaches an execution trace to result.trace (see ExecuteOptions).
+	Explain bool `json:"explain,omitempty"`
+
+	// Locale formats the result's display strings (see ExecuteOptions).
+	Locale string `json:"locale,omitempty"`
+
+	// Strict fails the parse step on bad rows or cells (see ParseRequest).
+	Strict bool `json:"strict,omitempty"`
 }
 
 // PipelineResult is the data payload returned by Pipeline.
 type PipelineResult struct {
-	// Schema is the schema used for this pipeline run.
-	// Either discovered from the CSV or the schema passed in the request.
+	// Schema is the schema used for this pipeline run. Either discovered
+	// from the CSV, read from the snapshot, or the schema passed in the request.
 	Schema schema.Config `json:"schema"`
 
 	// RecordCount is the number of records parsed from the CSV.
 	RecordCount int `json:"recordCount"`
 
+	// ParseReport describes what the parse step left out. Absent when the
+	// data came from a snapshot.
+	ParseReport *helpers.ParseReport `json:"parseReport,omitempty"`
+
 	// Query is the original query string from the request.
 	Query string `json:"query"`
 
@@ -291,4 +387,57 @@ type PipelineResult struct {
 }
 
 // PipelineResponse is the output of the Pipeline function.
-type PipelineResponse = Response[PipelineResult]
\ No newline at end of file
+type PipelineResponse = Response[PipelineResult]
+
+// ============================================================================
+// Snapshot
+// ============================================================================
+
+// SnapshotRequest is the input for the Snapshot function.
+type SnapshotRequest struct {
+	// CSV is the raw CSV content to encode. Required.
+	CSV string `json:"csv"`
+
+	// Schema classifies the columns. Discovered from the CSV when omitted.
+	Schema *schema.Config `json:"schema,omitempty"`
+}
+
+// SnapshotResult is the data payload returned by Snapshot.
+type SnapshotResult struct {
+	// Snapshot is the encoded dataset, schema included. Base64 in JSON.
+	// Pass it to Pipeline in place of the CSV.
+	Snapshot []byte `json:"snapshot"`
+
+	// Schema is the schema stored in the snapshot.
+	Schema schema.Config `json:"schema"`
+
+	// RecordCount is the number of records encoded.
+	RecordCount int `json:"recordCount"`
+
+	// ParseReport describes what parsing the CSV left out of the snapshot.
+	ParseReport *helpers.ParseReport `json:"parseReport,omitempty"`
+
+	// Stats are the per-column statistics stored in the snapshot.
+	Stats engine.SnapshotStats `json:"stats"`
+}
+
+// SnapshotResponse is the output of the Snapshot function.
+type SnapshotResponse = Response[SnapshotResult]
+
+// ============================================================================
+// /profile
+// ============================================================================
+
+// ProfileRequest is the input for the Profile function.
+type ProfileRequest struct {
+	// CSV is the raw CSV content to profile. Required.
+	CSV string `json:"csv"`
+
+	// Schema classifies the columns. Discovered from the CSV when omitted.
+	Schema *schema.Config `json:"schema,omitempty"`
+}
+
+// ProfileResponse is the output of the Profile function: null rate and
+// distinct count per column, numeric statistics for measures, top values
+// for dimensions and date ranges for temporal columns.
+type ProfileResponse = Response[schema.Profile]
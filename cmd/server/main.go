@@ -3,11 +3,13 @@
 // Spektr HTTP Server — thin transport wrapper around the api package.
 //
 // Usage:
-//   go run cmd/server/main.go [--port 8080] [--cors]
+//   go run cmd/server/main.go [--port 8080] [--cors] [--cache-entries 1000] [--cache-mb 64] [--cache-ttl 10m]
+//                             [--timeout 60s] [--max-records 0] [--max-groups 0] [--max-rows 0] [--verbose]
 //
 // The api package owns the contract. This file only does:
 //   1. JSON decode the request body into an api.XxxRequest
-//   2. Call api.Xxx(request)
+//   2. Call api.XxxContext(r.Context(), request), so
+//      work stops when the client disconnects or the timeout passes
 //   3. JSON encode the api.Response[T] back to the client
 //
 // No business logic here. If you're adding logic, it belongs in the api package.
@@ -15,15 +17,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/spektr-org/spektr/api"
+	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/observe"
 )
 
 // ── Handlers ─────────────────────────────────────────────────────
@@ -39,7 +45,7 @@ func discoverHandler(w http.ResponseWriter, r *http.Request) {
 	if !decodeJSON(w, r, &req) {
 		return
 	}
-	writeJSON(w, api.Discover(req))
+	writeJSON(w, api.DiscoverContext(r.Context(), req))
 }
 
 // POST /refine
@@ -48,7 +54,7 @@ func refineHandler(w http.ResponseWriter, r *http.Request) {
 	if !decodeJSON(w, r, &req) {
 		return
 	}
-	writeJSON(w, api.Refine(req))
+	writeJSON(w, api.RefineContext(r.Context(), req))
 }
 
 // POST /parse
@@ -57,7 +63,7 @@ func parseHandler(w http.ResponseWriter, r *http.Request) {
 	if !decodeJSON(w, r, &req) {
 		return
 	}
-	writeJSON(w, api.Parse(req))
+	writeJSON(w, api.ParseContext(r.Context(), req))
 }
 
 // POST /translate
@@ -66,7 +72,7 @@ func translateHandler(w http.ResponseWriter, r *http.Request) {
 	if !decodeJSON(w, r, &req) {
 		return
 	}
-	writeJSON(w, api.Translate(req))
+	writeJSON(w, api.TranslateContext(r.Context(), req))
 }
 
 // POST /execute
@@ -75,7 +81,7 @@ func executeHandler(w http.ResponseWriter, r *http.Request) {
 	if !decodeJSON(w, r, &req) {
 		return
 	}
-	writeJSON(w, api.Execute(req))
+	writeJSON(w, api.ExecuteContext(r.Context(), req))
 }
 
 // POST /pipeline
@@ -84,7 +90,25 @@ func pipelineHandler(w http.ResponseWriter, r *http.Request) {
 	if !decodeJSON(w, r, &req) {
 		return
 	}
-	writeJSON(w, api.Pipeline(req))
+	writeJSON(w, api.PipelineContext(r.Context(), req))
+}
+
+// POST /snapshot
+func snapshotHandler(w http.ResponseWriter, r *http.Request) {
+	var req api.SnapshotRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	writeJSON(w, api.SnapshotContext(r.Context(), req))
+}
+
+// POST /profile
+func profileHandler(w http.ResponseWriter, r *http.Request) {
+	var req api.ProfileRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	writeJSON(w, api.ProfileContext(r.Context(), req))
 }
 
 // ── JSON Helpers ─────────────────────────────────────────────────
@@ -140,6 +164,16 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// ── Timeout Middleware ────────────────────────────────────────────
+
+func timeoutMiddleware(next http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // ── Method Guard ─────────────────────────────────────────────────
 
 func post(handler http.HandlerFunc) http.HandlerFunc {
@@ -166,13 +200,41 @@ func post(handler http.HandlerFunc) http.HandlerFunc {
 func main() {
 	port := flag.Int("port", 8080, "Server port")
 	enableCORS := flag.Bool("cors", true, "Enable CORS (default: on for Sheets/browser access)")
+	cacheEntries := flag.Int("cache-entries", 1000, "Max cached query results (0 disables the result cache)")
+	cacheMB := flag.Int("cache-mb", 64, "Max memory for cached query results, in MB")
+	cacheTTL := flag.Duration("cache-ttl", 10*time.Minute, "How long a cached query result stays valid")
+	timeout := flag.Duration("timeout", 60*time.Second, "Per-request deadline for AI calls and queries (0 disables)")
+	maxRecords := flag.Int("max-records", 0, "Max records a query may scan (0 = unlimited)")
+	maxGroups := flag.Int("max-groups", 0, "Max groups a query may produce (0 = unlimited)")
+	maxRows := flag.Int("max-rows", 0, "Max table rows or chart points in a result (0 = unlimited)")
+	verbose := flag.Bool("verbose", false, "Also log spans and metrics (stage timings, AI latency, token usage)")
 	flag.Parse()
 
+	// Engine, translator and Smart Refine events go through the observer
+	if *verbose {
+		observe.SetDefault(observe.NewSlog(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	} else {
+		observe.SetDefault(observe.NewSlog(slog.Default()))
+	}
+
 	// Override port from env if set
 	if envPort := os.Getenv("PORT"); envPort != "" {
 		fmt.Sscanf(envPort, "%d", port)
 	}
 
+	if *cacheEntries > 0 {
+		api.EnableResultCache(engine.CacheOptions{
+			MaxEntries: *cacheEntries,
+			MaxBytes:   int64(*cacheMB) << 20,
+			TTL:        *cacheTTL,
+		})
+	}
+
+	limits := engine.Limits{MaxRecords: *maxRecords, MaxGroups: *maxGroups, MaxRows: *maxRows}
+	if limits != (engine.Limits{}) {
+		api.EnforceLimits(limits)
+	}
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/health", healthHandler)
@@ -182,17 +244,31 @@ func main() {
 	mux.HandleFunc("/translate", post(translateHandler))
 	mux.HandleFunc("/execute", post(executeHandler))
 	mux.HandleFunc("/pipeline", post(pipelineHandler))
+	mux.HandleFunc("/snapshot", post(snapshotHandler))
+	mux.HandleFunc("/profile", post(profileHandler))
 
 	var handler http.Handler = mux
 	if *enableCORS {
 		handler = corsMiddleware(handler)
 	}
+	if *timeout > 0 {
+		handler = timeoutMiddleware(handler, *timeout)
+	}
 	handler = loggingMiddleware(handler)
 
 	addr := fmt.Sprintf(":%d", *port)
 	log.Printf("Spektr server v%s listening on %s", api.Version, addr)
 	log.Printf("CORS: %v", *enableCORS)
-	log.Printf("Endpoints: /health /discover /refine /parse /translate /execute /pipeline")
+	if *cacheEntries > 0 {
+		log.Printf("Result cache: %d entries, %d MB, TTL %s (stats on /health)", *cacheEntries, *cacheMB, *cacheTTL)
+	}
+	if *timeout > 0 {
+		log.Printf("Request timeout: %s", *timeout)
+	}
+	if limits != (engine.Limits{}) {
+		log.Printf("Query limits: %d records, %d groups, %d rows (0 = unlimited)", limits.MaxRecords, limits.MaxGroups, limits.MaxRows)
+	}
+	log.Printf("Endpoints: /health /discover /refine /parse /translate /execute /pipeline /snapshot /profile")
 
 	if err := http.ListenAndServe(addr, handler); err != nil {
 		log.Fatalf("Server failed: %v", err)
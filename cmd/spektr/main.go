@@ -5,12 +5,18 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spektr-org/spektr/engine"
 	"github.com/spektr-org/spektr/helpers"
+	"github.com/spektr-org/spektr/observe"
 	"github.com/spektr-org/spektr/schema"
 	"github.com/spektr-org/spektr/translator"
 	"github.com/spektr-org/spektr/translator/adapters"
@@ -24,15 +30,22 @@ const version = "0.2.0"
 
 func main() {
 	// ── Flags ─────────────────────────────────────────────────────────────
-	filePath := flag.String("file", "", "Path to CSV data file (required)")
+	filePath := flag.String("file", "", "Path to CSV data file or snapshot (required)")
 	queryStr := flag.String("query", "", "Natural language query to execute")
 	schemaPath := flag.String("schema", "", "Path to pre-built schema JSON (skips auto-detect)")
 	discover := flag.Bool("discover", false, "Print auto-detected schema and exit")
+	profile := flag.Bool("profile", false, "Print per-column statistics and exit (with --discover, attach them to the schema)")
 	refine := flag.Bool("refine", false, "Apply Smart Refine (AI enrichment) to auto-detected schema")
 	model := flag.String("model", "", "AI model name (e.g. gemini-2.5-flash-lite, gpt-4o)")
 	endpoint := flag.String("endpoint", "", "AI provider endpoint URL (default: Gemini)")
 	format := flag.String("format", "json", "Output format: json, pretty, text, csv")
 	outFile := flag.String("out", "", "Write output to file instead of stdout")
+	stream := flag.Bool("stream", false, "Stream the file instead of loading it (for files too large for memory)")
+	saveSnapshot := flag.String("save-snapshot", "", "Write the parsed dataset and schema as a snapshot for fast reload")
+	explain := flag.Bool("explain", false, "Attach an execution trace (JSON formats) or print it to stderr (text, csv)")
+	strict := flag.Bool("strict", false, "Fail on malformed or ragged rows, duplicate headers and unparseable numbers instead of skipping them")
+	locale := flag.String("locale", "", "Format values, replies and period labels for a locale (en-US, en-IN, de-DE, fr-FR, ...)")
+	verbose := flag.Bool("verbose", false, "Also log spans and metrics (stage timings, AI latency, token usage) to stderr")
 	showVersion := flag.Bool("version", false, "Print version and exit")
 
 	flag.Usage = func() {
@@ -42,7 +55,13 @@ Usage:
   spektr --file data.csv --query "revenue by region" --format csv
   spektr --file data.csv --query "bugs by priority" --format csv --out results.csv
   spektr --file data.csv --discover --format pretty
+  spektr --file data.csv --profile --format text
   spektr --file data.csv --schema schema.json --query "total story points"
+  spektr --file huge.csv --stream --query "revenue by region" --format csv
+  spektr --file data.csv --save-snapshot data.spektr
+  spektr --file data.spektr --query "revenue by region"
+  spektr --file data.csv --query "revenue by region" --explain --format text
+  spektr --file data.csv --query "revenue by region" --locale de-DE --format text
 
 Flags:
 `)
@@ -66,11 +85,22 @@ Examples:
 
   # Save schema for reuse
   spektr --file data.csv --discover --refine --out schema.json --format pretty
+
+  # Snapshot once, then query without re-parsing or re-detecting
+  spektr --file data.csv --schema schema.json --save-snapshot data.spektr
+  spektr --file data.spektr --query "total story points" --format text
 `)
 	}
 
 	flag.Parse()
 
+	// Engine, translator and Smart Refine events go through the observer
+	if *verbose {
+		observe.SetDefault(observe.NewSlog(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	} else {
+		observe.SetDefault(observe.NewSlog(slog.Default()))
+	}
+
 	if *showVersion {
 		fmt.Printf("spektr %s\n", version)
 		os.Exit(0)
@@ -82,8 +112,8 @@ Examples:
 		os.Exit(1)
 	}
 
-	if !*discover && *queryStr == "" {
-		fmt.Fprintln(os.Stderr, "Error: either --discover or --query is required")
+	if !*discover && !*profile && *queryStr == "" && *saveSnapshot == "" {
+		fmt.Fprintln(os.Stderr, "Error: one of --discover, --profile, --query or --save-snapshot is required")
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -99,10 +129,22 @@ Examples:
 		writer = f
 	}
 
-	// ── Read data ─────────────────────────────────────────────────────────
-	data, err := os.ReadFile(*filePath)
-	if err != nil {
-		fatalf("Failed to read file: %v", err)
+	// ── Read data (streamed passes re-open the file instead) ──────────────
+	var data []byte
+	var snap *helpers.Snapshot
+	var err error
+	if helpers.IsSnapshotFile(*filePath) {
+		snap, err = helpers.OpenSnapshot(*filePath)
+		if err != nil {
+			fatalf("Failed to read snapshot: %v", err)
+		}
+		defer snap.Close()
+		*stream = false
+	} else if !*stream {
+		data, err = os.ReadFile(*filePath)
+		if err != nil {
+			fatalf("Failed to read file: %v", err)
+		}
 	}
 
 	// ── Schema ────────────────────────────────────────────────────────────
@@ -119,13 +161,24 @@ Examples:
 		}
 		log.Printf("📋 Loaded schema: %s (%d dimensions, %d measures)",
 			sch.Name, len(sch.Dimensions), len(sch.Measures))
+	} else if snap != nil {
+		sch = &snap.Schema
+		log.Printf("📦 Snapshot schema: %s (%d dims, %d measures)",
+			sch.Name, len(sch.Dimensions), len(sch.Measures))
 	} else {
-		sch, err = schema.DiscoverFromCSV(data)
+		if *stream {
+			sch, err = discoverFile(*filePath)
+		} else {
+			sch, err = schema.DiscoverFromCSV(data)
+		}
 		if err != nil {
 			fatalf("Auto-Detect failed: %v", err)
 		}
 		log.Printf("🔍 Auto-Detect: %s (%d dims, %d measures, %d skipped)",
 			sch.Name, len(sch.Dimensions), len(sch.Measures), len(sch.SkippedColumns))
+		if n := sch.MalformedRows + sch.RaggedRows; n > 0 {
+			log.Printf("⚠️ Auto-Detect skipped %d unreadable row(s): %d malformed, %d ragged", n, sch.MalformedRows, sch.RaggedRows)
+		}
 
 		if *refine {
 			apiKey := os.Getenv("AI_API_KEY")
@@ -142,6 +195,28 @@ Examples:
 		}
 	}
 
+	// ── Profile mode ──────────────────────────────────────────────────────
+	if *profile {
+		if snap != nil {
+			fatalf("--profile needs a CSV file, not a snapshot")
+		}
+		var p *schema.Profile
+		if *stream {
+			p, err = profileFile(*filePath, *sch)
+		} else {
+			p, err = schema.ProfileCSV(data, *sch)
+		}
+		if err != nil {
+			fatalf("Profile failed: %v", err)
+		}
+		log.Printf("📈 Profiled %d rows, %d columns", p.Rows, len(p.Columns))
+		if !*discover {
+			writeProfile(writer, p, *format)
+			return
+		}
+		sch.Profile = p
+	}
+
 	// ── Discover mode ─────────────────────────────────────────────────────
 	if *discover {
 		writeJSON(writer, sch, *format)
@@ -151,22 +226,69 @@ Examples:
 		return
 	}
 
-	// ── Query mode ────────────────────────────────────────────────────────
+	// ── Query prerequisites (checked before the data is loaded) ──────────
 	apiKey := os.Getenv("AI_API_KEY")
-	if apiKey == "" {
-		fatalf("AI_API_KEY required for --query")
+	if *queryStr != "" {
+		if apiKey == "" {
+			fatalf("AI_API_KEY required for --query")
+		}
+		if *model == "" {
+			fatalf("--model is required (e.g. gemini-2.5-flash-lite, gpt-4o)")
+		}
+	}
+
+	// ── Records ───────────────────────────────────────────────────────────
+	var parseOpts []helpers.ParseOption
+	if *strict {
+		parseOpts = append(parseOpts, helpers.Strict())
 	}
-	if *model == "" {
-		fatalf("--model is required (e.g. gemini-2.5-flash-lite, gpt-4o)")
+	var view engine.RecordView
+	var summary *translator.DataSummary
+	var report *helpers.ParseReport
+	switch {
+	case snap != nil:
+		view = snap.View
+		log.Printf("📊 Loaded %d records from snapshot", view.Len())
+	case *stream && *saveSnapshot == "":
+		summary, report, err = summarizeFile(*filePath, *sch, parseOpts...)
+		logParseReport(report)
+		if err != nil {
+			fatalf("Failed to read CSV records: %v", err)
+		}
+		log.Printf("📊 Streamed %d records", summary.RecordCount)
+	case *stream:
+		view, report, err = parseFile(*filePath, *sch, parseOpts...)
+		logParseReport(report)
+		if err != nil {
+			fatalf("Failed to parse CSV records: %v", err)
+		}
+		log.Printf("📊 Parsed %d records", view.Len())
+	default:
+		var records []engine.Record
+		records, report, err = helpers.ParseCSVWithReport(data, *sch, parseOpts...)
+		logParseReport(report)
+		if err != nil {
+			fatalf("Failed to parse CSV records: %v", err)
+		}
+		log.Printf("📊 Parsed %d records", len(records))
+		view = engine.NewSliceView(records)
 	}
 
-	records, err := helpers.ParseCSV(data, *sch)
-	if err != nil {
-		fatalf("Failed to parse CSV records: %v", err)
+	// ── Snapshot mode ─────────────────────────────────────────────────────
+	if *saveSnapshot != "" {
+		if err := writeSnapshotFile(*saveSnapshot, view, *sch); err != nil {
+			fatalf("Failed to write snapshot: %v", err)
+		}
+		log.Printf("📦 Snapshot written to %s", *saveSnapshot)
+		if *queryStr == "" {
+			return
+		}
 	}
-	log.Printf("📊 Parsed %d records", len(records))
 
-	summary := translator.BuildDataSummaryFromRecords(records, *sch)
+	// ── Query mode ────────────────────────────────────────────────────────
+	if summary == nil {
+		summary = translator.BuildDataSummaryFromView(view, *sch)
+	}
 
 	t := translator.NewTranslator(adapters.New(apiKey, *model, *endpoint))
 	result, err := t.TranslateWithSummary(*queryStr, *sch, summary)
@@ -176,13 +298,41 @@ Examples:
 	log.Printf("🔄 Translated: intent=%s, visualize=%s, confidence=%.2f",
 		result.QuerySpec.Intent, result.QuerySpec.Visualize, result.QuerySpec.Confidence)
 
-	view := engine.NewSliceView(records)
-	execResult, err := engine.Execute(result.QuerySpec, view,
-		engine.WithDefaultMeasure(sch.GetDefaultMeasure()),
-	)
+	var localeOpts []engine.Option
+	if *locale != "" {
+		l, found := engine.LookupLocale(*locale)
+		if !found {
+			fatalf("Unknown locale %q", *locale)
+		}
+		localeOpts = append(localeOpts, engine.WithLocale(l))
+	}
+
+	var execResult *engine.Result
+	if view == nil {
+		if *explain {
+			log.Printf("⚠️ --explain is not supported with --stream; no trace will be shown")
+		}
+		var execReport *helpers.ParseReport
+		execResult, execReport, err = executeFile(*filePath, *sch, result.QuerySpec, localeOpts, parseOpts...)
+		if err == nil && !sameRowsRead(report, execReport) {
+			log.Printf("⚠️ The file changed between reads: the query ran over %d records, not %d", execReport.Rows, report.Rows)
+			logParseReport(execReport)
+		}
+	} else {
+		execOpts := append([]engine.Option{engine.WithDefaultMeasure(sch.GetDefaultMeasure())},
+			helpers.EngineOptions(*sch)...)
+		execOpts = append(execOpts, localeOpts...)
+		if *explain {
+			execOpts = append(execOpts, engine.WithExplain())
+		}
+		execResult, err = engine.Execute(result.QuerySpec, view, execOpts...)
+	}
 	if err != nil {
 		fatalf("Execution failed: %v", err)
 	}
+	if execResult != nil && execResult.Trace != nil && (*format == "text" || *format == "csv") {
+		printTrace(os.Stderr, execResult.Trace)
+	}
 
 	// ── Render output ─────────────────────────────────────────────────────
 	switch *format {
@@ -210,6 +360,7 @@ Examples:
 			Interpretation: result.Interpretation,
 			QuerySpec:      result.QuerySpec,
 			Result:         execResult,
+			ParseReport:    report,
 		}
 		writeJSON(writer, out, *format)
 	}
@@ -224,6 +375,118 @@ type cliOutput struct {
 	Interpretation engine.Interpretation `json:"interpretation"`
 	QuerySpec      engine.QuerySpec     `json:"querySpec"`
 	Result         *engine.Result       `json:"result"`
+	ParseReport    *helpers.ParseReport  `json:"parseReport,omitempty"`
+}
+
+// ============================================================================
+// SNAPSHOTS — parsed dataset + schema, reloaded without parsing
+// ============================================================================
+
+// parseFile reads the file straight into a columnar view, without holding
+// the raw CSV in memory alongside it.
+func parseFile(path string, sch schema.Config, opts ...helpers.ParseOption) (engine.RecordView, *helpers.ParseReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	view, report, err := helpers.ParseCSVColumnarReader(f, sch, opts...)
+	if err != nil {
+		return nil, report, err
+	}
+	return view, report, nil
+}
+
+// writeSnapshotFile writes to a temporary file and renames it over path, so
+// a process with the old snapshot memory-mapped keeps reading intact data.
+func writeSnapshotFile(path string, view engine.RecordView, sch schema.Config) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := helpers.SaveSnapshot(f, view, sch); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// ============================================================================
+// STREAMING — one pass over the file per step, bounded memory
+// ============================================================================
+
+// profileFile profiles the file in one streamed pass.
+func profileFile(path string, sch schema.Config) (*schema.Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return schema.ProfileReader(f, sch)
+}
+
+// summaryRecords caps the records kept for the translator's value summary.
+const summaryRecords = 10000
+
+func discoverFile(path string) (*schema.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return schema.DiscoverFromReader(f)
+}
+
+// summarizeFile builds the translator summary from the first records and
+// counts the rest.
+func summarizeFile(path string, sch schema.Config, opts ...helpers.ParseOption) (*translator.DataSummary, *helpers.ParseReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var sample []engine.Record
+	report, err := helpers.StreamCSV(f, sch, func(rec engine.Record) error {
+		if len(sample) < summaryRecords {
+			sample = append(sample, rec)
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, report, err
+	}
+	summary := translator.BuildDataSummaryFromRecords(sample, sch)
+	summary.RecordCount = report.Rows
+	return summary, report, nil
+}
+
+// executeFile answers spec in a second streamed pass over the file, read
+// with the same parse options as summarizeFile.
+func executeFile(path string, sch schema.Config, spec engine.QuerySpec, opts []engine.Option, parseOpts ...helpers.ParseOption) (*engine.Result, *helpers.ParseReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	opts = append([]engine.Option{engine.WithDefaultMeasure(sch.GetDefaultMeasure())}, opts...)
+	return helpers.ExecuteCSVStream(f, sch, spec, opts, parseOpts...)
+}
+
+// sameRowsRead reports whether two reads of a file kept and left out the
+// same rows, so the second read's report adds nothing.
+func sameRowsRead(a, b *helpers.ParseReport) bool {
+	return a != nil && b != nil && a.Rows == b.Rows && a.MalformedRows == b.MalformedRows &&
+		a.RaggedRows == b.RaggedRows && a.FooterRows == b.FooterRows
 }
 
 // ============================================================================
@@ -352,6 +615,149 @@ func writeJSON(w *os.File, v interface{}, format string) {
 	fmt.Fprintln(w, string(out))
 }
 
+// ============================================================================
+// PROFILE OUTPUT — --profile as JSON, a text table, or one CSV row per column
+// ============================================================================
+
+func writeProfile(w *os.File, p *schema.Profile, format string) {
+	switch format {
+	case "text":
+		writeProfileText(w, p)
+	case "csv":
+		writeProfileCSV(w, p)
+	default:
+		writeJSON(w, p, format)
+	}
+}
+
+func writeProfileText(w io.Writer, p *schema.Profile) {
+	fmt.Fprintf(w, "%d rows\n\n", p.Rows)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLUMN\tROLE\tNULL\tDISTINCT\tDETAILS")
+	for _, c := range p.Columns {
+		distinct := fmt.Sprint(c.Distinct)
+		if c.DistinctCapped {
+			distinct += "+"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.1f%%\t%s\t%s\n", c.Column, c.Role, c.NullRate*100, distinct, profileDetails(c))
+	}
+	tw.Flush()
+}
+
+// profileDetails summarizes a column's role-specific statistics on one line.
+func profileDetails(c schema.ColumnProfile) string {
+	var parts []string
+	if n := c.Numeric; n != nil {
+		parts = append(parts, fmt.Sprintf("min %s · p25 %s · median %s · p75 %s · p95 %s · max %s · mean %s ± %s",
+			fmtNum(n.Min), fmtNum(n.P25), fmtNum(n.Median), fmtNum(n.P75), fmtNum(n.P95),
+			fmtNum(n.Max), fmtNum(n.Mean), fmtNum(n.StdDev)))
+	}
+	if d := c.Dates; d != nil {
+		parts = append(parts, fmt.Sprintf("%s → %s", d.Min, d.Max))
+	}
+	if len(c.TopValues) > 0 {
+		top := c.TopValues
+		if len(top) > 3 {
+			top = top[:3]
+		}
+		values := make([]string, len(top))
+		for i, v := range top {
+			values[i] = fmt.Sprintf("%s (%d)", v.Value, v.Count)
+		}
+		parts = append(parts, "top: "+strings.Join(values, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func writeProfileCSV(w *os.File, p *schema.Profile) {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+	cw.Write([]string{"Column", "Role", "Null Rate", "Distinct", "Min", "P25", "Median", "P75", "P95", "Max",
+		"Mean", "Std Dev", "First Date", "Last Date", "Top Values"})
+	for _, c := range p.Columns {
+		row := []string{c.Column, c.Role, fmt.Sprint(c.NullRate), fmt.Sprint(c.Distinct)}
+		if n := c.Numeric; n != nil {
+			for _, v := range []float64{n.Min, n.P25, n.Median, n.P75, n.P95, n.Max, n.Mean, n.StdDev} {
+				row = append(row, fmtNum(v))
+			}
+		} else {
+			row = append(row, "", "", "", "", "", "", "", "")
+		}
+		if d := c.Dates; d != nil {
+			row = append(row, d.Min, d.Max)
+		} else {
+			row = append(row, "", "")
+		}
+		top := make([]string, len(c.TopValues))
+		for i, v := range c.TopValues {
+			top[i] = fmt.Sprintf("%s (%d)", v.Value, v.Count)
+		}
+		row = append(row, strings.Join(top, "; "))
+		cw.Write(row)
+	}
+}
+
+// ============================================================================
+// PARSE REPORT — what parsing left out, logged to stderr
+// ============================================================================
+
+func logParseReport(report *helpers.ParseReport) {
+	if report == nil {
+		return
+	}
+	if report.FooterRows > 0 {
+		log.Printf("ℹ️ Dropped %d summary row(s) at the end of the file", report.FooterRows)
+	}
+	if report.Clean() {
+		return
+	}
+	for _, h := range report.DuplicateHeaders {
+		log.Printf("⚠️ Duplicate header %q ignored (an earlier column has the same key)", h)
+	}
+	if n := report.MalformedRows + report.RaggedRows; n > 0 {
+		log.Printf("⚠️ Skipped %d row(s): %d malformed, %d ragged", n, report.MalformedRows, report.RaggedRows)
+		for _, issue := range report.SkippedRows {
+			log.Printf("   line %d: %s (%s)", issue.Line, issue.Reason, issue.Detail)
+		}
+	}
+	keys := make([]string, 0, len(report.Columns))
+	for k := range report.Columns {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if c := report.Columns[k]; c.Unparseable > 0 {
+			log.Printf("⚠️ %s: %d value(s) not numeric, left missing (e.g. %s)", k, c.Unparseable, strings.Join(c.Samples, ", "))
+		}
+	}
+}
+
+// ============================================================================
+// TRACE OUTPUT — --explain for the text and csv formats
+// ============================================================================
+
+func printTrace(w io.Writer, tr *engine.Trace) {
+	fmt.Fprintf(w, "── Explain ── path=%s measure=%s records=%d matched=%d groups=%d returned=%d total=%.2fms\n",
+		tr.Path, tr.Measure, tr.Records, tr.Matched, tr.Groups, tr.Returned, tr.TotalMs)
+	for _, f := range tr.Filters {
+		set := ""
+		if f.Set != "" {
+			set = f.Set + " "
+		}
+		fmt.Fprintf(w, "  filter %s%s in %v: %d → %d\n", set, f.Dimension, f.Values, f.Before, f.After)
+	}
+	if c := tr.Currency; c != nil {
+		fmt.Fprintf(w, "  currency %s → %s: normalized=%v rates=%v", c.Dimension, c.Base, c.Normalized, c.Rates)
+		if len(c.Unconverted) > 0 {
+			fmt.Fprintf(w, " unconverted=%v", c.Unconverted)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, st := range tr.Stages {
+		fmt.Fprintf(w, "  stage %-10s %.2fms\n", st.Stage, st.Ms)
+	}
+}
+
 // ============================================================================
 // HELPERS
 // ============================================================================
@@ -3,12 +3,16 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strings"
 	"syscall/js"
 
 	"github.com/spektr-org/spektr/engine"
 	"github.com/spektr-org/spektr/helpers"
+	"github.com/spektr-org/spektr/observe"
 	"github.com/spektr-org/spektr/schema"
 	"github.com/spektr-org/spektr/translator"
 	"github.com/spektr-org/spektr/translator/adapters"
@@ -27,7 +31,7 @@ import (
 //   __spektr.refine(schemaJSON, apiKey, model)
 //     → JSON enriched schema (makes HTTP call to Gemini)
 //
-//   __spektr.execute(querySpecJSON, recordsJSON, optionsJSON)
+//   __spektr.execute(querySpecJSON, recordsJSON | snapshotBytes, optionsJSON)
 //     → JSON result (chart/table/text)
 //
 //   __spektr.translate(query, schemaJSON, summaryJSON, apiKey, model, [endpoint])
@@ -36,6 +40,18 @@ import (
 //   __spektr.parseCSV(csvString, schemaJSON)
 //     → JSON records array
 //
+//   __spektr.snapshot(csvString, [schemaJSON])
+//     → Uint8Array dataset snapshot (schema included), usable in place of
+//       recordsJSON in execute
+//
+//   __spektr.profile(csvString, [schemaJSON])
+//     → JSON per-column statistics (null rate, distinct count, numeric
+//       summary, top values, date range)
+//
+//   __spektr.cacheStats()
+//     → JSON { hits, misses, evictions, expired, entries, bytes } for the
+//       execute result cache (repeated queries over identical data)
+//
 //   __spektr.version()
 //     → version string
 //
@@ -44,7 +60,16 @@ import (
 
 const wasmVersion = "0.2.0"
 
+// resultCache answers repeated execute calls over identical data.
+var resultCache = engine.NewResultCache(engine.CacheOptions{
+	MaxEntries: 200,
+	MaxBytes:   32 << 20,
+})
+
 func main() {
+	// Engine, translator and Smart Refine logs go to the browser console
+	observe.SetDefault(observe.NewSlog(slog.Default()))
+
 	// Create namespace object
 	ns := js.Global().Get("Object").New()
 
@@ -53,6 +78,9 @@ func main() {
 	ns.Set("execute", js.FuncOf(jsExecute))
 	ns.Set("translate", js.FuncOf(jsTranslate))
 	ns.Set("parseCSV", js.FuncOf(jsParseCSV))
+	ns.Set("snapshot", js.FuncOf(jsSnapshot))
+	ns.Set("profile", js.FuncOf(jsProfile))
+	ns.Set("cacheStats", js.FuncOf(jsCacheStats))
 	ns.Set("version", js.FuncOf(jsVersion))
 
 	js.Global().Set("__spektr", ns)
@@ -127,7 +155,7 @@ func jsRefine(this js.Value, args []js.Value) interface{} {
 
 func jsExecute(this js.Value, args []js.Value) interface{} {
 	if len(args) < 2 {
-		return errResult("execute requires 2-3 arguments: querySpecJSON, recordsJSON, [optionsJSON]")
+		return errResult("execute requires 2-3 arguments: querySpecJSON, recordsJSON | snapshotBytes, [optionsJSON]")
 	}
 
 	// Parse QuerySpec
@@ -136,32 +164,86 @@ func jsExecute(this js.Value, args []js.Value) interface{} {
 		return errResult(fmt.Sprintf("invalid querySpec JSON: %v", err))
 	}
 
-	// Parse Records
-	var records []engine.Record
-	if err := json.Unmarshal([]byte(args[1].String()), &records); err != nil {
-		return errResult(fmt.Sprintf("invalid records JSON: %v", err))
+	// Parse Records, or load them from a snapshot
+	var view engine.RecordView
+	if args[1].InstanceOf(js.Global().Get("Uint8Array")) {
+		data := make([]byte, args[1].Get("length").Int())
+		js.CopyBytesToGo(data, args[1])
+		snap, err := helpers.LoadSnapshot(data)
+		if err != nil {
+			return errResult(fmt.Sprintf("invalid snapshot: %v", err))
+		}
+		view = snap.View
+	} else {
+		var records []engine.Record
+		if err := json.Unmarshal([]byte(args[1].String()), &records); err != nil {
+			return errResult(fmt.Sprintf("invalid records JSON: %v", err))
+		}
+		view = engine.NewSliceView(records)
 	}
 
 	// Parse options
 	opts := []engine.Option{}
 	if len(args) > 2 && !args[2].IsUndefined() {
 		var options struct {
-			DefaultMeasure    string             `json:"defaultMeasure"`
-			BaseCurrency      string             `json:"baseCurrency"`
-			CurrencyDimension string             `json:"currencyDimension"`
-			ExchangeRates     map[string]float64 `json:"exchangeRates"`
+			DefaultMeasure    string              `json:"defaultMeasure"`
+			BaseCurrency      string              `json:"baseCurrency"`
+			CurrencyDimension string              `json:"currencyDimension"`
+			ExchangeRates     map[string]float64  `json:"exchangeRates"`
+			CurrencyMeasures  []string            `json:"currencyMeasures"`
+			MeasureUnits      map[string]string   `json:"measureUnits"`
+			MeasureFormats    map[string]string   `json:"measureFormats"`
+			Locale            string              `json:"locale"`
+			RateHistory       string              `json:"rateHistory"`
+			RateDimension     string              `json:"rateDimension"`
+			RateMode          engine.RateMode     `json:"rateMode"`
+			Calendar          *engine.Calendar    `json:"calendar"`
+			MultiValue        map[string]string   `json:"multiValue"`
+			ValueOrders       map[string][]string `json:"valueOrders"`
 		}
 		if err := json.Unmarshal([]byte(args[2].String()), &options); err == nil {
 			if options.DefaultMeasure != "" {
 				opts = append(opts, engine.WithDefaultMeasure(options.DefaultMeasure))
 			}
-			if options.BaseCurrency != "" && len(options.ExchangeRates) > 0 { 
+			if options.BaseCurrency != "" && (len(options.ExchangeRates) > 0 || options.RateHistory != "") {
 				dim := options.CurrencyDimension
 				if dim == "" {
 					dim = "currency"
 				}
 				opts = append(opts, engine.WithCurrency(options.BaseCurrency, dim, options.ExchangeRates))
 			}
+			if len(options.CurrencyMeasures) > 0 {
+				opts = append(opts, engine.WithCurrencyMeasures(options.CurrencyMeasures...))
+			}
+			for measure, unit := range options.MeasureUnits {
+				opts = append(opts, engine.WithMeasureUnit(measure, unit))
+			}
+			for measure, format := range options.MeasureFormats {
+				opts = append(opts, engine.WithMeasureFormat(measure, format))
+			}
+			if options.Locale != "" {
+				locale, found := engine.LookupLocale(options.Locale)
+				if !found {
+					return errResult(fmt.Sprintf("unknown locale %q", options.Locale))
+				}
+				opts = append(opts, engine.WithLocale(locale))
+			}
+			if options.RateHistory != "" {
+				table, err := engine.LoadRatesCSV(strings.NewReader(options.RateHistory))
+				if err != nil {
+					return errResult(fmt.Sprintf("invalid rateHistory: %v", err))
+				}
+				opts = append(opts, engine.WithDatedRates(table, options.RateDimension, options.RateMode))
+			}
+			if options.Calendar != nil {
+				opts = append(opts, engine.WithCalendar(*options.Calendar))
+			}
+			for dim, delim := range options.MultiValue {
+				opts = append(opts, engine.WithMultiValue(dim, delim))
+			}
+			for dim, order := range options.ValueOrders {
+				opts = append(opts, engine.WithValueOrder(dim, order))
+			}
 			
 		}
 	}
@@ -170,8 +252,7 @@ func jsExecute(this js.Value, args []js.Value) interface{} {
 	
 
 	// Execute
-	view := engine.NewSliceView(records)
-	result, err := engine.Execute(spec, view, opts...)
+	result, err := resultCache.Execute(spec, view, opts...)
 	if err != nil {
 		return errResult(fmt.Sprintf("execute failed: %v", err))
 	}
@@ -249,6 +330,90 @@ func jsParseCSV(this js.Value, args []js.Value) interface{} {
 	return okResult(records)
 }
 
+// ============================================================================
+// SNAPSHOT — CSV + Schema → Binary dataset
+// ============================================================================
+
+func jsSnapshot(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return errResult("snapshot requires 1-2 arguments: csvString, [schemaJSON]")
+	}
+	data := []byte(args[0].String())
+
+	var sch schema.Config
+	if len(args) > 1 && !args[1].IsUndefined() && !args[1].IsNull() {
+		if err := json.Unmarshal([]byte(args[1].String()), &sch); err != nil {
+			return errResult(fmt.Sprintf("invalid schema JSON: %v", err))
+		}
+	} else {
+		discovered, err := schema.DiscoverFromCSV(data)
+		if err != nil {
+			return errResult(fmt.Sprintf("discover failed: %v", err))
+		}
+		sch = *discovered
+	}
+
+	view, report, err := helpers.ParseCSVColumnar(data, sch)
+	if err != nil {
+		return errResult(fmt.Sprintf("parseCSV failed: %v", err))
+	}
+	var buf bytes.Buffer
+	if err := helpers.SaveSnapshot(&buf, view, sch); err != nil {
+		return errResult(fmt.Sprintf("snapshot failed: %v", err))
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return errResult(fmt.Sprintf("marshal failed: %v", err))
+	}
+
+	bytesJS := js.Global().Get("Uint8Array").New(buf.Len())
+	js.CopyBytesToJS(bytesJS, buf.Bytes())
+	result := js.Global().Get("Object").New()
+	result.Set("ok", true)
+	result.Set("data", bytesJS)
+	result.Set("parseReport", js.Global().Get("JSON").Call("parse", string(reportJSON)))
+	return result
+}
+
+// ============================================================================
+// PROFILE — CSV + Schema → Per-column statistics
+// ============================================================================
+
+func jsProfile(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return errResult("profile requires 1-2 arguments: csvString, [schemaJSON]")
+	}
+	data := []byte(args[0].String())
+
+	var sch schema.Config
+	if len(args) > 1 && !args[1].IsUndefined() && !args[1].IsNull() {
+		if err := json.Unmarshal([]byte(args[1].String()), &sch); err != nil {
+			return errResult(fmt.Sprintf("invalid schema JSON: %v", err))
+		}
+	} else {
+		discovered, err := schema.DiscoverFromCSV(data)
+		if err != nil {
+			return errResult(fmt.Sprintf("discover failed: %v", err))
+		}
+		sch = *discovered
+	}
+
+	profile, err := schema.ProfileCSV(data, sch)
+	if err != nil {
+		return errResult(fmt.Sprintf("profile failed: %v", err))
+	}
+	return okResult(profile)
+}
+
+// ============================================================================
+// CACHE STATS
+// ============================================================================
+
+func jsCacheStats(this js.Value, args []js.Value) interface{} {
+	return okResult(resultCache.Stats())
+}
+
 // ============================================================================
 // VERSION
 // ============================================================================
@@ -24,41 +24,61 @@ func GroupAndAggregate(
 	aggregation string,
 	sortBy string,
 	limit int,
+) []Group {
+	sorter := newGroupSorter(QuerySpec{GroupBy: groupBy, Aggregation: aggregation, SortBy: sortBy}, nil)
+	return groupAndAggregate(view, groupBy, measure, aggregation, sorter, limit, &config{})
+}
+
+// groupAndAggregate is GroupAndAggregate with a configured sorter
+// (multi-key sorts, ordinal value orders) and worker count (0 = sequential).
+func groupAndAggregate(
+	view RecordView,
+	groupBy []string,
+	measure string,
+	aggregation string,
+	sorter groupSorter,
+	limit int,
+	cfg *config,
 ) []Group {
 	if view.Len() == 0 {
 		return nil
 	}
 
 	// 1. Group
-	var groups []Group
-	if len(groupBy) == 0 {
-		groups = []Group{{
+	groups := groupView(view, groupBy, cfg.Parallelism)
+	cfg.observeGroups(groups)
+
+	// 2. Aggregate
+	aggregateGroups(groups, measure, aggregation, cfg.Parallelism)
+
+	// 3. Sort, 4. Limit
+	groups = sortAndLimit(groups, sorter, limit)
+	cfg.trace.returned(groups)
+	return groups
+}
+
+// groupView splits a view into groups; no groupBy yields a single "Total".
+func groupView(view RecordView, groupBy []string, workers int) []Group {
+	switch len(groupBy) {
+	case 0:
+		return []Group{{
 			Key:   "all",
 			Label: "Total",
 			View:  view,
 		}}
-	} else if len(groupBy) == 1 {
-		groups = groupBySingle(view, groupBy[0])
-	} else {
-		groups = groupByMulti(view, groupBy)
-	}
-
-	// 2. Aggregate
-	for i := range groups {
-		aggregateGroup(&groups[i], measure, aggregation)
-		for j := range groups[i].SubGroups {
-			aggregateGroup(&groups[i].SubGroups[j], measure, aggregation)
-		}
+	case 1:
+		return groupBySingle(view, groupBy[0], workers)
+	default:
+		return groupByMulti(view, groupBy, workers)
 	}
+}
 
-	// 3. Sort
-	SortGroups(groups, sortBy)
-
-	// 4. Limit
+// sortAndLimit orders aggregated groups and keeps the first limit (0 = all).
+func sortAndLimit(groups []Group, sorter groupSorter, limit int) []Group {
+	sorter.sort(groups)
 	if limit > 0 && len(groups) > limit {
 		groups = groups[:limit]
 	}
-
 	return groups
 }
 
@@ -66,56 +86,67 @@ func GroupAndAggregate(
 // GROUPING
 // ============================================================================
 
-func groupBySingle(view RecordView, dimension string) []Group {
-	grouped := make(map[string][]int)
-	order := make([]string, 0)
-
-	for i := 0; i < view.Len(); i++ {
-		key := getDimensionValue(view, i, dimension)
-		if _, exists := grouped[key]; !exists {
-			order = append(order, key)
-		}
-		grouped[key] = append(grouped[key], i)
+func groupBySingle(view RecordView, dimension string, workers int) []Group {
+	if cv, dict, ok := dimensionDictionary(view, dimension); ok && dimension != "year" {
+		return groupByCode(view, cv, dict, dimension, workers)
 	}
 
-	groups := make([]Group, 0, len(order))
-	for _, key := range order {
+	g := groupKeyRanges(view, dimension, workers)
+	groups := make([]Group, 0, len(g.order))
+	for _, key := range g.order {
 		groups = append(groups, Group{
 			Key:   key,
 			Label: key,
-			View:  newSubView(view, grouped[key]),
+			View:  newSubView(view, g.grouped[key]),
 		})
 	}
 	return groups
 }
 
-func groupByMulti(view RecordView, dimensions []string) []Group {
-	if len(dimensions) < 2 {
-		return groupBySingle(view, dimensions[0])
+// groupByCode groups a dictionary-encoded dimension by code, avoiding a
+// string hash per row. Group order matches groupBySingle (first seen).
+func groupByCode(view RecordView, cv CodedView, dict []string, dimension string, workers int) []Group {
+	g := groupCodeRanges(cv, dict, dimension, workers)
+	groups := make([]Group, 0, len(g.order))
+	for k, code := range g.order {
+		groups = append(groups, Group{
+			Key:   dict[code],
+			Label: dict[code],
+			View:  newSubView(view, g.rows[k]),
+		})
 	}
+	return groups
+}
 
-	primaryGroups := groupBySingle(view, dimensions[0])
-	for i := range primaryGroups {
-		primaryGroups[i].SubGroups = groupBySingle(primaryGroups[i].View, dimensions[1])
+func groupByMulti(view RecordView, dimensions []string, workers int) []Group {
+	if len(dimensions) < 2 {
+		return groupBySingle(view, dimensions[0], workers)
 	}
+
+	primaryGroups := groupBySingle(view, dimensions[0], workers)
+	parallelFor(len(primaryGroups), workers, func(i int) {
+		primaryGroups[i].SubGroups = groupBySingle(primaryGroups[i].View, dimensions[1], 0)
+	})
 	return primaryGroups
 }
 
 // getDimensionValue extracts a dimension value from a view at index.
-// Handles "year" as a virtual dimension derived from "month".
+// Handles "year" as a virtual dimension derived from "month" when the view
+// (or a CalendarView around it) does not provide one.
 func getDimensionValue(view RecordView, i int, dimension string) string {
-	if dimension == "year" {
-		month := view.Dimension(i, "month")
-		if parts := strings.Split(month, "-"); len(parts) == 2 {
-			return parts[1] // "Jan-2026" → "2026"
-		}
-		// Try parsing as full date
-		if t, err := time.Parse("Jan-2006", month); err == nil {
-			return fmt.Sprintf("%d", t.Year())
-		}
+	if val := view.Dimension(i, dimension); val != "" || dimension != "year" {
+		return val
 	}
 
-	return view.Dimension(i, dimension)
+	month := view.Dimension(i, "month")
+	if parts := strings.Split(month, "-"); len(parts) == 2 {
+		return parts[1] // "Jan-2026" → "2026"
+	}
+	// Try parsing as full date
+	if t, err := time.Parse("Jan-2006", month); err == nil {
+		return fmt.Sprintf("%d", t.Year())
+	}
+	return ""
 }
 
 // ============================================================================
@@ -127,24 +158,31 @@ func aggregateGroup(group *Group, measure string, aggregation string) {
 	if group.Count == 0 {
 		return
 	}
+	if aggregation == "none" {
+		return // pass through
+	}
+	group.Value = aggregateView(group.View, measure, aggregation)
+}
 
+// aggregateView computes one aggregate of a measure over a view.
+func aggregateView(view RecordView, measure string, aggregation string) float64 {
 	switch aggregation {
-	case "sum":
-		group.Value = SumMeasure(group.View, measure)
 	case "count":
-		group.Value = float64(group.Count)
+		return float64(view.Len())
 	case "avg":
-		group.Value = AvgMeasure(group.View, measure)
+		return AvgMeasure(view, measure)
 	case "max":
-		group.Value = MaxMeasure(group.View, measure)
+		return MaxMeasure(view, measure)
 	case "min":
-		group.Value = MinMeasure(group.View, measure)
+		return MinMeasure(view, measure)
+	case "median":
+		return MedianMeasure(view, measure)
+	case "distinct":
+		return DistinctMeasure(view, measure)
 	case "list":
-		group.Value = SumMeasure(group.View, measure) // for sorting
-	case "none":
-		// pass through
+		return SumMeasure(view, measure) // for sorting
 	default:
-		group.Value = SumMeasure(group.View, measure)
+		return SumMeasure(view, measure)
 	}
 }
 
@@ -157,24 +195,31 @@ func SumMeasure(view RecordView, measure string) float64 {
 	return total
 }
 
-// AvgMeasure computes average of a named measure.
+// AvgMeasure computes the average of a named measure, skipping missing values.
 func AvgMeasure(view RecordView, measure string) float64 {
-	n := view.Len()
+	var total float64
+	n := 0
+	for i := 0; i < view.Len(); i++ {
+		if !HasMeasure(view, i, measure) {
+			continue
+		}
+		total += view.Measure(i, measure)
+		n++
+	}
 	if n == 0 {
 		return 0
 	}
-	return SumMeasure(view, measure) / float64(n)
+	return total / float64(n)
 }
 
-// MaxMeasure returns the largest value of a named measure.
+// MaxMeasure returns the largest value of a named measure, skipping missing values.
 func MaxMeasure(view RecordView, measure string) float64 {
-	n := view.Len()
-	if n == 0 {
-		return 0
-	}
 	m := math.Inf(-1)
 	found := false
-	for i := 0; i < n; i++ {
+	for i := 0; i < view.Len(); i++ {
+		if !HasMeasure(view, i, measure) {
+			continue
+		}
 		v := view.Measure(i, measure)
 		if !found || v > m {
 			m = v
@@ -187,15 +232,14 @@ func MaxMeasure(view RecordView, measure string) float64 {
 	return m
 }
 
-// MinMeasure returns the smallest value of a named measure.
+// MinMeasure returns the smallest value of a named measure, skipping missing values.
 func MinMeasure(view RecordView, measure string) float64 {
-	n := view.Len()
-	if n == 0 {
-		return 0
-	}
 	m := math.Inf(1)
 	found := false
-	for i := 0; i < n; i++ {
+	for i := 0; i < view.Len(); i++ {
+		if !HasMeasure(view, i, measure) {
+			continue
+		}
 		v := view.Measure(i, measure)
 		if !found || v < m {
 			m = v
@@ -208,28 +252,33 @@ func MinMeasure(view RecordView, measure string) float64 {
 	return m
 }
 
+// MedianMeasure returns the median of a named measure, skipping missing values.
+func MedianMeasure(view RecordView, measure string) float64 {
+	values := make([]float64, 0, view.Len())
+	for i := 0; i < view.Len(); i++ {
+		if HasMeasure(view, i, measure) {
+			values = append(values, view.Measure(i, measure))
+		}
+	}
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
 // ============================================================================
 // SORTING
 // ============================================================================
 
 // SortGroups sorts aggregate groups by the specified sort mode.
+// See sortKeysFor for the supported modes; unknown modes keep grouping order.
 func SortGroups(groups []Group, sortBy string) {
-	switch sortBy {
-	case "value_desc", "amount_desc":
-		sort.Slice(groups, func(i, j int) bool { return groups[i].Value > groups[j].Value })
-	case "value_asc", "amount_asc":
-		sort.Slice(groups, func(i, j int) bool { return groups[i].Value < groups[j].Value })
-	case "chronological", "date_asc":
-		sort.Slice(groups, func(i, j int) bool { return parseSortableDate(groups[i].Key) < parseSortableDate(groups[j].Key) })
-	case "reverse_chronological", "date_desc":
-		sort.Slice(groups, func(i, j int) bool { return parseSortableDate(groups[i].Key) > parseSortableDate(groups[j].Key) })
-	case "label_asc", "alpha_asc":
-		sort.Slice(groups, func(i, j int) bool { return strings.ToLower(groups[i].Key) < strings.ToLower(groups[j].Key) })
-	case "label_desc":
-		sort.Slice(groups, func(i, j int) bool { return strings.ToLower(groups[i].Key) > strings.ToLower(groups[j].Key) })
-	default:
-		// preserve grouping order
-	}
+	newGroupSorter(QuerySpec{SortBy: sortBy}, nil).sort(groups)
 }
 
 // ============================================================================
@@ -246,14 +295,7 @@ func ParseMonthOrder(monthStr string) int {
 }
 
 func parseSortableDate(key string) int {
-	if v := ParseMonthOrder(key); v > 0 {
-		return v
-	}
-	t, err := time.Parse("2006", key)
-	if err == nil {
-		return t.Year() * 100
-	}
-	return 0
+	return periodOrder(key)
 }
 
 // FormatCurrency formats an amount with currency prefix and comma separators.
@@ -301,14 +343,16 @@ func RoundTo2(v float64) float64 {
 }
 
 // UniqueValues returns distinct values for a dimension across a view.
+// Multi-value dimensions contribute each of their values.
 func UniqueValues(view RecordView, dimension string) []string {
 	seen := make(map[string]bool)
 	var result []string
 	for i := 0; i < view.Len(); i++ {
-		val := getDimensionValue(view, i, dimension)
-		if val != "" && !seen[val] {
-			seen[val] = true
-			result = append(result, val)
+		for _, val := range groupKeys(view, i, dimension) {
+			if val != "" && !seen[val] {
+				seen[val] = true
+				result = append(result, val)
+			}
 		}
 	}
 	return result
@@ -335,6 +379,10 @@ func LabelForAggregation(aggregation string) string {
 		return "Maximum"
 	case "min":
 		return "Minimum"
+	case "median":
+		return "Median"
+	case "distinct":
+		return "Distinct"
 	default:
 		return "Value"
 	}
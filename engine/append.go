@@ -0,0 +1,310 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ============================================================================
+// APPENDABLE VIEW — continuously growing data with maintained aggregates
+// ============================================================================
+// Ops data arrives in batches. Instead of re-parsing everything, append each
+// batch to an AppendableView:
+//
+//   view := engine.NewAppendableView(dimensionKeys, measureKeys)
+//   view.RegisterIndex("status", "team")
+//   view.RegisterCube(engine.CubeSpec{...})
+//   view.Append(records...)           // version 1
+//   engine.Execute(spec, view)        // result.DataVersion == 1
+//   view.Append(more...)              // version 2
+//
+// Every non-empty append publishes a new immutable version: the columns grow
+// in place (existing rows are never rewritten), while registered indexes and
+// cubes are extended with just the new rows. Execute queries the version
+// current when it starts (see LiveView), so concurrent appends never tear a
+// query, and Result.DataVersion reports which version answered it.
+// ============================================================================
+
+// LiveView is implemented by views that change while being queried. Pin
+// returns an immutable point-in-time view, which Execute queries instead.
+type LiveView interface {
+	VersionedView
+	Pin() RecordView
+}
+
+// pinView returns the point-in-time view of a LiveView, or view itself.
+func pinView(view RecordView) RecordView {
+	if lv, ok := view.(LiveView); ok {
+		return lv.Pin()
+	}
+	return view
+}
+
+// appendableViews numbers AppendableViews for their fingerprints.
+var appendableViews atomic.Uint64
+
+// AppendableView is an append-only, dictionary-encoded RecordView. Appends
+// are serialized; queries may run concurrently with them.
+type AppendableView struct {
+	id      uint64     // process-unique, for fingerprints
+	mu      sync.Mutex // held by writers
+	builder *ColumnarBuilder
+	indexed []string
+	cells   []*cellBuilder // one per registered cube, in cube order
+	current atomic.Pointer[appendVersion]
+}
+
+// appendVersion is one published state of an AppendableView.
+type appendVersion struct {
+	*ColumnarView
+	id      uint64
+	version uint64
+	indexes map[string]*DimensionIndex
+	cubes   []*Cube
+}
+
+// NewAppendableView creates an empty view with fixed columns. Appended
+// records may only use these keys; missing ones become blanks / nulls.
+func NewAppendableView(dimensionKeys, measureKeys []string) *AppendableView {
+	v := &AppendableView{
+		id:      appendableViews.Add(1),
+		builder: NewColumnarBuilder(dimensionKeys, measureKeys),
+	}
+	v.current.Store(&appendVersion{ColumnarView: v.builder.view.frozen(), id: v.id})
+	return v
+}
+
+// Version returns the current data version: 0 when empty, then incremented
+// by every non-empty append.
+func (v *AppendableView) Version() uint64 { return v.current.Load().version }
+
+// Pin returns the current version as an immutable view.
+func (v *AppendableView) Pin() RecordView { return v.current.Load() }
+
+// Append adds records as one new version. Records using a key the view
+// does not have are rejected, and nothing is appended.
+func (v *AppendableView) Append(records ...Record) error {
+	return v.AppendView(NewSliceView(records))
+}
+
+// AppendView adds every row of src as one new version. src must not use
+// dimension or measure keys the view does not have.
+func (v *AppendableView) AppendView(src RecordView) error {
+	if src.Len() == 0 {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	data := v.builder.view
+	for _, k := range src.DimensionKeys() {
+		if _, ok := data.dimIndex[k]; !ok {
+			return fmt.Errorf("unknown dimension %q", k)
+		}
+	}
+	for _, k := range src.MeasureKeys() {
+		if _, ok := data.mesIndex[k]; !ok {
+			return fmt.Errorf("unknown measure %q", k)
+		}
+	}
+
+	from := data.n
+	dims := make([]string, len(data.dimKeys))
+	meas := make([]float64, len(data.mesKeys))
+	present := make([]bool, len(data.mesKeys))
+	for i := 0; i < src.Len(); i++ {
+		for c, k := range data.dimKeys {
+			dims[c] = src.Dimension(i, k)
+		}
+		for c, k := range data.mesKeys {
+			meas[c], present[c] = src.Measure(i, k), HasMeasure(src, i, k)
+		}
+		v.builder.Append(dims, meas, present)
+	}
+	v.publish(from)
+	return nil
+}
+
+// RegisterIndex builds filter indexes for dimensions and keeps them up to
+// date on every append.
+func (v *AppendableView) RegisterIndex(dimensions ...string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for _, dim := range dimensions {
+		if _, ok := v.builder.view.dimIndex[dim]; !ok {
+			return fmt.Errorf("unknown dimension %q", dim)
+		}
+	}
+	registered := toSet(v.indexed)
+	for _, dim := range dimensions {
+		if !registered[dim] {
+			registered[dim] = true
+			v.indexed = append(v.indexed, dim)
+		}
+	}
+	v.publish(v.builder.view.n)
+	return nil
+}
+
+// RegisterCube materializes a rollup cube (see BuildCube) that Execute uses
+// automatically for compatible queries, and extends it on every append.
+// Options other than WithCalendar are ignored.
+func (v *AppendableView) RegisterCube(spec CubeSpec, opts ...Option) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Same rows and version; a new pointer because the cube's source must be
+	// the version it is published with
+	next := *v.current.Load()
+	cube, cells, err := buildCube(&next, spec, opts...)
+	if err != nil {
+		return err
+	}
+	v.cells = append(v.cells, cells)
+	next.cubes = append(append([]*Cube(nil), next.cubes...), cube)
+	v.current.Store(&next)
+	return nil
+}
+
+// publish stores a new version covering every appended row; rows from
+// `from` on are new and are folded into the indexes and cubes. Caller
+// holds mu.
+func (v *AppendableView) publish(from int) {
+	cur := v.current.Load()
+	next := &appendVersion{
+		ColumnarView: v.builder.view.frozen(),
+		id:           v.id,
+		version:      cur.version,
+		indexes:      make(map[string]*DimensionIndex, len(v.indexed)),
+	}
+	if next.n > cur.n {
+		next.version++
+	}
+	for _, dim := range v.indexed {
+		if idx, ok := cur.indexes[dim]; ok {
+			next.indexes[dim] = idx.extend(next, dim)
+		} else {
+			next.indexes[dim] = buildDimensionIndex(next, dim)
+		}
+	}
+	for i, c := range cur.cubes {
+		next.cubes = append(next.cubes, c.extend(next, from, v.cells[i]))
+	}
+	v.current.Store(next)
+}
+
+func (v *AppendableView) Len() int { return v.current.Load().Len() }
+func (v *AppendableView) Dimension(i int, key string) string {
+	return v.current.Load().Dimension(i, key)
+}
+func (v *AppendableView) Measure(i int, key string) float64 { return v.current.Load().Measure(i, key) }
+func (v *AppendableView) HasMeasure(i int, key string) bool {
+	return v.current.Load().HasMeasure(i, key)
+}
+func (v *AppendableView) DimensionKeys() []string { return v.current.Load().DimensionKeys() }
+func (v *AppendableView) MeasureKeys() []string   { return v.current.Load().MeasureKeys() }
+
+// ============================================================================
+// VERSION ACCESSORS
+// ============================================================================
+
+func (v *appendVersion) Version() uint64 { return v.version }
+
+func (v *appendVersion) FilterIndex(key string) (*DimensionIndex, bool) {
+	idx, ok := v.indexes[key]
+	return idx, ok
+}
+
+// Fingerprint identifies the version without hashing its rows (see
+// ResultCache).
+func (v *appendVersion) Fingerprint() string {
+	return fmt.Sprintf("appendable:%d:%d", v.id, v.version)
+}
+
+// viewCubes lets Execute find the cubes maintained for this version.
+func (v *appendVersion) viewCubes() []*Cube { return v.cubes }
+
+// frozen returns an immutable copy of the view's current rows. Column
+// slices are shared — appends only write past their end — except validity
+// bitmaps, whose last word later appends still modify.
+func (v *ColumnarView) frozen() *ColumnarView {
+	f := &ColumnarView{
+		n:        v.n,
+		dims:     make([]dictColumn, len(v.dims)),
+		meas:     make([]measureColumn, len(v.meas)),
+		dimIndex: v.dimIndex,
+		mesIndex: v.mesIndex,
+		dimKeys:  v.dimKeys,
+		mesKeys:  v.mesKeys,
+	}
+	for c, col := range v.dims {
+		f.dims[c] = dictColumn{
+			dict:  col.dict[:len(col.dict):len(col.dict)],
+			codes: col.codes[:v.n:v.n],
+		}
+	}
+	for c, col := range v.meas {
+		f.meas[c] = measureColumn{
+			values: col.values[:v.n:v.n],
+			valid:  append([]uint64(nil), col.valid...),
+		}
+	}
+	return f
+}
+
+// extend returns an index that also covers rows d.rows through view.Len()-1.
+// Row sets that gain rows are copied first, so d stays valid for queries
+// still using it.
+func (d *DimensionIndex) extend(view RecordView, key string) *DimensionIndex {
+	n := view.Len()
+	next := &DimensionIndex{rows: n, values: make(map[string]*rowSet, len(d.values))}
+	for val, s := range d.values {
+		next.values[val] = s
+	}
+	copied := make(map[string]bool)
+	for i := d.rows; i < n; i++ {
+		for _, val := range groupKeys(view, i, key) {
+			val = strings.ToLower(val)
+			s := next.values[val]
+			if !copied[val] {
+				copied[val] = true
+				switch {
+				case s == nil:
+					s = &rowSet{}
+				case s.bitmap != nil:
+					b := NewBitmap(n)
+					copy(b, s.bitmap)
+					s = &rowSet{bitmap: b}
+				default:
+					s = &rowSet{list: s.list} // appends write past d's view of the list
+				}
+				next.values[val] = s
+			}
+			if s.bitmap != nil {
+				s.bitmap.Set(i)
+			} else if l := s.list; len(l) == 0 || l[len(l)-1] != uint32(i) {
+				s.list = append(l, uint32(i))
+			}
+		}
+	}
+	return next
+}
+
+// extend returns a copy of the cube with rows from through view.Len()-1 of
+// view folded into cells, which must be the builder the cube was built from.
+func (c *Cube) extend(view RecordView, from int, cells *cellBuilder) *Cube {
+	next := *c
+	next.source, next.rows, next.version = view, view.Len(), viewVersion(view)
+	src := NewCalendarView(view, c.calendar)
+	for i := from; i < src.Len(); i++ {
+		if !next.hasMonth && src.Dimension(i, cubeMonthDim) != "" {
+			next.hasMonth = true
+		}
+		cells.add(src, i)
+	}
+	next.cells = cells.build()
+	return &next
+}
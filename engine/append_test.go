@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// ============================================================================
+// APPENDABLE VIEW TESTS
+// ============================================================================
+
+func newTestAppendableView(t *testing.T) *AppendableView {
+	t.Helper()
+	view := NewAppendableView([]string{"team", "status", "month", "currency", "ticket"}, []string{"hours"})
+	if err := view.RegisterIndex("team", "status"); err != nil {
+		t.Fatalf("RegisterIndex failed: %v", err)
+	}
+	err := view.RegisterCube(CubeSpec{
+		Dimensions:   []string{"team", "status", "month"},
+		Measures:     []string{"hours"},
+		Aggregations: []string{"sum", "count", "avg", "min", "max"},
+	})
+	if err != nil {
+		t.Fatalf("RegisterCube failed: %v", err)
+	}
+	return view
+}
+
+// rebuiltView encodes records with the test view's column order, so list
+// tables have the same columns.
+func rebuiltView(records []Record) RecordView {
+	b := NewColumnarBuilder([]string{"team", "status", "month", "currency", "ticket"}, []string{"hours"})
+	for _, r := range records {
+		hours, ok := r.Measures["hours"]
+		b.Append([]string{r.Dimensions["team"], r.Dimensions["status"], r.Dimensions["month"],
+			r.Dimensions["currency"], r.Dimensions["ticket"]}, []float64{hours}, []bool{ok})
+	}
+	return b.Build()
+}
+
+func TestAppendableViewMatchesRebuiltData(t *testing.T) {
+	records := cubeRecords()
+	view := newTestAppendableView(t)
+
+	specs := []QuerySpec{
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, Visualize: "bar"},
+		{Intent: "chart", Aggregation: "avg", Measure: "hours", GroupBy: []string{"month"}, SortBy: "chronological", Visualize: "line",
+			Filters: Filters{Dimensions: map[string][]string{"status": {"open", "BLOCKED"}}}},
+		{Intent: "table", Aggregation: "list", Measure: "hours", Visualize: "table", Limit: 5,
+			Filters: Filters{Dimensions: map[string][]string{"team": {"(blank)", "web"}, "ticket": {"T-3", "T-7", "T-399"}}}},
+		{Intent: "text", Aggregation: "max", Measure: "hours"},
+	}
+
+	n := 0
+	for batch, size := range []int{1, 63, 100, 236} {
+		if err := view.Append(records[n : n+size]...); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		n += size
+		if view.Len() != n || view.Version() != uint64(batch+1) {
+			t.Fatalf("after batch %d: len %d version %d", batch, view.Len(), view.Version())
+		}
+
+		pinned := view.Pin()
+		if cfg := applyOptions(nil); cfg.cubeFor(specs[0], pinned, "hours") == nil {
+			t.Errorf("batch %d: maintained cube does not answer", batch)
+		}
+		for _, spec := range specs {
+			want, _ := Execute(spec, rebuiltView(records[:n]))
+			got, err := Execute(spec, view)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if got.DataVersion != uint64(batch+1) {
+				t.Errorf("DataVersion = %d, want %d", got.DataVersion, batch+1)
+			}
+			got.DataVersion = 0
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("batch %d %s %s: result differs\n got: %+v\nwant: %+v", batch, spec.Intent, spec.Aggregation, got, want)
+			}
+		}
+	}
+}
+
+func TestAppendableViewPinIsStable(t *testing.T) {
+	records := cubeRecords()
+	view := newTestAppendableView(t)
+	if err := view.Append(records[:100]...); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	spec := QuerySpec{Intent: "chart", Aggregation: "count", Measure: "hours", GroupBy: []string{"team"}, Visualize: "bar",
+		Filters: Filters{Dimensions: map[string][]string{"status": {"closed"}}}}
+	pinned := view.Pin()
+	before, _ := Execute(spec, pinned)
+
+	if err := view.Append(records[100:]...); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	after, _ := Execute(spec, pinned)
+	if pinned.Len() != 100 || !reflect.DeepEqual(before, after) {
+		t.Error("pinned version changed after a later append")
+	}
+	if before.DataVersion != 1 {
+		t.Errorf("DataVersion = %d, want 1", before.DataVersion)
+	}
+}
+
+func TestAppendableViewRejectsUnknownKeys(t *testing.T) {
+	view := newTestAppendableView(t)
+	bad := []Record{
+		{Dimensions: map[string]string{"team": "Core"}, Measures: map[string]float64{"hours": 1}},
+		{Dimensions: map[string]string{"owner": "ana"}, Measures: map[string]float64{"hours": 1}},
+	}
+	if err := view.Append(bad...); err == nil {
+		t.Error("expected an error for an unknown dimension")
+	}
+	if err := view.Append(Record{Measures: map[string]float64{"cost": 1}}); err == nil {
+		t.Error("expected an error for an unknown measure")
+	}
+	if err := view.RegisterIndex("owner"); err == nil {
+		t.Error("expected an error indexing an unknown dimension")
+	}
+	if view.Len() != 0 || view.Version() != 0 {
+		t.Errorf("rejected appends changed the view: len %d version %d", view.Len(), view.Version())
+	}
+	if err := view.Append(); err != nil || view.Version() != 0 {
+		t.Errorf("empty append: err %v, version %d", err, view.Version())
+	}
+}
+
+func TestAppendableViewConcurrentQueries(t *testing.T) {
+	records := cubeRecords()
+	view := newTestAppendableView(t)
+	spec := QuerySpec{Intent: "text", Aggregation: "count", Measure: "hours",
+		Filters: Filters{Dimensions: map[string][]string{"team": {"core"}}}}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < len(records); i += 40 {
+			view.Append(records[i : i+40]...)
+		}
+	}()
+	for q := 0; q < 50; q++ {
+		if _, err := Execute(spec, view); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+	}
+	wg.Wait()
+
+	got, _ := Execute(spec, view)
+	want, _ := Execute(spec, NewSliceView(records))
+	got.DataVersion = 0
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("final result differs\n got: %+v\nwant: %+v", got, want)
+	}
+}
@@ -0,0 +1,352 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// RESULT CACHE — memoized Execute for repeated queries
+// ============================================================================
+// Dashboards and the HTTP server re-run the same QuerySpecs over the same
+// data. A ResultCache answers repeats without recomputing:
+//
+//   cache := engine.NewResultCache(engine.CacheOptions{MaxEntries: 500, TTL: 10 * time.Minute})
+//   result, err := cache.Execute(spec, view, opts...)
+//
+// The key hashes the normalized QuerySpec, the options that affect results,
+// the resolved time range (relative ranges move with the clock) and a
+// dataset fingerprint. The fingerprint is a content hash of the view, or
+// the view's own Fingerprint when it implements FingerprintedView
+// (AppendableView does, from its version, in O(1)).
+//
+// Cached results are shared between callers: treat them as read-only.
+// Top-level Result fields may be set freely; each call gets its own copy.
+// ============================================================================
+
+// CacheOptions bounds a ResultCache. Zero values mean unlimited.
+type CacheOptions struct {
+	MaxEntries int              // evict least recently used beyond this many results
+	MaxBytes   int64            // approximate limit on cached result size (JSON bytes)
+	TTL        time.Duration    // results older than this are recomputed
+	Clock      func() time.Time // for TTL; defaults to time.Now
+}
+
+// CacheStats reports cache effectiveness.
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"` // removed to respect MaxEntries / MaxBytes
+	Expired   uint64 `json:"expired"`   // removed because TTL elapsed
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// FingerprintedView is implemented by views that can identify their
+// contents without a full scan. Equal fingerprints must mean equal rows.
+type FingerprintedView interface {
+	RecordView
+	Fingerprint() string
+}
+
+// ResultCache is an LRU cache of Execute results. Safe for concurrent use.
+type ResultCache struct {
+	opts    CacheOptions
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front = most recently used
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	key     string
+	result  Result
+	size    int64
+	expires time.Time
+}
+
+// NewResultCache creates an empty cache.
+func NewResultCache(opts CacheOptions) *ResultCache {
+	return &ResultCache{
+		opts:    opts,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Execute returns the cached result for spec over view, or runs Execute and
+// caches it. Errors are never cached.
+func (c *ResultCache) Execute(spec QuerySpec, view RecordView, opts ...Option) (*Result, error) {
+	return c.ExecuteContext(context.Background(), spec, view, opts...)
+}
+
+// ExecuteContext is Execute with cancellation; see ExecuteContext. A cached
+// result is returned even if ctx is already done. Explain runs (WithExplain)
+// bypass the cache: their trace describes a real execution.
+func (c *ResultCache) ExecuteContext(ctx context.Context, spec QuerySpec, view RecordView, opts ...Option) (*Result, error) {
+	view = pinView(view) // key and result must see the same version
+	cfg := applyOptions(opts)
+	if cfg.Explain {
+		return ExecuteContext(ctx, spec, view, opts...)
+	}
+	key, ok := resultKey(spec, view, cfg)
+	if !ok {
+		return ExecuteContext(ctx, spec, view, opts...)
+	}
+	if result, hit := c.get(key); hit {
+		return result, nil
+	}
+
+	result, err := ExecuteContext(ctx, spec, view, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, result)
+	return result, nil
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *ResultCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Purge drops every cached result. Counters are kept.
+func (c *ResultCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.stats.Entries, c.stats.Bytes = 0, 0
+}
+
+func (c *ResultCache) now() time.Time {
+	if c.opts.Clock != nil {
+		return c.opts.Clock()
+	}
+	return time.Now()
+}
+
+func (c *ResultCache) get(key string) (*Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if c.opts.TTL > 0 && !c.now().Before(e.expires) {
+		c.remove(el)
+		c.stats.Expired++
+		c.stats.Misses++
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	c.stats.Hits++
+	result := e.result
+	return &result, true
+}
+
+func (c *ResultCache) put(key string, result *Result) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return // not serializable (NaN values); recompute next time
+	}
+	size := int64(len(encoded) + len(key))
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el) // a concurrent miss got here first
+	}
+	e := &cacheEntry{key: key, result: *result, size: size}
+	if c.opts.TTL > 0 {
+		e.expires = c.now().Add(c.opts.TTL)
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	c.stats.Entries++
+	c.stats.Bytes += size
+
+	for c.lru.Len() > 1 && ((c.opts.MaxEntries > 0 && c.stats.Entries > c.opts.MaxEntries) ||
+		(c.opts.MaxBytes > 0 && c.stats.Bytes > c.opts.MaxBytes)) {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove unlinks an entry. Caller holds mu.
+func (c *ResultCache) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*cacheEntry)
+	delete(c.entries, e.key)
+	c.stats.Entries--
+	c.stats.Bytes -= e.size
+}
+
+// ============================================================================
+// CACHE KEY
+// ============================================================================
+
+// resultKey hashes everything Execute's result depends on. Returns false
+// when the spec cannot be keyed (an invalid time range — Execute reports it).
+func resultKey(spec QuerySpec, view RecordView, cfg *config) (string, bool) {
+	var resolved *DateRange
+	if spec.TimeRange != nil && !spec.TimeRange.IsZero() {
+		r, err := cfg.Calendar.ResolveTimeRange(*spec.TimeRange, cfg.now())
+		if err != nil {
+			return "", false
+		}
+		resolved = r
+	}
+
+	key := struct {
+		Spec              QuerySpec           `json:"spec"`
+		Range             *DateRange          `json:"range"`
+		BaseCurrency      string              `json:"baseCurrency"`
+		CurrencyDimension string              `json:"currencyDimension"`
+		ExchangeRates     map[string]float64  `json:"exchangeRates"`
+		DatedRates        string              `json:"datedRates"`
+		RateDimension     string              `json:"rateDimension"`
+		RateMode          RateMode            `json:"rateMode"`
+		CurrencyMeasures  []string            `json:"currencyMeasures"`
+		MeasureUnits      map[string]string   `json:"measureUnits"`
+		MeasureFormats    map[string]string   `json:"measureFormats"`
+		Locale            *Locale             `json:"locale"`
+		DefaultMeasure    string              `json:"defaultMeasure"`
+		Calendar          Calendar            `json:"calendar"`
+		BlankLabel        string              `json:"blankLabel"`
+		ExcludeBlanks     bool                `json:"excludeBlanks"`
+		MultiValue        map[string]string   `json:"multiValue"`
+		ValueOrders       map[string][]string `json:"valueOrders"`
+		Limits            Limits              `json:"limits"`
+		Data              string              `json:"data"`
+	}{
+		Spec:              normalizeSpec(spec),
+		Range:             resolved,
+		BaseCurrency:      cfg.BaseCurrency,
+		CurrencyDimension: cfg.CurrencyDimension,
+		ExchangeRates:     cfg.ExchangeRates,
+		DatedRates:        cfg.RateTable.Fingerprint(),
+		RateDimension:     cfg.RateDimension,
+		RateMode:          cfg.RateMode,
+		CurrencyMeasures:  cfg.CurrencyMeasures,
+		MeasureUnits:      cfg.MeasureUnits,
+		MeasureFormats:    cfg.MeasureFormats,
+		Locale:            cfg.Locale,
+		DefaultMeasure:    cfg.DefaultMeasure,
+		Calendar:          cfg.Calendar,
+		BlankLabel:        cfg.BlankLabel,
+		ExcludeBlanks:     cfg.ExcludeBlanks,
+		MultiValue:        cfg.MultiValue,
+		ValueOrders:       cfg.ValueOrders,
+		Limits:            cfg.Limits,
+		Data:              Fingerprint(view),
+	}
+	encoded, err := json.Marshal(key) // map keys are sorted: canonical
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// normalizeSpec clears fields that cannot change the result, so equivalent
+// specs share a key. Filter values keep their case and order — ratio
+// replies echo them.
+func normalizeSpec(spec QuerySpec) QuerySpec {
+	spec.Confidence = 0
+	spec.Filters = normalizeFilters(spec.Filters)
+	if spec.CompareFilters != nil {
+		cf := normalizeFilters(*spec.CompareFilters)
+		spec.CompareFilters = &cf
+	}
+	if len(spec.GroupBy) == 0 {
+		spec.GroupBy = nil
+	}
+	if len(spec.Measures) == 0 {
+		spec.Measures = nil
+	}
+	if len(spec.SortKeys) == 0 {
+		spec.SortKeys = nil
+	}
+	if spec.TimeRange != nil && spec.TimeRange.IsZero() {
+		spec.TimeRange = nil
+	}
+	return spec
+}
+
+// normalizeFilters drops dimensions with no selected values.
+func normalizeFilters(f Filters) Filters {
+	var dims map[string][]string
+	for dim, vals := range f.Dimensions {
+		if len(vals) == 0 {
+			continue
+		}
+		if dims == nil {
+			dims = make(map[string][]string)
+		}
+		dims[dim] = vals
+	}
+	return Filters{Dimensions: dims}
+}
+
+// Fingerprint identifies a view's contents: its own Fingerprint for a
+// FingerprintedView, otherwise a SHA-256 over every key and cell.
+func Fingerprint(view RecordView) string {
+	if fv, ok := view.(FingerprintedView); ok {
+		return fv.Fingerprint()
+	}
+
+	dimKeys := append([]string(nil), view.DimensionKeys()...)
+	mesKeys := append([]string(nil), view.MeasureKeys()...)
+	sort.Strings(dimKeys)
+	sort.Strings(mesKeys)
+
+	h := sha256.New()
+	var buf []byte
+	writeString := func(s string) {
+		buf = binary.AppendUvarint(buf, uint64(len(s)))
+		buf = append(buf, s...)
+	}
+	buf = binary.AppendUvarint(buf, uint64(view.Len()))
+	for _, k := range dimKeys {
+		writeString(k)
+	}
+	buf = append(buf, 0)
+	for _, k := range mesKeys {
+		writeString(k)
+	}
+	for i := 0; i < view.Len(); i++ {
+		for _, k := range dimKeys {
+			writeString(view.Dimension(i, k))
+		}
+		for _, k := range mesKeys {
+			if HasMeasure(view, i, k) {
+				buf = append(buf, 1)
+				buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(view.Measure(i, k)))
+			} else {
+				buf = append(buf, 0)
+			}
+		}
+		if len(buf) >= 32<<10 {
+			h.Write(buf)
+			buf = buf[:0]
+		}
+	}
+	h.Write(buf)
+	return hex.EncodeToString(h.Sum(nil))
+}
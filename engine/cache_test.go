@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// RESULT CACHE TESTS
+// ============================================================================
+
+func cacheSpec() QuerySpec {
+	return QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, Visualize: "bar"}
+}
+
+func TestResultCacheHitsMatchExecute(t *testing.T) {
+	view := NewSliceView(cubeRecords())
+	cache := NewResultCache(CacheOptions{})
+
+	want, _ := Execute(cacheSpec(), view)
+	for i := 0; i < 3; i++ {
+		got, err := cache.Execute(cacheSpec(), view)
+		if err != nil {
+			t.Fatalf("cache Execute failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("call %d: cached result differs", i)
+		}
+		got.Reply = "changed by caller"
+	}
+	if s := cache.Stats(); s.Hits != 2 || s.Misses != 1 || s.Entries != 1 || s.Bytes <= 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestResultCacheKey(t *testing.T) {
+	records := cubeRecords()
+	view := NewSliceView(records)
+	cache := NewResultCache(CacheOptions{})
+	cache.Execute(cacheSpec(), view)
+
+	// Equivalent: confidence, empty filter lists, same rows in another view
+	equivalent := cacheSpec()
+	equivalent.Confidence = 0.4
+	equivalent.Filters = Filters{Dimensions: map[string][]string{"status": {}}}
+	cache.Execute(equivalent, view)
+	cache.Execute(cacheSpec(), NewColumnarView(records))
+	if s := cache.Stats(); s.Hits != 2 {
+		t.Errorf("equivalent queries: hits = %d, want 2", s.Hits)
+	}
+
+	// Different: spec, options, data
+	other := cacheSpec()
+	other.Aggregation = "avg"
+	cache.Execute(other, view)
+	cache.Execute(cacheSpec(), view, WithExcludeBlanks())
+	cache.Execute(cacheSpec(), NewSliceView(records[:200]))
+	if s := cache.Stats(); s.Hits != 2 || s.Misses != 4 {
+		t.Errorf("different queries: stats = %+v, want 2 hits / 4 misses", s)
+	}
+}
+
+func TestResultCacheRelativeTimeRange(t *testing.T) {
+	view := NewSliceView(cubeRecords())
+	cache := NewResultCache(CacheOptions{})
+	spec := cacheSpec()
+	spec.TimeRange = &TimeRange{Relative: "this_month"}
+
+	for _, now := range []string{"2026-02-10", "2026-02-20", "2026-03-05"} {
+		clock := WithClock(func() time.Time { d, _ := time.Parse("2006-01-02", now); return d })
+		want, _ := Execute(spec, view, clock)
+		got, _ := cache.Execute(spec, view, clock)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: cached result differs", now)
+		}
+	}
+	if s := cache.Stats(); s.Hits != 1 || s.Misses != 2 {
+		t.Errorf("stats = %+v, want the second February call to hit", s)
+	}
+}
+
+func TestResultCacheEviction(t *testing.T) {
+	view := NewSliceView(cubeRecords())
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewResultCache(CacheOptions{MaxEntries: 2, TTL: time.Minute, Clock: func() time.Time { return now }})
+
+	specs := make([]QuerySpec, 3)
+	for i, agg := range []string{"sum", "avg", "max"} {
+		specs[i] = cacheSpec()
+		specs[i].Aggregation = agg
+	}
+	cache.Execute(specs[0], view)
+	cache.Execute(specs[1], view)
+	cache.Execute(specs[0], view) // specs[1] is now least recently used
+	cache.Execute(specs[2], view)
+	if s := cache.Stats(); s.Entries != 2 || s.Evictions != 1 {
+		t.Fatalf("after overflow: stats = %+v", s)
+	}
+	cache.Execute(specs[0], view)
+	if s := cache.Stats(); s.Hits != 2 {
+		t.Errorf("recently used entry was evicted: stats = %+v", s)
+	}
+
+	now = now.Add(2 * time.Minute)
+	cache.Execute(specs[0], view)
+	if s := cache.Stats(); s.Expired != 1 || s.Hits != 2 {
+		t.Errorf("after TTL: stats = %+v", s)
+	}
+
+	small := NewResultCache(CacheOptions{MaxBytes: 64})
+	small.Execute(specs[0], view)
+	if s := small.Stats(); s.Entries != 0 || s.Bytes != 0 {
+		t.Errorf("oversized result was cached: stats = %+v", s)
+	}
+}
+
+func TestResultCacheFollowsAppends(t *testing.T) {
+	records := cubeRecords()
+	view := newTestAppendableView(t)
+	cache := NewResultCache(CacheOptions{})
+
+	view.Append(records[:100]...)
+	first, _ := cache.Execute(cacheSpec(), view)
+	cache.Execute(cacheSpec(), view)
+	view.Append(records[100:]...)
+	second, _ := cache.Execute(cacheSpec(), view)
+
+	if s := cache.Stats(); s.Hits != 1 || s.Misses != 2 {
+		t.Errorf("stats = %+v, want the append to force a miss", s)
+	}
+	if first.DataVersion != 1 || second.DataVersion != 2 {
+		t.Errorf("DataVersion = %d, %d; want 1, 2", first.DataVersion, second.DataVersion)
+	}
+}
+
+func TestResultCacheConcurrent(t *testing.T) {
+	view := NewSliceView(cubeRecords())
+	cache := NewResultCache(CacheOptions{MaxEntries: 3})
+	want, _ := Execute(cacheSpec(), view)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				spec := cacheSpec()
+				spec.Limit = (g + i) % 5
+				got, err := cache.Execute(spec, view)
+				if err != nil {
+					t.Errorf("Execute failed: %v", err)
+					return
+				}
+				if spec.Limit == 0 && !reflect.DeepEqual(got, want) {
+					t.Errorf("concurrent result differs")
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if s := cache.Stats(); s.Entries > 3 || s.Hits+s.Misses != 160 {
+		t.Errorf("stats = %+v", s)
+	}
+}
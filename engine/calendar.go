@@ -0,0 +1,353 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// CALENDAR — Fiscal years, week start, and time zone for temporal logic
+// ============================================================================
+// A Calendar decides where years, quarters and weeks begin. The zero value is
+// the Gregorian calendar with ISO (Monday-start) weeks in the clock's zone.
+//
+// Consumers of the calendar:
+//   - CalendarView      — derives "year", "quarter", "month", "week" buckets on read
+//   - ResolveTimeRange  — "this_quarter", "last_year", "last_week" boundaries
+//   - Growth            — period-over-period comparison on fiscal buckets
+//   - Sorting           — "FY26 Q1" < "FY26 Q2" < "FY27 Q1"
+//
+// Fiscal years are named after the calendar year they END in:
+// with FiscalYearStart=4, Apr-2025 … Mar-2026 is "FY26".
+// ============================================================================
+
+// Calendar configures temporal bucketing and relative-range boundaries.
+type Calendar struct {
+	Dimension       string `json:"dimension,omitempty"`       // temporal dimension to bucket (default "month")
+	FiscalYearStart int    `json:"fiscalYearStart,omitempty"` // 1–12, month the fiscal year begins (default 1)
+	WeekStart       string `json:"weekStart,omitempty"`       // "monday" (ISO, default), "sunday", "saturday"
+	TimeZone        string `json:"timeZone,omitempty"`        // IANA zone for "now" (default: clock's zone)
+}
+
+// Validate checks the calendar fields.
+func (c Calendar) Validate() error {
+	if c.FiscalYearStart < 0 || c.FiscalYearStart > 12 {
+		return fmt.Errorf("fiscalYearStart must be 1–12, got %d", c.FiscalYearStart)
+	}
+	if _, ok := parseWeekday(c.WeekStart); !ok {
+		return fmt.Errorf("unknown weekStart %q", c.WeekStart)
+	}
+	if c.TimeZone != "" {
+		if _, err := time.LoadLocation(c.TimeZone); err != nil {
+			return fmt.Errorf("unknown timeZone %q: %w", c.TimeZone, err)
+		}
+	}
+	return nil
+}
+
+// TimeDimension returns the temporal dimension the calendar buckets.
+func (c Calendar) TimeDimension() string {
+	if c.Dimension != "" {
+		return c.Dimension
+	}
+	return defaultTimeDimension
+}
+
+// IsFiscal returns true if the year does not start in January.
+func (c Calendar) IsFiscal() bool {
+	return c.yearStartMonth() != time.January
+}
+
+func (c Calendar) yearStartMonth() time.Month {
+	if c.FiscalYearStart < 1 || c.FiscalYearStart > 12 {
+		return time.January
+	}
+	return time.Month(c.FiscalYearStart)
+}
+
+func (c Calendar) weekStartDay() time.Weekday {
+	d, _ := parseWeekday(c.WeekStart)
+	return d
+}
+
+// location returns the calendar's zone, or nil to keep the clock's zone.
+func (c Calendar) location() *time.Location {
+	if c.TimeZone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// Today returns the calendar date of now in the calendar's zone.
+func (c Calendar) Today(now time.Time) time.Time {
+	if loc := c.location(); loc != nil {
+		now = now.In(loc)
+	}
+	return startOfDay(now)
+}
+
+// ── Period boundaries ─────────────────────────────────────────────────────
+
+// Truncate returns the start of the unit ("day", "week", "month", "quarter",
+// "year") containing t, honouring fiscal years and the week start.
+func (c Calendar) Truncate(t time.Time, unit string) time.Time {
+	t = startOfDay(t)
+	switch unit {
+	case "week":
+		offset := (int(t.Weekday()) - int(c.weekStartDay()) + 7) % 7
+		return t.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "quarter":
+		start := c.Truncate(t, "year")
+		months := monthsBetween(start, t)
+		return start.AddDate(0, months-months%3, 0)
+	case "year":
+		start := time.Date(t.Year(), c.yearStartMonth(), 1, 0, 0, 0, 0, time.UTC)
+		if start.After(t) {
+			start = start.AddDate(-1, 0, 0)
+		}
+		return start
+	}
+	return t
+}
+
+// FiscalYear returns the fiscal year containing t, named by its end year.
+func (c Calendar) FiscalYear(t time.Time) int {
+	start := c.Truncate(t, "year")
+	if !c.IsFiscal() {
+		return start.Year()
+	}
+	return start.Year() + 1
+}
+
+// FiscalQuarter returns the 1-based quarter of the (fiscal) year containing t.
+func (c Calendar) FiscalQuarter(t time.Time) int {
+	return monthsBetween(c.Truncate(t, "year"), t)/3 + 1
+}
+
+// Week returns the week-year and 1-based week number of t, following the
+// ISO 8601 rule for any week start: a week belongs to the year holding most
+// of its days (its fourth day), and week 1 is the first such week. Monday-start
+// calendars get exactly ISO weeks; a week spanning New Year is never split.
+func (c Calendar) Week(t time.Time) (int, int) {
+	mid := c.Truncate(t, "week").AddDate(0, 0, 3)
+	return mid.Year(), (mid.YearDay()-1)/7 + 1
+}
+
+// ── Labels ────────────────────────────────────────────────────────────────
+
+// YearLabel returns "2026" for calendar years or "FY26" for fiscal years.
+func (c Calendar) YearLabel(t time.Time) string {
+	if !c.IsFiscal() {
+		return strconv.Itoa(c.FiscalYear(t))
+	}
+	return fmt.Sprintf("FY%02d", c.FiscalYear(t)%100)
+}
+
+// QuarterLabel returns "Q1-2026" for calendar quarters or "FY26 Q1" for fiscal quarters.
+func (c Calendar) QuarterLabel(t time.Time) string {
+	if !c.IsFiscal() {
+		return fmt.Sprintf("Q%d-%d", c.FiscalQuarter(t), t.Year())
+	}
+	return fmt.Sprintf("%s Q%d", c.YearLabel(t), c.FiscalQuarter(t))
+}
+
+// WeekLabel returns "2026-W42".
+func (c Calendar) WeekLabel(t time.Time) string {
+	y, w := c.Week(t)
+	return fmt.Sprintf("%d-W%02d", y, w)
+}
+
+// rangeLabel names a resolved range, preferring fiscal names when the range
+// is exactly one fiscal year or quarter.
+func (c Calendar) rangeLabel(start, end time.Time) string {
+	if c.IsFiscal() && !start.IsZero() {
+		switch {
+		case start.Equal(c.Truncate(start, "year")) && end.Equal(start.AddDate(1, 0, 0)):
+			return c.YearLabel(start)
+		case start.Equal(c.Truncate(start, "quarter")) && end.Equal(start.AddDate(0, 3, 0)):
+			return c.QuarterLabel(start)
+		}
+	}
+	return formatRangeLabel(start, end)
+}
+
+// ============================================================================
+// CALENDAR VIEW — derived temporal buckets on read (zero-copy)
+// ============================================================================
+
+// calendarBuckets are the virtual dimensions a CalendarView can derive.
+var calendarBuckets = []string{"year", "quarter", "month", "week"}
+
+// CalendarView wraps a RecordView and answers "year", "quarter", "month" and
+// "week" from the calendar's temporal dimension. Real dimensions of the same
+// name in the parent always win.
+type CalendarView struct {
+	parent   RecordView
+	source   string
+	calendar Calendar
+	virtual  map[string]bool
+	cache    sync.Map // bucket + "\x00" + source value → label
+}
+
+// NewCalendarView wraps view with calendar-derived bucket dimensions.
+func NewCalendarView(view RecordView, cal Calendar) RecordView {
+	existing := make(map[string]bool)
+	for _, k := range view.DimensionKeys() {
+		existing[k] = true
+	}
+	virtual := make(map[string]bool)
+	for _, b := range calendarBuckets {
+		if !existing[b] {
+			virtual[b] = true
+		}
+	}
+	if len(virtual) == 0 {
+		return view
+	}
+	return &CalendarView{
+		parent:   view,
+		source:   cal.TimeDimension(),
+		calendar: cal,
+		virtual:  virtual,
+	}
+}
+
+func (v *CalendarView) Len() int { return v.parent.Len() }
+
+func (v *CalendarView) Dimension(i int, key string) string {
+	if !v.virtual[key] {
+		return v.parent.Dimension(i, key)
+	}
+	raw := v.parent.Dimension(i, v.source)
+	if raw == "" {
+		return ""
+	}
+	cacheKey := key + "\x00" + raw
+	if label, ok := v.cache.Load(cacheKey); ok {
+		return label.(string)
+	}
+	label := v.bucket(key, raw)
+	v.cache.Store(cacheKey, label)
+	return label
+}
+
+func (v *CalendarView) bucket(key, raw string) string {
+	start, _, ok := ParseTemporalValue(raw)
+	if !ok {
+		return ""
+	}
+	switch key {
+	case "year":
+		return v.calendar.YearLabel(start)
+	case "quarter":
+		return v.calendar.QuarterLabel(start)
+	case "month":
+		return start.Format("Jan-2006")
+	case "week":
+		return v.calendar.WeekLabel(start)
+	}
+	return ""
+}
+
+func (v *CalendarView) Measure(i int, key string) float64 { return v.parent.Measure(i, key) }
+func (v *CalendarView) DimensionValues(i int, key string) ([]string, bool) {
+	return DimensionValues(v.parent, i, key)
+}
+
+// Dictionary forwards codes for real dimensions; derived buckets are strings.
+func (v *CalendarView) Dictionary(key string) ([]string, bool) {
+	if v.virtual[key] {
+		return nil, false
+	}
+	_, dict, ok := dimensionDictionary(v.parent, key)
+	return dict, ok
+}
+
+func (v *CalendarView) DimensionCode(i int, key string) uint32 {
+	return v.parent.(CodedView).DimensionCode(i, key)
+}
+
+func (v *CalendarView) FilterIndex(key string) (*DimensionIndex, bool) {
+	if v.virtual[key] {
+		return nil, false
+	}
+	return filterIndex(v.parent, key)
+}
+
+func (v *CalendarView) HasMeasure(i int, key string) bool { return HasMeasure(v.parent, i, key) }
+func (v *CalendarView) DimensionKeys() []string           { return v.parent.DimensionKeys() }
+func (v *CalendarView) MeasureKeys() []string             { return v.parent.MeasureKeys() }
+
+// ============================================================================
+// SORTABLE PERIOD KEYS
+// ============================================================================
+
+var (
+	fiscalLabelPattern = regexp.MustCompile(`^FY(\d{2,4})(?: Q([1-4]))?$`)
+	weekLabelPattern   = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+)
+
+// periodOrder maps a period label to a sortable integer. Labels of the same
+// scheme ("Jan-2026", "FY26 Q1", "2026-W42", "2026-03-15") order correctly
+// against each other. Returns 0 for non-temporal labels.
+func periodOrder(key string) int {
+	key = strings.TrimSpace(key)
+	if m := fiscalLabelPattern.FindStringSubmatch(key); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		if y < 100 {
+			y += 2000
+		}
+		q := 0
+		if m[2] != "" {
+			q, _ = strconv.Atoi(m[2])
+		}
+		return y*10000 + q*100
+	}
+	if m := weekLabelPattern.FindStringSubmatch(key); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		w, _ := strconv.Atoi(m[2])
+		return y*10000 + w*100
+	}
+	if start, _, ok := ParseTemporalValue(key); ok {
+		return start.Year()*10000 + int(start.Month())*100 + start.Day()
+	}
+	return 0
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "monday", "mon", "iso":
+		return time.Monday, true
+	case "sunday", "sun":
+		return time.Sunday, true
+	case "saturday", "sat":
+		return time.Saturday, true
+	case "tuesday", "tue":
+		return time.Tuesday, true
+	case "wednesday", "wed":
+		return time.Wednesday, true
+	case "thursday", "thu":
+		return time.Thursday, true
+	case "friday", "fri":
+		return time.Friday, true
+	}
+	return time.Monday, false
+}
+
+func monthsBetween(start, t time.Time) int {
+	return (t.Year()-start.Year())*12 + int(t.Month()) - int(start.Month())
+}
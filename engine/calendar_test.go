@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// CALENDAR TESTS
+// ============================================================================
+
+var fiscalApril = Calendar{FiscalYearStart: 4}
+
+func TestCalendarLabels(t *testing.T) {
+	d := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	if got := (Calendar{}).QuarterLabel(d); got != "Q1-2026" {
+		t.Errorf("Gregorian QuarterLabel = %q, want Q1-2026", got)
+	}
+	if got := fiscalApril.YearLabel(d); got != "FY26" {
+		t.Errorf("fiscal YearLabel = %q, want FY26", got)
+	}
+	if got := fiscalApril.QuarterLabel(d); got != "FY26 Q4" {
+		t.Errorf("fiscal QuarterLabel = %q, want FY26 Q4", got)
+	}
+	if got := fiscalApril.QuarterLabel(d.AddDate(0, 2, 0)); got != "FY27 Q1" {
+		t.Errorf("fiscal QuarterLabel(Apr) = %q, want FY27 Q1", got)
+	}
+}
+
+func TestCalendarWeekYearBoundary(t *testing.T) {
+	sunday := Calendar{WeekStart: "sunday"}
+	cases := []struct {
+		cal  Calendar
+		days []string
+		want string
+	}{
+		// Sunday 31 Dec 2023 – Saturday 6 Jan 2024 is one week
+		{sunday, []string{"2023-12-31", "2024-01-01", "2024-01-06"}, "2024-W01"},
+		{sunday, []string{"2023-12-30"}, "2023-W52"},
+		// Sunday 26 Dec 2021 – Saturday 1 Jan 2022: most days in 2021
+		{sunday, []string{"2021-12-26", "2021-12-31", "2022-01-01"}, "2021-W52"},
+		{sunday, []string{"2022-01-02"}, "2022-W01"},
+		// Monday-start stays ISO
+		{Calendar{}, []string{"2020-12-31", "2021-01-03"}, "2020-W53"},
+		{Calendar{}, []string{"2024-12-30", "2025-01-05"}, "2025-W01"},
+	}
+	for _, tc := range cases {
+		for _, day := range tc.days {
+			d, _ := time.Parse("2006-01-02", day)
+			if got := tc.cal.WeekLabel(d); got != tc.want {
+				t.Errorf("%s WeekLabel(%s) = %q, want %q", tc.cal.weekStartDay(), day, got, tc.want)
+			}
+		}
+	}
+	for d := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC); d.Year() < 2030; d = d.AddDate(0, 0, 1) {
+		y, w := (Calendar{}).Week(d)
+		if iy, iw := d.ISOWeek(); y != iy || w != iw {
+			t.Fatalf("Monday Week(%s) = %d-W%02d, want ISO %d-W%02d", d.Format("2006-01-02"), y, w, iy, iw)
+		}
+	}
+}
+
+func TestCalendarWeekStart(t *testing.T) {
+	sunday := Calendar{WeekStart: "sunday"}
+	// Sunday 18 Oct 2026: ISO puts it at the end of W42, Sunday-start at the beginning of a new week.
+	if got := (Calendar{}).Truncate(fixedNow, "week").Format("2006-01-02"); got != "2026-10-12" {
+		t.Errorf("ISO week start = %s, want 2026-10-12", got)
+	}
+	if got := sunday.Truncate(fixedNow, "week").Format("2006-01-02"); got != "2026-10-18" {
+		t.Errorf("Sunday week start = %s, want 2026-10-18", got)
+	}
+	if got := (Calendar{}).WeekLabel(fixedNow); got != "2026-W42" {
+		t.Errorf("ISO WeekLabel = %q, want 2026-W42", got)
+	}
+	if err := (Calendar{WeekStart: "someday"}).Validate(); err == nil {
+		t.Error("expected error for unknown weekStart")
+	}
+	if err := (Calendar{FiscalYearStart: 13}).Validate(); err == nil {
+		t.Error("expected error for fiscalYearStart 13")
+	}
+}
+
+func TestCalendarResolveFiscalRange(t *testing.T) {
+	tests := []struct {
+		tr    TimeRange
+		start string
+		end   string
+		label string
+	}{
+		{TimeRange{Relative: "this_quarter"}, "2026-10-01", "2027-01-01", "FY27 Q3"},
+		{TimeRange{Relative: "last_quarter"}, "2026-07-01", "2026-10-01", "FY27 Q2"},
+		{TimeRange{Relative: "last_year"}, "2025-04-01", "2026-04-01", "FY26"},
+		{TimeRange{Relative: "ytd"}, "2026-04-01", "2026-10-19", "Apr 1, 2026 – Oct 18, 2026"},
+	}
+	for _, tt := range tests {
+		r, err := fiscalApril.ResolveTimeRange(tt.tr, fixedNow)
+		if err != nil {
+			t.Errorf("ResolveTimeRange(%+v) error: %v", tt.tr, err)
+			continue
+		}
+		if r.Start.Format("2006-01-02") != tt.start || r.End.Format("2006-01-02") != tt.end || r.Label != tt.label {
+			t.Errorf("ResolveTimeRange(%+v) = [%s, %s) %q, want [%s, %s) %q", tt.tr,
+				r.Start.Format("2006-01-02"), r.End.Format("2006-01-02"), r.Label, tt.start, tt.end, tt.label)
+		}
+	}
+}
+
+func TestExecuteGroupByFiscalQuarter(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "chart",
+		Aggregation: "sum",
+		Measure:     "amount",
+		GroupBy:     []string{"quarter"},
+		SortBy:      "chronological",
+		Visualize:   "bar",
+	}
+
+	result, err := Execute(spec, monthlyView(), WithCalendar(fiscalApril))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.ChartConfig == nil || len(result.ChartConfig.Series) == 0 {
+		t.Fatalf("expected chart series, got %+v", result.ChartConfig)
+	}
+
+	points := result.ChartConfig.Series[0].Data
+	want := []struct {
+		label string
+		value float64
+	}{
+		{"FY27 Q1", 100}, // Jun
+		{"FY27 Q2", 900}, // Jul + Aug + Sep
+		{"FY27 Q3", 500}, // Oct
+	}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d: %+v", len(points), len(want), points)
+	}
+	for i, w := range want {
+		if points[i].Label != w.label || points[i].Value != w.value {
+			t.Errorf("point %d = %s:%v, want %s:%v", i, points[i].Label, points[i].Value, w.label, w.value)
+		}
+	}
+}
+
+func TestExecuteInvalidCalendar(t *testing.T) {
+	spec := QuerySpec{Intent: "text", Aggregation: "sum", Measure: "amount"}
+	if _, err := Execute(spec, monthlyView(), WithCalendar(Calendar{TimeZone: "Mars/Olympus"})); err == nil {
+		t.Error("expected error for unknown time zone")
+	}
+}
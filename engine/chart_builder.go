@@ -61,6 +61,10 @@ func BuildChart(spec QuerySpec, groups []Group) *ChartConfig {
 // then all subsequent series are reordered to match — so bar positions are
 // consistent across series regardless of sortBy.
 func BuildMultiMeasureChart(spec QuerySpec, view RecordView, measures []string) *ChartConfig {
+	return buildMultiMeasureChart(spec, view, measures, newGroupSorter(spec, nil), &config{})
+}
+
+func buildMultiMeasureChart(spec QuerySpec, view RecordView, measures []string, sorter groupSorter, cfg *config) *ChartConfig {
 	if view.Len() == 0 || len(measures) == 0 {
 		return nil
 	}
@@ -87,7 +91,7 @@ func BuildMultiMeasureChart(spec QuerySpec, view RecordView, measures []string)
 	var canonicalLabels []string // label order established by first measure
 
 	for i, measure := range measures {
-		groups := GroupAndAggregate(view, spec.GroupBy, measure, spec.Aggregation, spec.SortBy, spec.Limit)
+		groups := groupAndAggregate(view, spec.GroupBy, measure, spec.Aggregation, sorter, spec.Limit, cfg)
 
 		var points []ChartPoint
 		if i == 0 {
@@ -147,18 +151,18 @@ func buildSingleSeries(groups []Group, seriesName string) []ChartSeries {
 }
 
 func buildMultiSeries(groups []Group) []ChartSeries {
+	// Series in first-seen order — map order would shuffle them between runs
 	subKeySet := make(map[string]bool)
+	subKeys := make([]string, 0)
 	for _, g := range groups {
 		for _, sg := range g.SubGroups {
-			subKeySet[sg.Key] = true
+			if !subKeySet[sg.Key] {
+				subKeySet[sg.Key] = true
+				subKeys = append(subKeys, sg.Key)
+			}
 		}
 	}
 
-	subKeys := make([]string, 0, len(subKeySet))
-	for k := range subKeySet {
-		subKeys = append(subKeys, k)
-	}
-
 	seriesMap := make(map[string][]ChartPoint)
 	for _, key := range subKeys {
 		seriesMap[key] = make([]ChartPoint, 0, len(groups))
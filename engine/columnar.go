@@ -0,0 +1,258 @@
+package engine
+
+import "math/bits"
+
+// ============================================================================
+// COLUMNAR VIEW — dictionary-encoded columns for large datasets
+// ============================================================================
+// SliceView keeps two maps per row; at millions of rows the map overhead
+// dominates memory and every Dimension() call is a hash lookup.
+// ColumnarView stores one slice per column instead:
+//
+//   dimensions — []uint32 codes into a per-column dictionary (code 0 = "")
+//   measures   — []float64 values plus a validity bitmap (missing = null)
+//
+// Filters and grouping read codes directly when a view implements CodedView,
+// so string comparison happens once per distinct value, not once per row.
+//
+// Build with ColumnarBuilder (streaming, e.g. helpers.ParseCSVColumnar) or
+// convert existing records with NewColumnarView.
+// ============================================================================
+
+// CodedView is implemented by views with dictionary-encoded dimensions.
+// Dictionary returns the distinct values of a dimension (codes index into it)
+// and false when the dimension is not encoded in this view. DimensionCode
+// must only be called for dimensions Dictionary reports as encoded.
+type CodedView interface {
+	RecordView
+	Dictionary(key string) ([]string, bool)
+	DimensionCode(index int, key string) uint32
+}
+
+// dimensionDictionary returns the dictionary for key if view encodes it.
+func dimensionDictionary(view RecordView, key string) (CodedView, []string, bool) {
+	cv, ok := view.(CodedView)
+	if !ok {
+		return nil, nil, false
+	}
+	dict, ok := cv.Dictionary(key)
+	if !ok {
+		return nil, nil, false
+	}
+	return cv, dict, true
+}
+
+// ColumnarView is a column-oriented, dictionary-encoded RecordView.
+type ColumnarView struct {
+	n        int
+	dims     []dictColumn
+	meas     []measureColumn
+	dimIndex map[string]int
+	mesIndex map[string]int
+	dimKeys  []string
+	mesKeys  []string
+}
+
+type dictColumn struct {
+	dict   []string          // code → value; dict[0] is always ""
+	codes  []uint32          // row → code
+	lookup map[string]uint32 // value → code (build time only)
+}
+
+type measureColumn struct {
+	values []float64
+	valid  []uint64 // bitmap: bit i set when row i has a value
+}
+
+func (c *dictColumn) code(val string) uint32 {
+	if code, ok := c.lookup[val]; ok {
+		return code
+	}
+	code := uint32(len(c.dict))
+	c.dict = append(c.dict, val)
+	c.lookup[val] = code
+	return code
+}
+
+func (c *measureColumn) isValid(i int) bool {
+	return c.valid[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+func (v *ColumnarView) Len() int { return v.n }
+
+func (v *ColumnarView) Dimension(i int, key string) string {
+	c, ok := v.dimIndex[key]
+	if !ok || i < 0 || i >= v.n {
+		return ""
+	}
+	col := &v.dims[c]
+	return col.dict[col.codes[i]]
+}
+
+func (v *ColumnarView) Measure(i int, key string) float64 {
+	c, ok := v.mesIndex[key]
+	if !ok || i < 0 || i >= v.n {
+		return 0
+	}
+	return v.meas[c].values[i]
+}
+
+func (v *ColumnarView) HasMeasure(i int, key string) bool {
+	c, ok := v.mesIndex[key]
+	if !ok || i < 0 || i >= v.n {
+		return false
+	}
+	return v.meas[c].isValid(i)
+}
+
+func (v *ColumnarView) Dictionary(key string) ([]string, bool) {
+	c, ok := v.dimIndex[key]
+	if !ok {
+		return nil, false
+	}
+	return v.dims[c].dict, true
+}
+
+func (v *ColumnarView) DimensionCode(i int, key string) uint32 {
+	return v.dims[v.dimIndex[key]].codes[i]
+}
+
+func (v *ColumnarView) DimensionKeys() []string { return v.dimKeys }
+func (v *ColumnarView) MeasureKeys() []string   { return v.mesKeys }
+
+// Cardinality returns the number of distinct non-empty values of a dimension.
+func (v *ColumnarView) Cardinality(key string) int {
+	c, ok := v.dimIndex[key]
+	if !ok {
+		return 0
+	}
+	return len(v.dims[c].dict) - 1
+}
+
+// NullCount returns the number of rows missing a value for a measure.
+func (v *ColumnarView) NullCount(key string) int {
+	c, ok := v.mesIndex[key]
+	if !ok {
+		return v.n
+	}
+	set := 0
+	for _, w := range v.meas[c].valid {
+		set += bits.OnesCount64(w)
+	}
+	return v.n - set
+}
+
+// ============================================================================
+// COLUMNAR BUILDER — row-at-a-time construction
+// ============================================================================
+
+// ColumnarBuilder appends rows into a ColumnarView. Not safe for concurrent use.
+//
+//	b := engine.NewColumnarBuilder([]string{"team"}, []string{"hours"})
+//	b.Append([]string{"Core"}, []float64{7.5}, nil)
+//	view := b.Build()
+type ColumnarBuilder struct {
+	view *ColumnarView
+}
+
+// NewColumnarBuilder creates a builder for the given dimension and measure keys.
+// Append takes values in the same order.
+func NewColumnarBuilder(dimensionKeys, measureKeys []string) *ColumnarBuilder {
+	v := &ColumnarView{
+		dims:     make([]dictColumn, len(dimensionKeys)),
+		meas:     make([]measureColumn, len(measureKeys)),
+		dimIndex: make(map[string]int, len(dimensionKeys)),
+		mesIndex: make(map[string]int, len(measureKeys)),
+		dimKeys:  append([]string(nil), dimensionKeys...),
+		mesKeys:  append([]string(nil), measureKeys...),
+	}
+	for i, k := range dimensionKeys {
+		v.dimIndex[k] = i
+		v.dims[i] = dictColumn{dict: []string{""}, lookup: map[string]uint32{"": 0}}
+	}
+	for i, k := range measureKeys {
+		v.mesIndex[k] = i
+	}
+	return &ColumnarBuilder{view: v}
+}
+
+// Append adds one row. dims and measures follow the builder's key order;
+// missing trailing values are blank / null. present marks which measures
+// have a value — nil means all of them do.
+func (b *ColumnarBuilder) Append(dims []string, measures []float64, present []bool) {
+	v := b.view
+	row := v.n
+	for c := range v.dims {
+		val := ""
+		if c < len(dims) {
+			val = dims[c]
+		}
+		col := &v.dims[c]
+		col.codes = append(col.codes, col.code(val))
+	}
+	for c := range v.meas {
+		col := &v.meas[c]
+		val, ok := 0.0, false
+		if c < len(measures) {
+			val, ok = measures[c], present == nil || (c < len(present) && present[c])
+		}
+		if !ok {
+			val = 0
+		}
+		col.values = append(col.values, val)
+		if row>>6 >= len(col.valid) {
+			col.valid = append(col.valid, 0)
+		}
+		if ok {
+			col.valid[row>>6] |= 1 << (uint(row) & 63)
+		}
+	}
+	v.n++
+}
+
+// Build returns the finished view. The builder must not be used afterwards.
+func (b *ColumnarBuilder) Build() *ColumnarView {
+	v := b.view
+	for c := range v.dims {
+		v.dims[c].lookup = nil // only needed while appending
+	}
+	b.view = nil
+	return v
+}
+
+// NewColumnarView converts records into a ColumnarView. Keys are collected
+// in first-seen order, as in NewSliceView; missing measures become nulls.
+func NewColumnarView(records []Record) *ColumnarView {
+	var dimKeys, mesKeys []string
+	dimSeen := make(map[string]bool)
+	mesSeen := make(map[string]bool)
+	for _, r := range records {
+		for k := range r.Dimensions {
+			if !dimSeen[k] {
+				dimSeen[k] = true
+				dimKeys = append(dimKeys, k)
+			}
+		}
+		for k := range r.Measures {
+			if !mesSeen[k] {
+				mesSeen[k] = true
+				mesKeys = append(mesKeys, k)
+			}
+		}
+	}
+
+	b := NewColumnarBuilder(dimKeys, mesKeys)
+	dims := make([]string, len(dimKeys))
+	meas := make([]float64, len(mesKeys))
+	present := make([]bool, len(mesKeys))
+	for _, r := range records {
+		for i, k := range dimKeys {
+			dims[i] = r.Dimensions[k]
+		}
+		for i, k := range mesKeys {
+			meas[i], present[i] = r.Measures[k]
+		}
+		b.Append(dims, meas, present)
+	}
+	return b.Build()
+}
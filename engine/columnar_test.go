@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// ============================================================================
+// COLUMNAR VIEW TESTS
+// ============================================================================
+
+func columnarRecords() []Record {
+	return []Record{
+		{Dimensions: map[string]string{"team": "Core", "month": "Jan-2026", "status": "Open"}, Measures: map[string]float64{"hours": 8}},
+		{Dimensions: map[string]string{"team": "Web", "month": "Jan-2026", "status": "Closed"}, Measures: map[string]float64{"hours": 3}},
+		{Dimensions: map[string]string{"team": "Core", "month": "Feb-2026", "status": "Closed"}, Measures: map[string]float64{}},
+		{Dimensions: map[string]string{"team": "", "month": "Feb-2026", "status": "Open"}, Measures: map[string]float64{"hours": 5}},
+		{Dimensions: map[string]string{"team": "Data", "month": "Mar-2026", "status": "open"}, Measures: map[string]float64{"hours": 2}},
+	}
+}
+
+func TestColumnarViewMatchesSliceView(t *testing.T) {
+	records := columnarRecords()
+	slice, columnar := NewSliceView(records), NewColumnarView(records)
+
+	specs := []QuerySpec{
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, SortBy: "value_desc", Visualize: "bar"},
+		{Intent: "chart", Aggregation: "avg", Measure: "hours", GroupBy: []string{"month"}, SortBy: "chronological", Visualize: "line"},
+		{Intent: "table", Aggregation: "count", Measure: "hours", GroupBy: []string{"team", "status"}, Visualize: "table"},
+		{Intent: "text", Aggregation: "sum", Measure: "hours",
+			Filters: Filters{Dimensions: map[string][]string{"status": {"OPEN"}, "team": {"core", "(blank)"}}}},
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"year"}, Visualize: "bar"},
+	}
+	for _, spec := range specs {
+		want, err := Execute(spec, slice)
+		if err != nil {
+			t.Fatalf("Execute(slice) failed: %v", err)
+		}
+		got, err := Execute(spec, columnar)
+		if err != nil {
+			t.Fatalf("Execute(columnar) failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s by %v: columnar result differs\n got: %+v\nwant: %+v", spec.Aggregation, spec.GroupBy, got, want)
+		}
+	}
+}
+
+func TestColumnarViewEncoding(t *testing.T) {
+	view := NewColumnarView(columnarRecords())
+
+	if got := view.Cardinality("team"); got != 3 {
+		t.Errorf("Cardinality(team) = %d, want 3", got)
+	}
+	if got := view.NullCount("hours"); got != 1 {
+		t.Errorf("NullCount(hours) = %d, want 1", got)
+	}
+	if HasMeasure(view, 2, "hours") || !HasMeasure(view, 0, "hours") {
+		t.Error("HasMeasure should follow the validity bitmap")
+	}
+	if view.DimensionCode(0, "team") != view.DimensionCode(2, "team") {
+		t.Error("equal values should share a code")
+	}
+	if view.Dimension(3, "team") != "" || view.DimensionCode(3, "team") != 0 {
+		t.Error("blank values should use code 0")
+	}
+}
+
+func TestColumnarCodesForwardThroughWrappers(t *testing.T) {
+	view := NewColumnarView(columnarRecords())
+	filtered := ApplyFilters(view, Filters{Dimensions: map[string][]string{"status": {"closed"}}})
+
+	if _, dict, ok := dimensionDictionary(filtered, "team"); !ok || len(dict) != 4 {
+		t.Fatalf("SubView should forward the team dictionary, got %v", dict)
+	}
+	if filtered.Len() != 2 || filtered.Dimension(1, "team") != "Core" {
+		t.Errorf("filtered rows = %d, want 2 ending in Core", filtered.Len())
+	}
+
+	tagged := NewTagView(view, map[string]string{"team": ";"})
+	if _, _, ok := dimensionDictionary(tagged, "team"); ok {
+		t.Error("TagView must not expose codes for multi-value dimensions")
+	}
+	blank := newBlankView(view, []string{"team"}, DefaultBlankLabel)
+	if _, _, ok := dimensionDictionary(blank, "team"); ok {
+		t.Error("BlankView must not expose codes for relabelled dimensions")
+	}
+}
+
+func TestColumnarBuilderNulls(t *testing.T) {
+	b := NewColumnarBuilder([]string{"team"}, []string{"hours", "cost"})
+	b.Append([]string{"Core"}, []float64{7.5, 0}, []bool{true, false})
+	b.Append([]string{"Web"}, []float64{2}, nil) // short row: cost missing
+	view := b.Build()
+
+	if view.Len() != 2 || view.Measure(0, "hours") != 7.5 {
+		t.Fatalf("unexpected view contents")
+	}
+	if HasMeasure(view, 0, "cost") || HasMeasure(view, 1, "cost") {
+		t.Error("cost should be null on both rows")
+	}
+	if !HasMeasure(view, 1, "hours") {
+		t.Error("nil present should mark supplied measures valid")
+	}
+}
+
+func TestColumnarSubGroupsOverLargeDictionary(t *testing.T) {
+	// Every row has its own user; each team's sub-groups see a few of them
+	records := make([]Record, 20000)
+	for i := range records {
+		records[i] = Record{
+			Dimensions: map[string]string{"team": fmt.Sprintf("T%d", i%2000), "user": fmt.Sprintf("u%d", i)},
+			Measures:   map[string]float64{"hours": float64(i % 7)},
+		}
+	}
+	spec := QuerySpec{Intent: "table", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team", "user"}, Visualize: "table"}
+	want, err := Execute(spec, NewSliceView(records))
+	if err != nil {
+		t.Fatalf("Execute(slice) failed: %v", err)
+	}
+	for _, n := range []int{0, 4} {
+		got, err := Execute(spec, NewColumnarView(records), WithParallelism(n))
+		if err != nil {
+			t.Fatalf("Execute(columnar) failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parallelism %d: columnar sub-groups differ from slice", n)
+		}
+	}
+
+	// A sub-group pass sizes its lookup to its rows, not the dictionary
+	if g := newCodeGroups(len(records)+1, 10); g.slots != nil {
+		t.Errorf("10 rows over a %d-value dictionary allocated a slot per code", len(records)+1)
+	}
+	if g := newCodeGroups(5, 1000); g.slots == nil {
+		t.Error("a small dictionary should use dense slots")
+	}
+}
@@ -0,0 +1,223 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// CONTEXT & LIMITS — cancellation, deadlines and resource caps for Execute
+// ============================================================================
+// Servers and Lambdas need to stop work nobody is waiting for:
+//
+//   ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+//   defer cancel()
+//   result, err := engine.ExecuteContext(ctx, spec, view,
+//       engine.WithLimits(engine.Limits{MaxRecords: 5_000_000, MaxRows: 10_000}))
+//   if errors.Is(err, context.DeadlineExceeded) { ... }
+//   if errors.Is(err, engine.ErrLimitExceeded) { ... }
+//
+// Cancellation is checked on every row read: the raw view is wrapped in a
+// contextView whose accessors load an atomic flag that context.AfterFunc
+// sets when the context ends — one uncontended load per cell, no channel
+// select — so every filter, grouping and aggregation loop (parallel shards
+// included) stops within one row of the context ending. A stop unwinds by
+// panicking with an abort value, which ExecuteContext recovers into an
+// ordinary error. Contexts that can never be cancelled (context.Background)
+// skip the wrapper entirely.
+// ============================================================================
+
+// ErrLimitExceeded is matched by every *LimitError (errors.Is).
+var ErrLimitExceeded = errors.New("resource limit exceeded")
+
+// Limits caps the work and output of one query. Zero values mean unlimited.
+type Limits struct {
+	MaxRecords int `json:"maxRecords,omitempty"` // rows Execute may scan (cube answers scan none)
+	MaxGroups  int `json:"maxGroups,omitempty"`  // groups and sub-groups, before QuerySpec.Limit
+	MaxRows    int `json:"maxRows,omitempty"`    // table rows, or points per chart series
+}
+
+// LimitError reports which limit a query exceeded.
+type LimitError struct {
+	Limit  string // "records", "groups" or "rows"
+	Max    int
+	Actual int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit exceeded: %d > %d", e.Limit, e.Actual, e.Max)
+}
+
+// Is makes errors.Is(err, ErrLimitExceeded) true for any LimitError.
+func (e *LimitError) Is(target error) bool { return target == ErrLimitExceeded }
+
+// WithLimits fails queries that would scan, group or return too much with
+// a *LimitError.
+func WithLimits(limits Limits) Option {
+	return func(c *config) {
+		c.Limits = limits
+	}
+}
+
+// ExecuteContext is Execute with cancellation: it returns an error wrapping
+// ctx.Err() once ctx is cancelled or its deadline passes.
+func ExecuteContext(ctx context.Context, spec QuerySpec, view RecordView, opts ...Option) (result *Result, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("query cancelled: %w", err)
+	}
+	cfg := applyOptions(opts)
+	if cfg.Explain {
+		cfg.trace = &Trace{}
+	}
+	start := time.Now()
+	view = pinView(view)
+	defer cfg.startExecute(ctx, spec, view)(&err)
+	defer cfg.endOpenStage(&err)
+	defer recoverAbort(&err)
+
+	result, err = execute(cfg.ctx, spec, view, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOutputRows(result, cfg.Limits); err != nil {
+		return nil, err
+	}
+	result.DataVersion = viewVersion(view)
+	if cfg.trace != nil {
+		cfg.trace.TotalMs = millis(time.Since(start))
+		result.Trace = cfg.trace
+	}
+	return result, nil
+}
+
+// abort carries an error out of the engine's loops; see ExecuteContext.
+type abort struct{ err error }
+
+// recoverAbort, deferred, turns an abort into *err. Other panics propagate.
+func recoverAbort(err *error) {
+	if r := recover(); r != nil {
+		a, ok := r.(abort)
+		if !ok {
+			panic(r)
+		}
+		*err = a.err
+	}
+}
+
+// observeGroups traces the group count and stops the query when it exceeds
+// the group limit.
+func (c *config) observeGroups(groups []Group) {
+	n := 0
+	for _, g := range groups {
+		n++
+		n += len(g.SubGroups)
+	}
+	if c.trace != nil {
+		c.trace.Groups = n
+	}
+	if c.Limits.MaxGroups > 0 && n > c.Limits.MaxGroups {
+		panic(abort{&LimitError{Limit: "groups", Max: c.Limits.MaxGroups, Actual: n}})
+	}
+}
+
+// checkRecords fails when a raw scan would read more rows than allowed.
+func (l Limits) checkRecords(n int) error {
+	if l.MaxRecords > 0 && n > l.MaxRecords {
+		return &LimitError{Limit: "records", Max: l.MaxRecords, Actual: n}
+	}
+	return nil
+}
+
+// checkOutputRows fails when a result has too many table rows or chart points.
+func checkOutputRows(result *Result, l Limits) error {
+	if l.MaxRows <= 0 || result == nil {
+		return nil
+	}
+	n := 0
+	if result.TableData != nil {
+		n = len(result.TableData.Rows)
+	}
+	if result.ChartConfig != nil {
+		for _, s := range result.ChartConfig.Series {
+			if len(s.Data) > n {
+				n = len(s.Data)
+			}
+		}
+	}
+	if n > l.MaxRows {
+		return &LimitError{Limit: "rows", Max: l.MaxRows, Actual: n}
+	}
+	return nil
+}
+
+// ============================================================================
+// CONTEXT VIEW — cancellation checks on every row read
+// ============================================================================
+
+// contextView aborts the query once ctx is done. It forwards the optional
+// view interfaces so dictionary grouping and filter indexes keep working.
+type contextView struct {
+	parent RecordView
+	ctx    context.Context
+	done   *atomic.Bool // set by context.AfterFunc
+}
+
+// withContext wraps view for ctx, or returns it unchanged when ctx can
+// never be cancelled. stop releases the AfterFunc once the query is over.
+func withContext(ctx context.Context, view RecordView) (wrapped RecordView, stop func()) {
+	if ctx.Done() == nil {
+		return view, func() {}
+	}
+	v := &contextView{parent: view, ctx: ctx, done: new(atomic.Bool)}
+	release := context.AfterFunc(ctx, func() { v.done.Store(true) })
+	return v, func() { release() }
+}
+
+func (v *contextView) check() {
+	if v.done.Load() {
+		panic(abort{fmt.Errorf("query cancelled: %w", v.ctx.Err())})
+	}
+}
+
+func (v *contextView) Len() int { return v.parent.Len() }
+
+func (v *contextView) Dimension(i int, key string) string {
+	v.check()
+	return v.parent.Dimension(i, key)
+}
+
+func (v *contextView) Measure(i int, key string) float64 {
+	v.check()
+	return v.parent.Measure(i, key)
+}
+
+func (v *contextView) HasMeasure(i int, key string) bool {
+	v.check()
+	return HasMeasure(v.parent, i, key)
+}
+
+func (v *contextView) DimensionValues(i int, key string) ([]string, bool) {
+	v.check()
+	return DimensionValues(v.parent, i, key)
+}
+
+func (v *contextView) Dictionary(key string) ([]string, bool) {
+	_, dict, ok := dimensionDictionary(v.parent, key)
+	return dict, ok
+}
+
+func (v *contextView) DimensionCode(i int, key string) uint32 {
+	v.check()
+	return v.parent.(CodedView).DimensionCode(i, key)
+}
+
+func (v *contextView) FilterIndex(key string) (*DimensionIndex, bool) {
+	v.check()
+	return filterIndex(v.parent, key)
+}
+
+func (v *contextView) DimensionKeys() []string { return v.parent.DimensionKeys() }
+func (v *contextView) MeasureKeys() []string   { return v.parent.MeasureKeys() }
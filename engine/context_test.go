@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// CONTEXT & LIMITS TESTS
+// ============================================================================
+
+// cancellingView cancels its context after a number of dimension reads.
+// Reads from then on pause, giving the engine's context.AfterFunc time to
+// run, as a cancellation from another goroutine would.
+type cancellingView struct {
+	RecordView
+	after  int64
+	reads  atomic.Int64
+	cancel context.CancelFunc
+}
+
+func (v *cancellingView) Dimension(i int, key string) string {
+	n := v.reads.Add(1)
+	if n == v.after {
+		v.cancel()
+	}
+	if n >= v.after {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return v.RecordView.Dimension(i, key)
+}
+
+func TestExecuteContextMatchesExecute(t *testing.T) {
+	view := NewColumnarView(cubeRecords())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, spec := range []QuerySpec{
+		cacheSpec(),
+		{Intent: "table", Aggregation: "list", Measure: "hours", Visualize: "table", Limit: 5},
+		{Intent: "text", Aggregation: "avg", Measure: "hours", Filters: Filters{Dimensions: map[string][]string{"status": {"open"}}}},
+	} {
+		want, _ := Execute(spec, view)
+		got, err := ExecuteContext(ctx, spec, view, WithLimits(Limits{MaxRecords: 400, MaxGroups: 50, MaxRows: 400}))
+		if err != nil {
+			t.Fatalf("ExecuteContext failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s %s: result differs from Execute", spec.Intent, spec.Aggregation)
+		}
+	}
+}
+
+func TestExecuteContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ExecuteContext(ctx, cacheSpec(), NewSliceView(cubeRecords())); !errors.Is(err, context.Canceled) {
+		t.Errorf("already cancelled: err = %v, want context.Canceled", err)
+	}
+
+	expired, stop := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer stop()
+	if _, err := ExecuteContext(expired, cacheSpec(), NewSliceView(cubeRecords())); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("past deadline: err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestExecuteContextCancelledMidQuery(t *testing.T) {
+	for _, workers := range []int{0, 4} {
+		ctx, cancel := context.WithCancel(context.Background())
+		view := &cancellingView{RecordView: NewSliceView(cubeRecords()), after: 50, cancel: cancel}
+		_, err := ExecuteContext(ctx, cacheSpec(), view, WithParallelism(workers))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("workers %d: err = %v, want context.Canceled", workers, err)
+		}
+		// Each worker finishes at most the read it was paused in
+		if reads := view.reads.Load(); reads > 50+int64(workers) {
+			t.Errorf("workers %d: %d reads after cancelling at 50", workers, reads)
+		}
+		cancel()
+	}
+}
+
+func TestExecuteLimits(t *testing.T) {
+	view := NewSliceView(cubeRecords())
+	list := QuerySpec{Intent: "table", Aggregation: "list", Measure: "hours", Visualize: "table"}
+	byTicket := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"ticket"}, Visualize: "bar", Limit: 5}
+
+	tests := []struct {
+		name   string
+		spec   QuerySpec
+		limits Limits
+		limit  string
+	}{
+		{"records", cacheSpec(), Limits{MaxRecords: 100}, "records"},
+		{"groups before spec limit", byTicket, Limits{MaxGroups: 100}, "groups"},
+		{"list rows", list, Limits{MaxRows: 10}, "rows"},
+	}
+	for _, tt := range tests {
+		_, err := Execute(tt.spec, view, WithLimits(tt.limits))
+		var le *LimitError
+		if !errors.As(err, &le) || !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: err = %v, want a LimitError", tt.name, err)
+			continue
+		}
+		if le.Limit != tt.limit || le.Actual <= le.Max {
+			t.Errorf("%s: got %+v", tt.name, le)
+		}
+	}
+
+	// Output within bounds succeeds
+	list.Filters = Filters{Dimensions: map[string][]string{"ticket": {"T-1", "T-2", "T-3"}}}
+	if _, err := Execute(list, view, WithLimits(Limits{MaxRows: 10})); err != nil {
+		t.Errorf("limited list: %v", err)
+	}
+
+	// Parallel grouping and cube answers honour the group limit too
+	_, err := Execute(byTicket, view, WithParallelism(4), WithLimits(Limits{MaxGroups: 100}))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("parallel: err = %v", err)
+	}
+	cube, err := BuildCube(view, CubeSpec{Dimensions: []string{"team", "status"}, Measures: []string{"hours"}, Aggregations: []string{"sum"}})
+	if err != nil {
+		t.Fatalf("BuildCube failed: %v", err)
+	}
+	byStatus := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team", "status"}, Visualize: "bar"}
+	if _, err := Execute(byStatus, view, WithCube(cube), WithLimits(Limits{MaxRecords: 1, MaxGroups: 3})); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("cube: err = %v", err)
+	}
+	if _, err := Execute(byStatus, view, WithCube(cube), WithLimits(Limits{MaxRecords: 1})); err != nil {
+		t.Errorf("cube answers should not count as scanned records: %v", err)
+	}
+}
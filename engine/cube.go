@@ -0,0 +1,420 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spektr-org/spektr/observe"
+)
+
+// ============================================================================
+// ROLLUP CUBES — pre-aggregated answers for repeated dashboard queries
+// ============================================================================
+// A cube materializes one cell per distinct combination of its dimensions,
+// holding mergeable partial aggregates (rows, sum, count, min, max) for each
+// of its measures:
+//
+//   cube, _ := engine.BuildCube(view, engine.CubeSpec{
+//       Dimensions:   []string{"team", "status", "month"},
+//       Measures:     []string{"hours"},
+//       Aggregations: []string{"sum", "count", "avg"},
+//   })
+//   engine.Execute(spec, view, engine.WithCube(cube))
+//
+// Execute answers a QuerySpec from the cube when every dimension it filters,
+// groups or sorts by is a cube dimension and its measure/aggregation pair was
+// declared; anything else falls back to the raw records. Cells are filtered
+// and grouped exactly like records, then their partials are merged.
+//
+// Only mergeable aggregations are supported — median, list, growth and
+// ratio always read raw records. Sums are merged cell by cell, so they may
+// differ from the raw path in the last floating-point bits.
+// ============================================================================
+
+// CubeSpec declares what a cube pre-aggregates.
+type CubeSpec struct {
+	Dimensions   []string `json:"dimensions"`
+	Measures     []string `json:"measures"`
+	Aggregations []string `json:"aggregations"` // "sum", "count", "avg", "min", "max"
+}
+
+// cubeAggregations lists the mergeable aggregations a cube can serve.
+var cubeAggregations = map[string]bool{
+	"sum": true, "count": true, "avg": true, "min": true, "max": true,
+}
+
+// Cell measure columns. The plain measure key holds the cell sum, so period
+// and growth helpers read cells like records.
+const (
+	cubeRowsKey  = "\x00rows"
+	cubeCountSfx = "\x00n"
+	cubeSumNSfx  = "\x00sumN"
+	cubeMinSfx   = "\x00min"
+	cubeMaxSfx   = "\x00max"
+	cubeMonthDim = "month"
+)
+
+// Cube is a materialized rollup of a RecordView. Immutable and safe for
+// concurrent queries; rebuild it when the source changes.
+type Cube struct {
+	spec         CubeSpec
+	dims         map[string]bool
+	measures     map[string]bool
+	aggregations map[string]bool
+	source       RecordView
+	rows         int
+	version      uint64
+	calendar     Calendar
+	hasMonth     bool // source has month values (period / growth need them)
+	cells        *ColumnarView
+}
+
+// BuildCube materializes a cube over view. Derived calendar buckets
+// ("quarter", "week", fiscal "year") follow the WithCalendar option, which
+// must match the one passed to Execute for the cube to be used.
+func BuildCube(view RecordView, spec CubeSpec, opts ...Option) (*Cube, error) {
+	c, _, err := buildCube(view, spec, opts...)
+	return c, err
+}
+
+// buildCube is BuildCube that also returns the cell builder, so an
+// AppendableView can keep extending the cube.
+func buildCube(view RecordView, spec CubeSpec, opts ...Option) (*Cube, *cellBuilder, error) {
+	cfg := applyOptions(opts)
+	if err := cfg.Calendar.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid calendar: %w", err)
+	}
+	if len(spec.Measures) == 0 {
+		return nil, nil, fmt.Errorf("cube needs at least one measure")
+	}
+	if len(spec.Aggregations) == 0 {
+		return nil, nil, fmt.Errorf("cube needs at least one aggregation")
+	}
+	for _, a := range spec.Aggregations {
+		if !cubeAggregations[a] {
+			return nil, nil, fmt.Errorf("aggregation %q is not mergeable; cubes support sum, count, avg, min, max", a)
+		}
+	}
+
+	c := &Cube{
+		spec:         spec,
+		dims:         toSet(spec.Dimensions),
+		measures:     toSet(spec.Measures),
+		aggregations: toSet(spec.Aggregations),
+		source:       view,
+		rows:         view.Len(),
+		version:      viewVersion(view),
+		calendar:     cfg.Calendar,
+	}
+	src := NewCalendarView(view, cfg.Calendar)
+
+	cells := newCellBuilder(spec.Dimensions, spec.Measures)
+	for i := 0; i < src.Len(); i++ {
+		if !c.hasMonth && src.Dimension(i, cubeMonthDim) != "" {
+			c.hasMonth = true
+		}
+		cells.add(src, i)
+	}
+	c.cells = cells.build()
+	return c, cells, nil
+}
+
+// ============================================================================
+// CELL BUILDER
+// ============================================================================
+
+// cellBuilder accumulates one cell per distinct dimension tuple, in
+// first-seen order. Shared by cubes and the streaming executor.
+type cellBuilder struct {
+	dims     []string
+	measures []string
+	index    map[string]int
+	tuples   [][]string
+	partials [][]partialAggregate
+	tuple    []string
+}
+
+func newCellBuilder(dims, measures []string) *cellBuilder {
+	return &cellBuilder{
+		dims:     dims,
+		measures: measures,
+		index:    make(map[string]int),
+		tuple:    make([]string, len(dims)),
+	}
+}
+
+// add folds record i of view into its cell.
+func (b *cellBuilder) add(view RecordView, i int) {
+	for d, dim := range b.dims {
+		b.tuple[d] = getDimensionValue(view, i, dim)
+	}
+	key := strings.Join(b.tuple, "\x00")
+	cell, ok := b.index[key]
+	if !ok {
+		cell = len(b.tuples)
+		b.index[key] = cell
+		b.tuples = append(b.tuples, append([]string(nil), b.tuple...))
+		p := make([]partialAggregate, len(b.measures))
+		for m := range p {
+			p[m] = newPartialAggregate()
+		}
+		b.partials = append(b.partials, p)
+	}
+	for m, measure := range b.measures {
+		b.partials[cell][m].add(view.Measure(i, measure), HasMeasure(view, i, measure))
+	}
+}
+
+// cellMeasureKeys lists the cell columns: rows, then five per measure.
+func cellMeasureKeys(measures []string) []string {
+	keys := []string{cubeRowsKey}
+	for _, m := range measures {
+		keys = append(keys, m, m+cubeCountSfx, m+cubeSumNSfx, m+cubeMinSfx, m+cubeMaxSfx)
+	}
+	return keys
+}
+
+// build returns the cells as a columnar view: dimensions plus one column per
+// partial field.
+func (b *cellBuilder) build() *ColumnarView {
+	measKeys := cellMeasureKeys(b.measures)
+	cb := NewColumnarBuilder(b.dims, measKeys)
+	values := make([]float64, len(measKeys))
+	present := make([]bool, len(measKeys))
+	for cell, t := range b.tuples {
+		values[0], present[0] = 0, true
+		if len(b.partials[cell]) > 0 {
+			values[0] = float64(b.partials[cell][0].rows)
+		}
+		for m, p := range b.partials[cell] {
+			col := 1 + m*5
+			values[col], values[col+1], values[col+2] = p.sum, float64(p.n), p.sumN
+			values[col+3], values[col+4] = p.min, p.max
+			for k := 0; k < 5; k++ {
+				present[col+k] = k < 3 || p.n > 0
+			}
+		}
+		cb.Append(t, values, present)
+	}
+	return cb.Build()
+}
+
+// Cells returns the number of materialized cells.
+func (c *Cube) Cells() int { return c.cells.Len() }
+
+// Spec returns the cube's declaration.
+func (c *Cube) Spec() CubeSpec { return c.spec }
+
+// viewVersion returns a VersionedView's version, or 0.
+func viewVersion(view RecordView) uint64 {
+	if vv, ok := view.(VersionedView); ok {
+		return vv.Version()
+	}
+	return 0
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// ============================================================================
+// COMPATIBILITY
+// ============================================================================
+
+// answers reports whether the cube can answer spec over view exactly as the
+// raw path would.
+func (c *Cube) answers(spec QuerySpec, view RecordView, measure string, cfg *config) bool {
+	// Built from this data, unchanged since
+	if c.source != view || view.Len() != c.rows || viewVersion(view) != c.version {
+		return false
+	}
+	if cfg.Calendar != c.calendar {
+		return false
+	}
+
+	// Mergeable single-measure aggregates only
+	if spec.TimeRange != nil || len(spec.Measures) > 1 || spec.CompareFilters != nil {
+		return false
+	}
+	if !c.measures[measure] || !c.aggregations[spec.Aggregation] {
+		return false
+	}
+
+	// Every dimension read must be a cube dimension
+	if len(spec.GroupBy) > 2 {
+		return false
+	}
+	for _, dim := range spec.GroupBy {
+		if !c.dims[dim] {
+			return false
+		}
+	}
+	for dim, vals := range spec.Filters.Dimensions {
+		if len(vals) > 0 && !c.dims[dim] {
+			return false
+		}
+	}
+	for _, k := range spec.SortKeys {
+		if k.By == "measure" || (k.Dimension != "" && !c.dims[k.Dimension]) {
+			return false
+		}
+	}
+	for dim := range cfg.MultiValue {
+		if c.dims[dim] {
+			return false // cells hold raw cells, not split tags
+		}
+	}
+	if cfg.CurrencyDimension != "" && !c.dims[cfg.CurrencyDimension] {
+		return false
+	}
+
+	// Text values and reply templates report the period from "month"
+	needsPeriod := (spec.Intent != "chart" && spec.Intent != "table") || spec.Reply != ""
+	if needsPeriod && c.hasMonth && !c.dims[cubeMonthDim] {
+		return false
+	}
+	return true
+}
+
+// cubeFor returns the first cube that answers spec: those the view
+// maintains itself (AppendableView) first, then the configured ones.
+func (cfg *config) cubeFor(spec QuerySpec, view RecordView, measure string) *Cube {
+	if mv, ok := view.(interface{ viewCubes() []*Cube }); ok {
+		for _, c := range mv.viewCubes() {
+			if c.answers(spec, view, measure, cfg) {
+				return c
+			}
+		}
+	}
+	for _, c := range cfg.Cubes {
+		if c.answers(spec, view, measure, cfg) {
+			return c
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// CUBE EXECUTION
+// ============================================================================
+
+// mergeCells merges the partials of every cell in view for one measure.
+func mergeCells(view RecordView, measure string) partialAggregate {
+	total := newPartialAggregate()
+	for i := 0; i < view.Len(); i++ {
+		p := partialAggregate{
+			rows: int(view.Measure(i, cubeRowsKey)),
+			sum:  view.Measure(i, measure),
+			n:    int(view.Measure(i, measure+cubeCountSfx)),
+			sumN: view.Measure(i, measure+cubeSumNSfx),
+			min:  view.Measure(i, measure+cubeMinSfx),
+			max:  view.Measure(i, measure+cubeMaxSfx),
+		}
+		if p.n == 0 {
+			p.min, p.max = total.min, total.max
+		}
+		total.merge(p)
+	}
+	return total
+}
+
+// executeCube answers spec from a configured cube. Returns false when no cube
+// is compatible, or when currency conversion would be needed.
+func executeCube(spec QuerySpec, view RecordView, measure string, cfg *config) (*Result, bool) {
+	cube := cfg.cubeFor(spec, view, measure)
+	if cube == nil {
+		return nil, false
+	}
+	st := cfg.stage("cube")
+	defer st.end()
+
+	filtered := applyFilters(cube.cells, spec.Filters, 0, cfg.blankLabel())
+	plan := cfg.planCurrency(filtered, []string{measure})
+	if plan.converted {
+		return nil, false // conversion is per record
+	}
+
+	cfg.log(observe.LevelInfo, "answering from cube", observe.Int("cells", filtered.Len()), observe.Int("totalCells", cube.Cells()))
+	cfg.trace.path("cube")
+	cfg.trace.filters("", cube.cells, spec.Filters, cfg) // counts are cells, not records
+	cfg.trace.currency(filtered, cfg, false)
+	return answerFromCells(spec, filtered, filtered, measure, plan.unit, cfg), true
+}
+
+// answerFromCells builds a result from filtered cells. Filters must already
+// be applied; list tables are built from listView.
+func answerFromCells(spec QuerySpec, cells, listView RecordView, measure, displayUnit string, cfg *config) *Result {
+	if cells.Len() == 0 {
+		return &Result{
+			Success:   true,
+			Type:      "text",
+			Reply:     "No records match your query filters. Try broadening your search.",
+			TimeRange: spec.resolvedRange(),
+		}
+	}
+	cells = applyBlankPolicy(cells, spec.GroupBy, cfg)
+	groups := groupView(cells, spec.GroupBy, 0)
+	cfg.observeGroups(groups)
+	for i := range groups {
+		aggregateCells(&groups[i], measure, spec.Aggregation)
+		for j := range groups[i].SubGroups {
+			aggregateCells(&groups[i].SubGroups[j], measure, spec.Aggregation)
+		}
+	}
+	groups = sortAndLimit(groups, newGroupSorter(spec, cfg.ValueOrders), spec.Limit)
+	cfg.trace.returned(groups)
+
+	all := mergeCells(cells, measure)
+	cfg.trace.matched(all.rows)
+	period := DerivePeriod(cells, spec.resolvedRange())
+	f := cfg.valueFormat(measure, displayUnit)
+	result := &Result{
+		Success:     true,
+		DisplayUnit: displayUnit,
+		TimeRange:   spec.resolvedRange(),
+	}
+	switch spec.Intent {
+	case "chart":
+		result.Type = "chart"
+		result.ChartConfig = BuildChart(spec, groups)
+		if result.ChartConfig == nil {
+			result.Type = "text"
+			result.Reply = "Not enough data to generate a chart."
+			return result
+		}
+		f.decorateChart(result.ChartConfig, spec.Aggregation)
+	case "table":
+		result.Type = "table"
+		result.TableData = buildTable(spec, groups, listView, measure, f)
+	default:
+		result.Type = "text"
+		result.Data = newTextData(spec.Aggregation, all.result(spec.Aggregation), all.rows, period, f)
+	}
+
+	if spec.Reply == "" {
+		result.Reply = fmt.Sprintf("Found %d records totalling %s.", all.rows, f.value(all.sum))
+		return result
+	}
+	stats := replyStats{
+		total:  all.sum,
+		count:  all.rows,
+		period: period,
+		growth: BuildGrowthText(cells, measure, displayUnit).Growth,
+	}
+	if all.rows > 0 {
+		stats.avg, stats.max, stats.min = all.result("avg"), all.result("max"), all.result("min")
+	}
+	result.Reply = resolveReply(spec.Reply, groups, stats, f)
+	return result
+}
+
+// aggregateCells sets a cell group's value and record count.
+func aggregateCells(group *Group, measure, aggregation string) {
+	p := mergeCells(group.View, measure)
+	group.Count = p.rows
+	group.Value = p.result(aggregation)
+}
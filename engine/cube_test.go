@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// ============================================================================
+// ROLLUP CUBE TESTS
+// ============================================================================
+
+// cubeRecords uses binary-exact values so merged sums match raw sums bit for
+// bit; TestCubeInexactSumsMatchRawRecords covers values that are not.
+func cubeRecords() []Record {
+	teams := []string{"Core", "Web", "Data", ""}
+	months := []string{"Jan-2026", "Feb-2026", "Mar-2026", "Apr-2026"}
+	records := make([]Record, 0, 400)
+	for i := 0; i < 400; i++ {
+		measures := map[string]float64{"hours": float64(i%13) + 0.25*float64(i%4)}
+		if i%17 == 0 {
+			measures = map[string]float64{} // missing hours
+		}
+		records = append(records, Record{
+			Dimensions: map[string]string{
+				"team":     teams[(i*5)%len(teams)],
+				"status":   []string{"Open", "Closed", "Blocked"}[i%3],
+				"month":    months[(i/7)%len(months)],
+				"currency": "SGD",
+				"ticket":   fmt.Sprintf("T-%d", i),
+			},
+			Measures: measures,
+		})
+	}
+	return records
+}
+
+func cubeFixture(t *testing.T) (RecordView, *Cube) {
+	t.Helper()
+	view := NewSliceView(cubeRecords())
+	cube, err := BuildCube(view, CubeSpec{
+		Dimensions:   []string{"team", "status", "month", "quarter", "currency"},
+		Measures:     []string{"hours"},
+		Aggregations: []string{"sum", "count", "avg", "min", "max"},
+	})
+	if err != nil {
+		t.Fatalf("BuildCube failed: %v", err)
+	}
+	return view, cube
+}
+
+func TestCubeResultsMatchRawRecords(t *testing.T) {
+	view, cube := cubeFixture(t)
+	if cube.Cells() >= view.Len() {
+		t.Fatalf("cube has %d cells for %d records", cube.Cells(), view.Len())
+	}
+
+	specs := []QuerySpec{
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, SortBy: "value_desc", Visualize: "bar"},
+		{Intent: "chart", Aggregation: "avg", Measure: "hours", GroupBy: []string{"month"}, SortBy: "chronological", Visualize: "line",
+			Filters: Filters{Dimensions: map[string][]string{"status": {"open", "BLOCKED"}}}},
+		{Intent: "chart", Aggregation: "count", Measure: "hours", GroupBy: []string{"status", "team"}, Visualize: "stacked_bar", Limit: 2},
+		{Intent: "chart", Aggregation: "max", Measure: "hours", GroupBy: []string{"quarter"}, Visualize: "bar"},
+		{Intent: "table", Aggregation: "min", Measure: "hours", GroupBy: []string{"team"}, Visualize: "table",
+			SortKeys: []SortKey{{By: "count", Desc: true}, {By: "label"}}},
+		{Intent: "text", Aggregation: "sum", Measure: "hours",
+			Filters: Filters{Dimensions: map[string][]string{"team": {"(blank)", "web"}}},
+			Reply:   "{total} over {count} tickets in {period}; avg {avg}, top {top_category}, {direction} {growth_percent}."},
+		{Intent: "text", Aggregation: "avg", Measure: "hours"},
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, Visualize: "pie",
+			Filters: Filters{Dimensions: map[string][]string{"team": {"nobody"}}}},
+	}
+	for _, spec := range specs {
+		for _, opts := range [][]Option{nil, {WithExcludeBlanks()}, {WithBlankLabel("Unassigned")}} {
+			want, err := Execute(spec, view, opts...)
+			if err != nil {
+				t.Fatalf("raw Execute failed: %v", err)
+			}
+			got, err := Execute(spec, view, append(opts, WithCube(cube))...)
+			if err != nil {
+				t.Fatalf("cube Execute failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %s by %v: cube result differs\n got: %+v\nwant: %+v",
+					spec.Intent, spec.Aggregation, spec.GroupBy, got, want)
+			}
+		}
+	}
+}
+
+// TestCubeInexactSumsMatchRawRecords uses values that are not exactly
+// representable, so merged cell sums may differ from record-order sums in
+// the last bits. Every number must still agree to within rounding, and
+// everything else — labels, order, counts, formatted values — exactly.
+func TestCubeInexactSumsMatchRawRecords(t *testing.T) {
+	records := cubeRecords()
+	for i := range records {
+		if len(records[i].Measures) > 0 {
+			records[i].Measures["hours"] = float64(i%37)*0.1 + float64(i%7)*0.01
+		}
+	}
+	view := NewSliceView(records)
+	cube, err := BuildCube(view, CubeSpec{
+		Dimensions:   []string{"team", "status", "month", "quarter", "currency"},
+		Measures:     []string{"hours"},
+		Aggregations: []string{"sum", "count", "avg", "min", "max"},
+	})
+	if err != nil {
+		t.Fatalf("BuildCube failed: %v", err)
+	}
+
+	specs := []QuerySpec{
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, SortBy: "label_asc", Visualize: "bar"},
+		{Intent: "chart", Aggregation: "avg", Measure: "hours", GroupBy: []string{"month", "status"}, SortBy: "chronological", Visualize: "stacked_bar"},
+		{Intent: "table", Aggregation: "sum", Measure: "hours", GroupBy: []string{"status"}, SortBy: "label_asc", Visualize: "table"},
+		{Intent: "text", Aggregation: "sum", Measure: "hours"},
+		{Intent: "text", Aggregation: "avg", Measure: "hours",
+			Filters: Filters{Dimensions: map[string][]string{"team": {"core", "(blank)"}}}},
+	}
+	cfg := applyOptions([]Option{WithCube(cube)})
+	for _, spec := range specs {
+		if cfg.cubeFor(spec, view, spec.Measure) == nil {
+			t.Fatalf("%s %s by %v: cube should answer", spec.Intent, spec.Aggregation, spec.GroupBy)
+		}
+		want, err := Execute(spec, view)
+		if err != nil {
+			t.Fatalf("raw Execute failed: %v", err)
+		}
+		got, err := Execute(spec, view, WithCube(cube))
+		if err != nil {
+			t.Fatalf("cube Execute failed: %v", err)
+		}
+		if diff := resultDiff(t, got, want); diff != "" {
+			t.Errorf("%s %s by %v: cube result differs at %s", spec.Intent, spec.Aggregation, spec.GroupBy, diff)
+		}
+	}
+}
+
+// resultDiff compares two results field by field through their JSON form,
+// allowing numbers a relative error of 1e-9. Returns the first differing
+// path, or "".
+func resultDiff(t *testing.T, got, want *Result) string {
+	t.Helper()
+	decode := func(r *Result) interface{} {
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("marshal result: %v", err)
+		}
+		var v interface{}
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Fatalf("unmarshal result: %v", err)
+		}
+		return v
+	}
+	return jsonDiff(decode(got), decode(want), "result")
+}
+
+func jsonDiff(a, b interface{}, path string) string {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return path
+		}
+		for k := range av {
+			if d := jsonDiff(av[k], bv[k], path+"."+k); d != "" {
+				return d
+			}
+		}
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return path
+		}
+		for i := range av {
+			if d := jsonDiff(av[i], bv[i], fmt.Sprintf("%s[%d]", path, i)); d != "" {
+				return d
+			}
+		}
+	case float64:
+		bv, ok := b.(float64)
+		if !ok || math.Abs(av-bv) > 1e-9*math.Max(1, math.Abs(bv)) {
+			return fmt.Sprintf("%s (%v vs %v)", path, a, b)
+		}
+	default:
+		if !reflect.DeepEqual(a, b) {
+			return fmt.Sprintf("%s (%v vs %v)", path, a, b)
+		}
+	}
+	return ""
+}
+
+func TestCubeCompatibility(t *testing.T) {
+	view, cube := cubeFixture(t)
+	cfg := applyOptions([]Option{WithCube(cube)})
+
+	base := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}}
+	if cfg.cubeFor(base, view, "hours") == nil {
+		t.Fatal("cube should answer a sum by team")
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*QuerySpec)
+		view   RecordView
+	}{
+		{"median is not mergeable", func(s *QuerySpec) { s.Aggregation = "median" }, view},
+		{"group by non-cube dimension", func(s *QuerySpec) { s.GroupBy = []string{"ticket"} }, view},
+		{"filter on non-cube dimension", func(s *QuerySpec) {
+			s.Filters = Filters{Dimensions: map[string][]string{"ticket": {"T-1"}}}
+		}, view},
+		{"undeclared measure", func(s *QuerySpec) { s.Measure = "cost" }, view},
+		{"relative time range", func(s *QuerySpec) { s.TimeRange = &TimeRange{Relative: "last_month"} }, view},
+		{"sort by another measure", func(s *QuerySpec) { s.SortKeys = []SortKey{{By: "measure", Measure: "hours"}} }, view},
+		{"different dataset", func(s *QuerySpec) {}, NewSliceView(cubeRecords())},
+	}
+	for _, tt := range tests {
+		spec := base
+		tt.modify(&spec)
+		measure := spec.Measure
+		if cfg.cubeFor(spec, tt.view, measure) != nil {
+			t.Errorf("%s: cube should not answer", tt.name)
+		}
+	}
+
+	if _, err := BuildCube(view, CubeSpec{Measures: []string{"hours"}, Aggregations: []string{"median"}}); err == nil {
+		t.Error("BuildCube should reject non-mergeable aggregations")
+	}
+}
+
+func TestCubeFallsBackWhenSourceChanges(t *testing.T) {
+	records := cubeRecords()
+	source := &versionedRecords{SliceView: NewSliceView(records).(*SliceView)}
+	cube, err := BuildCube(source, CubeSpec{
+		Dimensions: []string{"team"}, Measures: []string{"hours"}, Aggregations: []string{"sum"},
+	})
+	if err != nil {
+		t.Fatalf("BuildCube failed: %v", err)
+	}
+
+	records[1].Measures["hours"] = 1000
+	source.version++
+
+	spec := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, Visualize: "bar"}
+	want, _ := Execute(spec, source)
+	got, _ := Execute(spec, source, WithCube(cube))
+	if !reflect.DeepEqual(got, want) {
+		t.Error("stale cube should fall back to raw records")
+	}
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+// ============================================================================
+// CURRENCY NORMALIZATION TESTS — every execution path
+// ============================================================================
+
+func mixedCurrencyRecords() []Record {
+	return records([]string{"country", "currency", "month"}, []string{"spend", "budget", "hours"},
+		row{"India", "INR", "2024-01", 10000, 20000, 40}, // 100 SGD spend, 200 budget
+		row{"India", "INR", "2024-02", 30000, 20000, 10}, // 300 SGD spend, 200 budget
+		row{"Singapore", "SGD", "2024-01", 100, 150, 20},
+		row{"Singapore", "SGD", "2024-02", 500, 150, 30},
+	)
+}
+
+var sgdRates = WithCurrency("SGD", "currency", map[string]float64{"INR": 0.01})
+
+func TestCurrencyRatio(t *testing.T) {
+	view := NewSliceView(mixedCurrencyRecords())
+	spec := QuerySpec{Intent: "text", Aggregation: "ratio", Measure: "spend",
+		CompareFilters: &Filters{Dimensions: map[string][]string{"country": {"India"}}}}
+
+	result, err := Execute(spec, view, sgdRates)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	ratio := result.Data.(*TextData).Ratio
+	// India 400 SGD of 1000 SGD total — not 40000 of 40600 raw
+	if ratio.NumeratorTotal != 400 || ratio.DenominatorTotal != 1000 || math.Abs(ratio.Percentage-40) > 1e-9 {
+		t.Errorf("ratio = %+v", ratio)
+	}
+	if !result.ShouldConvert || result.DisplayUnit != "SGD" {
+		t.Errorf("shouldConvert = %v, unit = %q", result.ShouldConvert, result.DisplayUnit)
+	}
+
+	// The numerator alone is all INR; it still converts with the denominator
+	spec.Filters = Filters{Dimensions: map[string][]string{"month": {"2024-02"}}}
+	result, _ = Execute(spec, view, sgdRates)
+	if ratio := result.Data.(*TextData).Ratio; ratio.NumeratorTotal != 400 || ratio.DenominatorTotal != 800 {
+		t.Errorf("filtered ratio = %+v", ratio)
+	}
+}
+
+func TestCurrencyMultiMeasure(t *testing.T) {
+	view := NewSliceView(mixedCurrencyRecords())
+	spec := QuerySpec{Intent: "chart", Aggregation: "sum", Measures: []string{"spend", "budget", "hours"},
+		GroupBy: []string{"country"}, SortBy: "label_asc", Visualize: "bar"}
+
+	result, err := Execute(spec, view, sgdRates, WithCurrencyMeasures("spend", "budget"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	want := map[string][]float64{"Spend": {400, 600}, "Budget": {400, 300}, "Hours": {50, 50}}
+	for _, s := range result.ChartConfig.Series {
+		if len(s.Data) != 2 || s.Data[0].Value != want[s.Name][0] || s.Data[1].Value != want[s.Name][1] {
+			t.Errorf("series %s = %+v, want %v", s.Name, s.Data, want[s.Name])
+		}
+	}
+	if !result.ShouldConvert || result.DisplayUnit != "SGD" {
+		t.Errorf("shouldConvert = %v, unit = %q", result.ShouldConvert, result.DisplayUnit)
+	}
+}
+
+func TestCurrencyGrowth(t *testing.T) {
+	view := NewSliceView(mixedCurrencyRecords())
+	spec := QuerySpec{Intent: "text", Aggregation: "growth", Measure: "spend"}
+
+	result, err := Execute(spec, view, sgdRates)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	growth := result.Data.(*TextData).Growth
+	// Jan 200 SGD → Feb 800 SGD
+	if growth == nil || growth.EarliestValue != 200 || growth.LatestValue != 800 || growth.ChangePercent != 300 {
+		t.Errorf("growth = %+v", growth)
+	}
+}
+
+func TestCurrencyMeasuresSkipNonAmounts(t *testing.T) {
+	view := NewSliceView(mixedCurrencyRecords())
+	spec := QuerySpec{Intent: "text", Aggregation: "sum", Measure: "hours"}
+
+	result, err := Execute(spec, view, sgdRates, WithCurrencyMeasures("spend", "budget"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if text := result.Data.(*TextData); text.RawValue != 100 || result.ShouldConvert || result.DisplayUnit != "" {
+		t.Errorf("hours = %v, shouldConvert = %v, unit = %q", text.RawValue, result.ShouldConvert, result.DisplayUnit)
+	}
+
+	// Without WithCurrencyMeasures every queried measure is an amount
+	result, _ = Execute(QuerySpec{Intent: "text", Aggregation: "sum", Measure: "spend"}, view, sgdRates)
+	if text := result.Data.(*TextData); text.RawValue != 1000 {
+		t.Errorf("spend = %v, want 1000", text.RawValue)
+	}
+}
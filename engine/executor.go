@@ -1,10 +1,12 @@
 package engine
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
+
+	"github.com/spektr-org/spektr/observe"
 )
 
 // ============================================================================
@@ -15,7 +17,7 @@ import (
 // Pipeline:
 //   1. Apply filters from QuerySpec → SubView
 //   2. (Optional) Wrap in CurrencyView for normalization
-//   3. Group and aggregate
+//   3. Group and aggregate (blank group-by values → "(blank)" or excluded)
 //   4. Dispatch to builder (chart / table / text)
 //   5. Resolve reply template placeholders
 //   6. Return Result
@@ -29,9 +31,33 @@ import (
 //
 // Options:
 //   - WithCurrency(base, dimension, rates) — enables multi-currency normalization
+//   - WithDatedRates(table, timeDimension, mode) — convert each record at its dated rate
 //   - WithDefaultMeasure(key) — sets the measure when QuerySpec.Measure is empty
+//   - WithClock(now) — clock for resolving QuerySpec.TimeRange (default time.Now)
+//   - WithCalendar(cal) — fiscal year, week start and time zone for temporal logic
+//   - WithBlankLabel(label) / WithExcludeBlanks() — how empty group-by values are grouped
+//   - WithMultiValue(dimension, delimiter) — treat a dimension as a tag set
+//   - WithValueOrder(dimension, values) — ordinal order for "ordinal" sorts
+//   - WithParallelism(n) — shard filtering and aggregation over n goroutines
+//   - WithCube(cube) — answer compatible queries from a pre-aggregated cube
+//   - WithLimits(limits) — fail oversized queries with a *LimitError
+//   - WithExplain() — attach an execution trace to the Result
+//   - WithObserver(o) — send logs, spans and metrics to o (see package observe)
+//
+// A LiveView (AppendableView) is queried at the version current when Execute
+// starts; Result.DataVersion reports it. Use ExecuteContext to make the query
+// cancellable.
 func Execute(spec QuerySpec, view RecordView, opts ...Option) (*Result, error) {
-	cfg := applyOptions(opts)
+	return ExecuteContext(context.Background(), spec, view, opts...)
+}
+
+func execute(ctx context.Context, spec QuerySpec, view RecordView, cfg *config) (*Result, error) {
+	if err := cfg.Calendar.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid calendar: %w", err)
+	}
+	if err := validateSortKeys(spec.SortKeys); err != nil {
+		return nil, fmt.Errorf("invalid sortKeys: %w", err)
+	}
 
 	// Resolve which measure to aggregate
 	measure := spec.Measure
@@ -42,7 +68,13 @@ func Execute(spec QuerySpec, view RecordView, opts ...Option) (*Result, error) {
 		measure = "amount" // last-resort default
 	}
 
+	tr := cfg.trace
+	if tr != nil {
+		tr.Spec, tr.Measure, tr.Records = normalizeSpec(spec), measure, view.Len()
+	}
+
 	if view.Len() == 0 {
+		tr.path("empty")
 		return &Result{
 			Success: true,
 			Type:    "text",
@@ -50,57 +82,105 @@ func Execute(spec QuerySpec, view RecordView, opts ...Option) (*Result, error) {
 		}, nil
 	}
 
-	log.Printf("🔧 Spektr: Processing %d records, intent=%s, visualize=%s, aggregation=%s, measure=%s",
-		view.Len(), spec.Intent, spec.Visualize, spec.Aggregation, measure)
+	cfg.log(observe.LevelInfo, "processing records", observe.Int("records", view.Len()),
+		observe.String("intent", spec.Intent), observe.String("visualize", spec.Visualize),
+		observe.String("aggregation", spec.Aggregation), observe.String("measure", measure))
+
+	// ── ROLLUP CUBE (early return) — falls through when incompatible ──────
+	if result, ok := executeCube(spec, view, measure, cfg); ok {
+		return result, nil
+	}
+
+	// ── RAW RECORDS — limited, and cancellable on every row read ──────────
+	if err := cfg.Limits.checkRecords(view.Len()); err != nil {
+		return nil, err
+	}
+	view, stop := withContext(ctx, view)
+	defer stop()
+
+	// Derived year/quarter/month/week buckets follow the configured calendar
+	view = NewCalendarView(view, cfg.Calendar)
+	// Multi-value dimensions ("backend;api") are split into sets on read
+	view = NewTagView(view, cfg.MultiValue)
+
+	// ── RELATIVE TIME RANGE → concrete temporal filters ───────────────────
+	if spec.TimeRange != nil && !spec.TimeRange.IsZero() {
+		st := cfg.stage("time range")
+		resolved, err := applyTimeRange(spec, view, cfg)
+		st.end()
+		if err != nil {
+			return nil, err
+		}
+		spec = resolved
+		if r := spec.resolvedRange(); r != nil {
+			cfg.log(observe.LevelInfo, "time range resolved", observe.String("range", r.Label))
+		}
+		if tr != nil {
+			tr.Spec = normalizeSpec(spec)
+		}
+	}
 
 	// ── RATIO AGGREGATION (early return) ──────────────────────────────────
 	if spec.Aggregation == "ratio" && spec.CompareFilters != nil {
+		tr.path("ratio")
 		return executeRatio(spec, view, measure, cfg)
 	}
 
 	// ── MULTI-MEASURE COMPARISON CHART (early return) ──────────────────────
 	if spec.Intent == "chart" && len(spec.Measures) > 1 {
+		tr.path("multi-measure")
 		return executeMultiMeasure(spec, view, cfg)
 	}
 
 	// 1. Apply filters → SubView (zero-copy)
-	filtered := ApplyFilters(view, spec.Filters)
+	tr.path("standard")
+	st := cfg.stage("filter")
+	filtered := applyFilters(view, spec.Filters, cfg.Parallelism, cfg.blankLabel())
+	st.end()
+	tr.filters("", view, spec.Filters, cfg)
+	tr.matched(filtered.Len())
 
 	if filtered.Len() == 0 {
 		return &Result{
-			Success: true,
-			Type:    "text",
-			Reply:   "No records match your query filters. Try broadening your search.",
+			Success:   true,
+			Type:      "text",
+			Reply:     "No records match your query filters. Try broadening your search.",
+			TimeRange: spec.resolvedRange(),
 		}, nil
 	}
 
-	log.Printf("🔧 Spektr: %d records after filtering (from %d)", filtered.Len(), view.Len())
+	cfg.log(observe.LevelInfo, "records filtered", observe.Int("matched", filtered.Len()), observe.Int("records", view.Len()))
+	cfg.metric("spektr.execute.matched", float64(filtered.Len()))
 
 	// 2. Currency normalization — wrap in CurrencyView (zero-copy)
-	displayUnit := cfg.BaseCurrency
-	needsConversion := false
-	if cfg.BaseCurrency != "" && cfg.CurrencyDimension != "" && len(cfg.ExchangeRates) > 0 {
-		displayUnit, needsConversion = detectDisplayCurrency(filtered, cfg.CurrencyDimension, cfg.BaseCurrency)
-		if needsConversion {
-			log.Printf("💱 Spektr: Multi-currency detected, normalizing to %s", cfg.BaseCurrency)
-			filtered = newCurrencyView(filtered, measure, cfg.CurrencyDimension, cfg.BaseCurrency, cfg.ExchangeRates)
-			displayUnit = cfg.BaseCurrency
-		}
-	}
-	if displayUnit == "" {
-		displayUnit = inferUnit(filtered, cfg.CurrencyDimension)
-	}
-
-	// 3. Group and aggregate
-	groups := GroupAndAggregate(filtered, spec.GroupBy, measure, spec.Aggregation, spec.SortBy, spec.Limit)
+	st = cfg.stage("currency")
+	plan := cfg.planCurrency(filtered, []string{measure})
+	unconverted := filtered
+	filtered = plan.apply(filtered)
+	displayUnit, needsConversion := plan.unit, plan.converted
+	rateDates := plan.rateDates(unconverted)
+	tr.currency(unconverted, cfg, needsConversion)
+	st.end()
+
+	// 3. Group and aggregate — blank group-by values labelled or excluded
+	st = cfg.stage("group")
+	filtered = applyBlankPolicy(filtered, spec.GroupBy, cfg)
+	sorter := newGroupSorter(spec, cfg.ValueOrders)
+	groups := groupAndAggregate(filtered, spec.GroupBy, measure, spec.Aggregation, sorter, spec.Limit, cfg)
+	st.end()
+	defer cfg.stage("build").end()
 
 	// 4. Dispatch to builder
 	result := &Result{
 		Success:       true,
 		DisplayUnit:   displayUnit,
 		ShouldConvert: needsConversion,
+		RateDates:     rateDates,
+		TimeRange:     spec.resolvedRange(),
+		Warnings:      overlapWarnings(spec, filtered),
 	}
 
+	f := cfg.valueFormat(measure, displayUnit)
 	switch spec.Intent {
 	case "chart":
 		result.Type = "chart"
@@ -110,14 +190,15 @@ func Execute(spec QuerySpec, view RecordView, opts ...Option) (*Result, error) {
 			result.Reply = "Not enough data to generate a chart."
 			return result, nil
 		}
+		f.decorateChart(result.ChartConfig, spec.Aggregation)
 
 	case "table":
 		result.Type = "table"
-		result.TableData = BuildTable(spec, groups, filtered, measure, displayUnit)
+		result.TableData = buildTable(spec, groups, filtered, measure, f)
 
 	case "text":
 		result.Type = "text"
-		result.Data = BuildText(spec, groups, filtered, measure, displayUnit)
+		result.Data = buildText(spec, filtered, measure, f)
 		// Growth with insufficient data override
 		if spec.Aggregation == "growth" {
 			if textData, ok := result.Data.(*TextData); ok && textData.Growth != nil && textData.Growth.Direction == "insufficient data" {
@@ -129,11 +210,16 @@ func Execute(spec QuerySpec, view RecordView, opts ...Option) (*Result, error) {
 
 	default:
 		result.Type = "text"
-		result.Data = BuildText(spec, groups, filtered, measure, displayUnit)
+		result.Data = buildText(spec, filtered, measure, f)
 	}
 
 	// 5. Resolve reply template placeholders
-	result.Reply = ResolvePlaceholders(spec.Reply, groups, filtered, measure, displayUnit)
+	// A resolved time range names the period, even where the data is sparser.
+	reply := spec.Reply
+	if r := spec.resolvedRange(); r != nil {
+		reply = strings.ReplaceAll(reply, "{period}", f.period(r.Label))
+	}
+	result.Reply = resolvePlaceholders(reply, groups, filtered, measure, f)
 
 	return result, nil
 }
@@ -143,18 +229,35 @@ func Execute(spec QuerySpec, view RecordView, opts ...Option) (*Result, error) {
 // ============================================================================
 
 func executeMultiMeasure(spec QuerySpec, view RecordView, cfg *config) (*Result, error) {
-	filtered := ApplyFilters(view, spec.Filters)
+	tr := cfg.trace
+	st := cfg.stage("filter")
+	filtered := applyFilters(view, spec.Filters, cfg.Parallelism, cfg.blankLabel())
+	st.end()
+	tr.filters("", view, spec.Filters, cfg)
+	tr.matched(filtered.Len())
 	if filtered.Len() == 0 {
 		return &Result{
-			Success: true,
-			Type:    "text",
-			Reply:   "No records match your query filters. Try broadening your search.",
+			Success:   true,
+			Type:      "text",
+			Reply:     "No records match your query filters. Try broadening your search.",
+			TimeRange: spec.resolvedRange(),
 		}, nil
 	}
 
-	log.Printf("📊 Spektr: Multi-measure chart — measures=%v, groupBy=%v", spec.Measures, spec.GroupBy)
+	cfg.log(observe.LevelInfo, "multi-measure chart", observe.Strings("measures", spec.Measures), observe.Strings("groupBy", spec.GroupBy))
+
+	st = cfg.stage("currency")
+	plan := cfg.planCurrency(filtered, spec.Measures)
+	unconverted := filtered
+	filtered = plan.apply(filtered)
+	tr.currency(unconverted, cfg, plan.converted)
+	st.end()
 
-	chartConfig := BuildMultiMeasureChart(spec, filtered, spec.Measures)
+	st = cfg.stage("group")
+	filtered = applyBlankPolicy(filtered, spec.GroupBy, cfg)
+
+	chartConfig := buildMultiMeasureChart(spec, filtered, spec.Measures, newGroupSorter(spec, cfg.ValueOrders), cfg)
+	st.end()
 	if chartConfig == nil {
 		return &Result{
 			Success: true,
@@ -163,16 +266,23 @@ func executeMultiMeasure(spec QuerySpec, view RecordView, cfg *config) (*Result,
 		}, nil
 	}
 
+	cfg.valueFormat("", plan.unit).decorateChart(chartConfig, spec.Aggregation)
+
 	measureLabels := make([]string, len(spec.Measures))
 	for i, m := range spec.Measures {
 		measureLabels[i] = LabelForDimension(m)
 	}
 
 	return &Result{
-		Success:     true,
-		Type:        "chart",
-		ChartConfig: chartConfig,
-		Reply:       fmt.Sprintf("Comparing %s.", strings.Join(measureLabels, " vs ")),
+		Success:       true,
+		Type:          "chart",
+		ChartConfig:   chartConfig,
+		Reply:         fmt.Sprintf("Comparing %s.", strings.Join(measureLabels, " vs ")),
+		DisplayUnit:   plan.unit,
+		ShouldConvert: plan.converted,
+		RateDates:     plan.rateDates(unconverted),
+		TimeRange:     spec.resolvedRange(),
+		Warnings:      overlapWarnings(spec, filtered),
 	}, nil
 }
 
@@ -181,30 +291,44 @@ func executeMultiMeasure(spec QuerySpec, view RecordView, cfg *config) (*Result,
 // ============================================================================
 
 func executeRatio(spec QuerySpec, view RecordView, measure string, cfg *config) (*Result, error) {
-	denominator := ApplyFilters(view, spec.Filters)
-	numerator := ApplyFilters(view, *spec.CompareFilters)
+	tr := cfg.trace
+	st := cfg.stage("filter")
+	denominator := applyFilters(view, spec.Filters, cfg.Parallelism, cfg.blankLabel())
+	numerator := applyFilters(view, *spec.CompareFilters, cfg.Parallelism, cfg.blankLabel())
+	st.end()
+	tr.filters("", view, spec.Filters, cfg)
+	tr.filters("compareFilters", view, *spec.CompareFilters, cfg)
+	tr.matched(denominator.Len())
+
+	// Both sides convert at the same plan, so the percentage compares like
+	// with like even when only one side mixes currencies
+	st = cfg.stage("currency")
+	combined := newConcatView(denominator, numerator)
+	plan := cfg.planCurrency(combined, []string{measure})
+	tr.currency(combined, cfg, plan.converted)
+	rateDates := plan.rateDates(combined)
+	denominator, numerator = plan.apply(denominator), plan.apply(numerator)
+	st.end()
 
-	denomSum := SumMeasure(denominator, measure)
-	numSum := SumMeasure(numerator, measure)
+	st = cfg.stage("aggregate")
+	denomSum := sumMeasure(denominator, measure, cfg.Parallelism)
+	numSum := sumMeasure(numerator, measure, cfg.Parallelism)
+	st.end()
 
 	var pct float64
 	if denomSum > 0 {
 		pct = (numSum / denomSum) * 100
 	}
 
-	// Detect display unit
-	unit := cfg.BaseCurrency
-	if unit == "" {
-		unit = inferUnit(denominator, cfg.CurrencyDimension)
-	}
+	unit := plan.unit
+	f := cfg.valueFormat(measure, unit)
 
 	numLabel := buildFilterLabel(spec.CompareFilters)
 	denomLabel := buildFilterLabel(&spec.Filters)
 
-	displayValue := fmt.Sprintf("%.1f%%", pct)
+	displayValue := f.percent(pct)
 	// ConcatView for period derivation — no data copy
-	combined := newConcatView(denominator, numerator)
-	period := DerivePeriod(combined)
+	period := f.period(DerivePeriod(combined, spec.resolvedRange()))
 
 	textData := &TextData{
 		Value:    displayValue,
@@ -225,18 +349,19 @@ func executeRatio(spec QuerySpec, view RecordView, measure string, cfg *config)
 	reply := spec.Reply
 	replacements := map[string]string{
 		"{ratio_percent}":     displayValue,
-		"{numerator_total}":   FormatCurrency(numSum, unit),
-		"{denominator_total}": FormatCurrency(denomSum, unit),
+		"{numerator_total}":   f.value(numSum),
+		"{denominator_total}": f.value(denomSum),
 		"{numerator_label}":   numLabel,
 		"{denominator_label}": denomLabel,
 		"{period}":            period,
-		"{total}":             FormatCurrency(numSum, unit),
+		"{total}":             f.value(numSum),
 	}
 	for k, v := range replacements {
 		reply = strings.ReplaceAll(reply, k, v)
 	}
 
-	log.Printf("📊 Spektr: Ratio — %s / %s = %.1f%%", numLabel, denomLabel, pct)
+	cfg.log(observe.LevelInfo, "ratio", observe.String("numerator", numLabel),
+		observe.String("denominator", denomLabel), observe.Float64("percent", pct))
 
 	return &Result{
 		Success:       true,
@@ -244,7 +369,9 @@ func executeRatio(spec QuerySpec, view RecordView, measure string, cfg *config)
 		Reply:         reply,
 		Data:          textData,
 		DisplayUnit:   unit,
-		ShouldConvert: false,
+		ShouldConvert: plan.converted,
+		RateDates:     rateDates,
+		TimeRange:     spec.resolvedRange(),
 	}, nil
 }
 
@@ -254,19 +381,43 @@ func executeRatio(spec QuerySpec, view RecordView, measure string, cfg *config)
 
 // ResolvePlaceholders substitutes computed values into the reply template.
 func ResolvePlaceholders(template string, groups []Group, view RecordView, measure string, unit string) string {
+	return resolvePlaceholders(template, groups, view, measure, classicFormat(unit))
+}
+
+func resolvePlaceholders(template string, groups []Group, view RecordView, measure string, f valueFormat) string {
 	if template == "" {
-		return buildDefaultReply(view, measure, unit)
+		return buildDefaultReply(view, measure, f)
 	}
+	stats := replyStats{
+		total:  SumMeasure(view, measure),
+		count:  view.Len(),
+		period: DerivePeriod(view),
+		growth: buildGrowthText(view, measure, f, "month").Growth,
+	}
+	if stats.count > 0 {
+		stats.avg = AvgMeasure(view, measure)
+		stats.max = MaxMeasure(view, measure)
+		stats.min = MinMeasure(view, measure)
+	}
+	return resolveReply(template, groups, stats, f)
+}
 
-	total := SumMeasure(view, measure)
-	count := view.Len()
-	period := DerivePeriod(view)
+// replyStats are the view-wide values reply placeholders draw on.
+type replyStats struct {
+	total, avg, max, min float64
+	count                int
+	period               string
+	growth               *GrowthData
+}
 
+// resolveReply substitutes precomputed stats into a reply template.
+func resolveReply(template string, groups []Group, stats replyStats, f valueFormat) string {
+	count := stats.count
 	replacements := map[string]string{
-		"{total}":    FormatCurrency(total, unit),
+		"{total}":    f.value(stats.total),
 		"{count}":    fmt.Sprintf("%d", count),
-		"{period}":   period,
-		"{currency}": unit,
+		"{period}":   f.period(stats.period),
+		"{currency}": f.unit,
 	}
 
 	// Top group (highest value)
@@ -277,31 +428,30 @@ func ResolvePlaceholders(template string, groups []Group, view RecordView, measu
 				topGroup = g
 			}
 		}
-		replacements["{top_category}"] = topGroup.Label
-		replacements["{top_amount}"] = FormatCurrency(topGroup.Value, unit)
+		replacements["{top_category}"] = f.groupLabel(topGroup.Label)
+		replacements["{top_amount}"] = f.value(topGroup.Value)
 	}
 
-	// Average
+	// Average (missing values skipped)
 	if count > 0 {
-		replacements["{avg}"] = FormatCurrency(total/float64(count), unit)
+		replacements["{avg}"] = f.value(stats.avg)
 	}
 
 	// Max and Min
 	if count > 0 {
-		replacements["{max}"] = FormatCurrency(MaxMeasure(view, measure), unit)
-		replacements["{min}"] = FormatCurrency(MinMeasure(view, measure), unit)
+		replacements["{max}"] = f.value(stats.max)
+		replacements["{min}"] = f.value(stats.min)
 	}
 
 	// Growth placeholders
-	growthData := BuildGrowthText(view, measure, unit)
-	if growthData.Growth != nil {
-		g := growthData.Growth
-		replacements["{growth_percent}"] = fmt.Sprintf("%.1f%%", g.ChangePercent)
-		replacements["{change_amount}"] = FormatCurrency(g.ChangeAmount, unit)
-		replacements["{earliest_value}"] = FormatCurrency(g.EarliestValue, unit)
-		replacements["{latest_value}"] = FormatCurrency(g.LatestValue, unit)
-		replacements["{earliest_period}"] = g.EarliestPeriod
-		replacements["{latest_period}"] = g.LatestPeriod
+	if stats.growth != nil {
+		g := stats.growth
+		replacements["{growth_percent}"] = f.percent(g.ChangePercent)
+		replacements["{change_amount}"] = f.value(g.ChangeAmount)
+		replacements["{earliest_value}"] = f.value(g.EarliestValue)
+		replacements["{latest_value}"] = f.value(g.LatestValue)
+		replacements["{earliest_period}"] = f.period(g.EarliestPeriod)
+		replacements["{latest_period}"] = f.period(g.LatestPeriod)
 		replacements["{direction}"] = g.Direction
 	}
 
@@ -345,8 +495,9 @@ func NormalizeQuerySpec(spec QuerySpec) QuerySpec {
 	}
 
 	if changed {
-		log.Printf("🔧 NormalizeQuerySpec: Adjusted → intent=%s, groupBy=%v, aggregation=%s",
-			spec.Intent, spec.GroupBy, spec.Aggregation)
+		observe.Default().Log(context.Background(), observe.LevelInfo, "query spec adjusted",
+			observe.String("intent", spec.Intent), observe.Strings("groupBy", spec.GroupBy),
+			observe.String("aggregation", spec.Aggregation))
 	}
 
 	return spec
@@ -356,6 +507,61 @@ func NormalizeQuerySpec(spec QuerySpec) QuerySpec {
 // CURRENCY HELPERS
 // ============================================================================
 
+// currencyPlan is how one execution normalizes currency: the display unit,
+// and whether (and which) measures are converted to the base currency.
+type currencyPlan struct {
+	unit      string
+	converted bool
+	measures  []string // currency measures among those queried
+	rates     *currencyRates
+}
+
+// planCurrency decides currency handling for measures over view. Only
+// currency measures (WithCurrencyMeasures; all measures when unset) carry a
+// currency unit or are converted, and only when view mixes currencies. A
+// single measure declared in another unit displays in that unit.
+func (c *config) planCurrency(view RecordView, measures []string) currencyPlan {
+	var p currencyPlan
+	for _, m := range measures {
+		if c.isCurrencyMeasure(m) {
+			p.measures = append(p.measures, m)
+		}
+	}
+	if len(p.measures) == 0 {
+		if u, ok := c.measureUnit(measures[0]); ok && len(measures) == 1 {
+			p.unit = u.Name
+		}
+		return p
+	}
+	p.unit = c.BaseCurrency
+	if p.rates = c.currencyRates(); p.rates != nil {
+		p.unit, p.converted = detectDisplayCurrency(view, c.CurrencyDimension, c.BaseCurrency)
+		if p.converted {
+			c.log(observe.LevelInfo, "multi-currency detected, normalizing", observe.String("base", c.BaseCurrency))
+		}
+	}
+	if p.unit == "" {
+		p.unit = inferUnit(view, c.CurrencyDimension)
+	}
+	return p
+}
+
+// apply wraps view in a CurrencyView when the plan converts.
+func (p currencyPlan) apply(view RecordView) RecordView {
+	if !p.converted {
+		return view
+	}
+	return newCurrencyView(view, p.measures, p.rates)
+}
+
+// rateDates lists the dated rates the plan applies to view's records.
+func (p currencyPlan) rateDates(view RecordView) map[string][]string {
+	if !p.converted {
+		return nil
+	}
+	return p.rates.datesUsed(view)
+}
+
 // detectDisplayCurrency checks if records span multiple currencies.
 func detectDisplayCurrency(view RecordView, currencyDimension string, baseCurrency string) (string, bool) {
 	if view.Len() == 0 {
@@ -391,12 +597,12 @@ func inferUnit(view RecordView, currencyDimension string) string {
 // INTERNAL HELPERS
 // ============================================================================
 
-func buildDefaultReply(view RecordView, measure string, unit string) string {
+func buildDefaultReply(view RecordView, measure string, f valueFormat) string {
 	if view.Len() == 0 {
 		return "No matching records found."
 	}
 	return fmt.Sprintf("Found %d records totalling %s.",
-		view.Len(), FormatCurrency(SumMeasure(view, measure), unit))
+		view.Len(), f.value(SumMeasure(view, measure)))
 }
 
 // buildFilterLabel creates a human-readable label from Filters.
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"sort"
+	"time"
+)
+
+// ============================================================================
+// EXPLAIN — structured execution trace attached to a Result
+// ============================================================================
+// When a result looks wrong, ask the engine how it got there:
+//
+//   result, _ := engine.Execute(spec, view, engine.WithExplain())
+//   result.Trace.Path      // "standard", "cube", "ratio", "multi-measure", "empty"
+//   result.Trace.Filters   // rows before / after each filtered dimension
+//   result.Trace.Stages    // per-stage timings
+//
+// The per-dimension filter counts are computed by re-applying the filters
+// one dimension at a time, so explain mode costs an extra filter pass.
+// Results are otherwise identical to a run without WithExplain.
+// ============================================================================
+
+// Trace describes how Execute produced a Result.
+type Trace struct {
+	Spec     QuerySpec      `json:"spec"`               // normalized; a time range appears as resolved filter values
+	Path     string         `json:"path"`               // "empty", "cube", "ratio", "multi-measure" or "standard"
+	Measure  string         `json:"measure"`            // measure actually aggregated
+	Records  int            `json:"records"`            // rows in the queried view
+	Filters  []FilterTrace  `json:"filters,omitempty"`  // in the order applied
+	Matched  int            `json:"matched"`            // rows (or cube records) left after filtering
+	Currency *CurrencyTrace `json:"currency,omitempty"` // nil when currency normalization is not configured
+	Groups   int            `json:"groups"`             // groups and sub-groups before QuerySpec.Limit
+	Returned int            `json:"returned"`           // top-level groups after QuerySpec.Limit
+	Stages   []StageTrace   `json:"stages"`
+	TotalMs  float64        `json:"totalMs"`
+}
+
+// FilterTrace is one filtered dimension and its effect on the row count.
+type FilterTrace struct {
+	Set       string   `json:"set,omitempty"` // "compareFilters" for a ratio's numerator
+	Dimension string   `json:"dimension"`
+	Values    []string `json:"values"`
+	Indexed   bool     `json:"indexed,omitempty"` // answered from a filter index
+	Before    int      `json:"before"`
+	After     int      `json:"after"`
+}
+
+// CurrencyTrace reports currency normalization.
+type CurrencyTrace struct {
+	Base        string              `json:"base"`
+	Dimension   string              `json:"dimension"`
+	Normalized  bool                `json:"normalized"`
+	Rates       map[string]float64  `json:"rates,omitempty"`       // rates applied to the matched records
+	Unconverted []string            `json:"unconverted,omitempty"` // currencies present without a rate
+	RateDates   map[string][]string `json:"rateDates,omitempty"`   // dated rates applied, per currency
+}
+
+// StageTrace is the wall time of one pipeline stage.
+type StageTrace struct {
+	Stage string  `json:"stage"`
+	Ms    float64 `json:"ms"`
+}
+
+// WithExplain attaches a Trace to the Result (Result.Trace).
+func WithExplain() Option {
+	return func(c *config) {
+		c.Explain = true
+	}
+}
+
+// The methods below are no-ops on a nil *Trace, so the executor can call
+// them unconditionally.
+
+func (t *Trace) path(path string) {
+	if t != nil {
+		t.Path = path
+	}
+}
+
+func (t *Trace) matched(n int) {
+	if t != nil {
+		t.Matched = n
+	}
+}
+
+func (t *Trace) returned(groups []Group) {
+	if t != nil {
+		t.Returned = len(groups)
+	}
+}
+
+// filters records each dimension of f applied cumulatively to view, in
+// sorted dimension order.
+func (t *Trace) filters(set string, view RecordView, f Filters, cfg *config) {
+	if t == nil {
+		return
+	}
+	dims := make([]string, 0, len(f.Dimensions))
+	for dim, vals := range f.Dimensions {
+		if len(vals) > 0 {
+			dims = append(dims, dim)
+		}
+	}
+	sort.Strings(dims)
+
+	current := view
+	for _, dim := range dims {
+		_, indexed := filterIndex(view, dim)
+		step := FilterTrace{Set: set, Dimension: dim, Values: f.Dimensions[dim], Indexed: indexed, Before: current.Len()}
+		current = applyFilters(current, Filters{Dimensions: map[string][]string{dim: f.Dimensions[dim]}}, 0, cfg.blankLabel())
+		step.After = current.Len()
+		t.Filters = append(t.Filters, step)
+	}
+}
+
+// currency records the rates that apply to the currencies present in view.
+func (t *Trace) currency(view RecordView, cfg *config, normalized bool) {
+	rates := cfg.currencyRates()
+	if t == nil || rates == nil {
+		return
+	}
+	ct := &CurrencyTrace{Base: cfg.BaseCurrency, Dimension: cfg.CurrencyDimension, Normalized: normalized}
+	seen := make(map[string]bool)
+	for i := 0; i < view.Len(); i++ {
+		seen[view.Dimension(i, cfg.CurrencyDimension)] = true
+	}
+	for c := range seen {
+		if c == "" || c == cfg.BaseCurrency {
+			continue
+		}
+		if !rates.knows(c) {
+			ct.Unconverted = append(ct.Unconverted, c)
+			continue
+		}
+		if rate, ok := cfg.ExchangeRates[c]; ok && rate > 0 && normalized {
+			if ct.Rates == nil {
+				ct.Rates = make(map[string]float64)
+			}
+			ct.Rates[c] = rate
+		}
+	}
+	sort.Strings(ct.Unconverted)
+	if normalized {
+		ct.RateDates = rates.datesUsed(view)
+	}
+	t.Currency = ct
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
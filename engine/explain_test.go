@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ============================================================================
+// EXPLAIN TESTS
+// ============================================================================
+
+func explainRecords() []Record {
+	return records([]string{"team", "status", "currency"}, []string{"amount"},
+		row{"Core", "Open", "SGD", 100},
+		row{"Core", "Closed", "USD", 50},
+		row{"Web", "Open", "INR", 1000},
+		row{"Web", "Open", "EUR", 20},
+		row{"Data", "Closed", "SGD", 70},
+		row{"Data", "Open", "SGD", 30},
+	)
+}
+
+func stageNames(tr *Trace) []string {
+	names := make([]string, len(tr.Stages))
+	for i, s := range tr.Stages {
+		names[i] = s.Stage
+	}
+	return names
+}
+
+func TestExplainStandardPath(t *testing.T) {
+	view := NewSliceView(explainRecords())
+	spec := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "amount", GroupBy: []string{"team"}, Visualize: "bar", Limit: 1,
+		Filters: Filters{Dimensions: map[string][]string{"status": {"open"}, "team": {"Core", "Web"}}}}
+	rates := WithCurrency("SGD", "currency", map[string]float64{"USD": 1.35, "INR": 0.016})
+
+	want, _ := Execute(spec, view, rates)
+	got, err := Execute(spec, view, rates, WithExplain())
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	tr := got.Trace
+	if tr == nil {
+		t.Fatal("no trace attached")
+	}
+	got.Trace = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Error("explain changed the result")
+	}
+
+	if tr.Path != "standard" || tr.Measure != "amount" || tr.Records != 6 || tr.Matched != 3 {
+		t.Errorf("trace = %+v", tr)
+	}
+	wantFilters := []FilterTrace{
+		{Dimension: "status", Values: []string{"open"}, Before: 6, After: 4},
+		{Dimension: "team", Values: []string{"Core", "Web"}, Before: 4, After: 3},
+	}
+	if !reflect.DeepEqual(tr.Filters, wantFilters) {
+		t.Errorf("filters = %+v", tr.Filters)
+	}
+	wantCurrency := &CurrencyTrace{Base: "SGD", Dimension: "currency", Normalized: true,
+		Rates: map[string]float64{"INR": 0.016}, Unconverted: []string{"EUR"}}
+	if !reflect.DeepEqual(tr.Currency, wantCurrency) {
+		t.Errorf("currency = %+v", tr.Currency)
+	}
+	if tr.Groups != 2 || tr.Returned != 1 {
+		t.Errorf("groups = %d, returned = %d; want 2, 1", tr.Groups, tr.Returned)
+	}
+	if names := stageNames(tr); !reflect.DeepEqual(names, []string{"filter", "currency", "group", "build"}) {
+		t.Errorf("stages = %v", names)
+	}
+	if tr.TotalMs < 0 {
+		t.Errorf("TotalMs = %v", tr.TotalMs)
+	}
+}
+
+func TestExplainPaths(t *testing.T) {
+	view := NewSliceView(explainRecords())
+	compare := Filters{Dimensions: map[string][]string{"status": {"closed"}}}
+	cube, err := BuildCube(view, CubeSpec{Dimensions: []string{"team", "status"}, Measures: []string{"amount"}, Aggregations: []string{"sum"}})
+	if err != nil {
+		t.Fatalf("BuildCube failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		spec QuerySpec
+		view RecordView
+		opts []Option
+		path string
+	}{
+		{"empty", QuerySpec{Intent: "text", Aggregation: "sum"}, NewSliceView(nil), nil, "empty"},
+		{"ratio", QuerySpec{Intent: "text", Aggregation: "ratio", Measure: "amount", CompareFilters: &compare}, view, nil, "ratio"},
+		{"multi-measure", QuerySpec{Intent: "chart", Aggregation: "sum", Measures: []string{"amount", "amount"}, GroupBy: []string{"team"}}, view, nil, "multi-measure"},
+		{"cube", QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "amount", GroupBy: []string{"status"}, Visualize: "bar"}, view, []Option{WithCube(cube)}, "cube"},
+	}
+	for _, tt := range tests {
+		result, err := Execute(tt.spec, tt.view, append(tt.opts, WithExplain())...)
+		if err != nil {
+			t.Fatalf("%s: Execute failed: %v", tt.name, err)
+		}
+		if result.Trace == nil || result.Trace.Path != tt.path {
+			t.Errorf("%s: trace = %+v", tt.name, result.Trace)
+		}
+	}
+
+	ratio, _ := Execute(tests[1].spec, view, WithExplain())
+	if f := ratio.Trace.Filters; len(f) != 1 || f[0].Set != "compareFilters" || f[0].After != 2 {
+		t.Errorf("ratio filters = %+v", f)
+	}
+	cubed, _ := Execute(tests[3].spec, view, WithCube(cube), WithExplain())
+	if tr := cubed.Trace; tr.Matched != 6 || tr.Groups != 2 || tr.Stages[0].Stage != "cube" {
+		t.Errorf("cube trace = %+v", tr)
+	}
+}
+
+func TestExplainBypassesCache(t *testing.T) {
+	view := NewSliceView(explainRecords())
+	cache := NewResultCache(CacheOptions{})
+	spec := QuerySpec{Intent: "text", Aggregation: "sum", Measure: "amount"}
+
+	cache.Execute(spec, view)
+	explained, _ := cache.Execute(spec, view, WithExplain())
+	if explained.Trace == nil {
+		t.Error("explain run returned a cached result without a trace")
+	}
+	if s := cache.Stats(); s.Hits != 0 || s.Misses != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+}
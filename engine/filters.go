@@ -15,15 +15,28 @@ import (
 // Dimensions are AND-combined; values within a dimension are OR-combined.
 // Empty filter = no restriction (returns original view).
 func ApplyFilters(view RecordView, filters Filters) RecordView {
+	return applyFilters(view, filters, 0, DefaultBlankLabel)
+}
+
+// applyFilters is ApplyFilters sharded over workers (see parallel.go). A
+// filter value equal to blankLabel (case-insensitive) selects empty values,
+// so groups labelled with WithBlankLabel can be drilled into.
+func applyFilters(view RecordView, filters Filters, workers int, blankLabel string) RecordView {
 	if filters.IsEmpty() {
 		return view
 	}
 
-	// Pre-build lowercase lookup sets for each dimension filter
+	// Pre-build lowercase lookup sets for each dimension filter; the blank
+	// label selects the empty value
+	blank := strings.ToLower(blankLabel)
 	sets := make(map[string]map[string]bool)
 	for dim, allowed := range filters.Dimensions {
 		if len(allowed) > 0 {
-			sets[dim] = toLowerSet(allowed)
+			set := toLowerSet(allowed)
+			if set[blank] {
+				set[""] = true
+			}
+			sets[dim] = set
 		}
 	}
 
@@ -31,26 +44,110 @@ func ApplyFilters(view RecordView, filters Filters) RecordView {
 		return view
 	}
 
+	// Indexed dimensions are answered with bitmap OR (within) / AND (across);
+	// only the remaining dimensions need a row scan
+	var candidates Bitmap
+	for dim, set := range sets {
+		idx, ok := filterIndex(view, dim)
+		if !ok {
+			continue
+		}
+		b := idx.Match(set)
+		if candidates == nil {
+			candidates = b
+		} else {
+			candidates.And(b)
+		}
+		delete(sets, dim)
+	}
+	if candidates != nil && len(sets) == 0 {
+		return newSubView(view, candidates.Indices())
+	}
+
+	// Multi-value dimensions match if ANY of their values is selected
+	multi := make(map[string]bool)
+	for dim := range sets {
+		if IsMultiValue(view, dim) {
+			multi[dim] = true
+		}
+	}
+
+	// Dictionary-encoded dimensions are matched once per distinct value;
+	// rows then only need a code lookup
+	coded := make(map[string][]bool)
+	var cv CodedView
+	for dim, set := range sets {
+		if multi[dim] {
+			continue
+		}
+		if v, dict, ok := dimensionDictionary(view, dim); ok {
+			cv = v
+			coded[dim] = allowedCodes(dict, set)
+		}
+	}
+
 	// Single pass — record passes if it matches ALL dimension filters
-	n := view.Len()
-	indices := make([]int, 0, n)
-	for i := 0; i < n; i++ {
-		pass := true
+	match := func(i int) bool {
 		for dim, set := range sets {
-			val := strings.ToLower(view.Dimension(i, dim))
-			if !set[val] {
-				pass = false
-				break
+			if allowed, ok := coded[dim]; ok {
+				if !allowed[cv.DimensionCode(i, dim)] {
+					return false
+				}
+				continue
+			}
+			if multi[dim] {
+				if !matchesAny(view, i, dim, set) {
+					return false
+				}
+				continue
+			}
+			if !matchesValue(view, i, dim, set) {
+				return false
 			}
 		}
-		if pass {
-			indices = append(indices, i)
+		return true
+	}
+	var indices []int
+	if candidates != nil {
+		indices = candidates.Indices()
+		kept := indices[:0]
+		for _, i := range indices {
+			if match(i) {
+				kept = append(kept, i)
+			}
 		}
+		indices = kept
+	} else {
+		indices = filterIndices(view.Len(), workers, match)
 	}
 
 	return newSubView(view, indices)
 }
 
+// matchesValue reports whether a single-value dimension is in set.
+func matchesValue(view RecordView, i int, dim string, set map[string]bool) bool {
+	return set[strings.ToLower(view.Dimension(i, dim))]
+}
+
+// matchesAny reports whether any value of a multi-value dimension is in set.
+func matchesAny(view RecordView, i int, dim string, set map[string]bool) bool {
+	for _, val := range groupKeys(view, i, dim) {
+		if set[strings.ToLower(val)] {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedCodes marks which dictionary codes pass a lowercase filter set.
+func allowedCodes(dict []string, set map[string]bool) []bool {
+	allowed := make([]bool, len(dict))
+	for code, val := range dict {
+		allowed[code] = set[strings.ToLower(val)]
+	}
+	return allowed
+}
+
 // toLowerSet converts a string slice to a lowercase lookup set.
 func toLowerSet(items []string) map[string]bool {
 	set := make(map[string]bool, len(items))
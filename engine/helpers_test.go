@@ -0,0 +1,40 @@
+package engine
+
+import "fmt"
+
+// ============================================================================
+// TEST HELPERS — shared fixtures
+// ============================================================================
+
+// row is one fixture record: its dimension values, then its measure values.
+type row []interface{}
+
+// records builds one Record per row, naming the values by dims then
+// measures. Measure values may be written as int or float64 constants.
+func records(dims, measures []string, rows ...row) []Record {
+	out := make([]Record, len(rows))
+	for i, r := range rows {
+		if len(r) != len(dims)+len(measures) {
+			panic(fmt.Sprintf("fixture row %d has %d values, want %d", i, len(r), len(dims)+len(measures)))
+		}
+		rec := Record{
+			Dimensions: make(map[string]string, len(dims)),
+			Measures:   make(map[string]float64, len(measures)),
+		}
+		for j, key := range dims {
+			rec.Dimensions[key] = r[j].(string)
+		}
+		for j, key := range measures {
+			switch v := r[len(dims)+j].(type) {
+			case int:
+				rec.Measures[key] = float64(v)
+			case float64:
+				rec.Measures[key] = v
+			default:
+				panic(fmt.Sprintf("fixture row %d: measure %s is %T", i, key, v))
+			}
+		}
+		out[i] = rec
+	}
+	return out
+}
@@ -0,0 +1,253 @@
+package engine
+
+import (
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// BITMAP INDEXES — value → row sets for repeated filtering
+// ============================================================================
+// ApplyFilters normally scans every row and lowercases every value. When the
+// same dataset is queried many times (dashboards, the Sheets sidebar), wrap
+// it once in an IndexedView:
+//
+//   view := engine.NewIndexedView(engine.NewSliceView(records))
+//   engine.Execute(spec1, view)   // builds indexes for filtered dimensions
+//   engine.Execute(spec2, view)   // reuses them
+//
+// Each dimension is indexed on first use. A filter then ORs the row sets of
+// its selected values and ANDs across dimensions; only unindexed dimensions
+// still need a row scan.
+//
+// Invalidation: indexes are dropped when the parent's Len changes, or when a
+// VersionedView parent reports a new Version. Sources that mutate rows in
+// place without either signal must call Invalidate.
+// ============================================================================
+
+// Bitmap is a fixed-size set of row indices.
+type Bitmap []uint64
+
+// NewBitmap returns an empty bitmap for n rows.
+func NewBitmap(n int) Bitmap { return make(Bitmap, (n+63)/64) }
+
+func (b Bitmap) Set(i int)      { b[i>>6] |= 1 << (uint(i) & 63) }
+func (b Bitmap) Has(i int) bool { return b[i>>6]&(1<<(uint(i)&63)) != 0 }
+
+// And intersects b with o in place.
+func (b Bitmap) And(o Bitmap) {
+	for i := range b {
+		b[i] &= o[i]
+	}
+}
+
+// Or unions o into b in place.
+func (b Bitmap) Or(o Bitmap) {
+	for i := range b {
+		b[i] |= o[i]
+	}
+}
+
+// Count returns the number of rows in the set.
+func (b Bitmap) Count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Indices returns the rows in the set in ascending order.
+func (b Bitmap) Indices() []int {
+	indices := make([]int, 0, b.Count())
+	for wi, w := range b {
+		for w != 0 {
+			indices = append(indices, wi<<6+bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+	return indices
+}
+
+// VersionedView is implemented by views whose contents can change.
+// Version must change whenever rows are added, removed or modified.
+type VersionedView interface {
+	RecordView
+	Version() uint64
+}
+
+// IndexedFilterView is implemented by views that can answer filters from an
+// index. FilterIndex returns the index for a dimension, or false when that
+// dimension is not indexed (the filter then falls back to a scan).
+type IndexedFilterView interface {
+	RecordView
+	FilterIndex(key string) (*DimensionIndex, bool)
+}
+
+// filterIndex returns the filter index for key if view provides one.
+func filterIndex(view RecordView, key string) (*DimensionIndex, bool) {
+	if iv, ok := view.(IndexedFilterView); ok {
+		return iv.FilterIndex(key)
+	}
+	return nil, false
+}
+
+// ============================================================================
+// DIMENSION INDEX
+// ============================================================================
+
+// DimensionIndex maps the lowercased values of one dimension to their rows.
+// Blank values are stored under the empty string, whatever the blank label;
+// multi-value dimensions index each value separately.
+type DimensionIndex struct {
+	rows   int
+	values map[string]*rowSet
+}
+
+// rowSet stores dense values as a bitmap and sparse ones as a row list,
+// whichever is smaller — high-cardinality dimensions stay O(rows) in memory.
+type rowSet struct {
+	bitmap Bitmap
+	list   []uint32
+}
+
+func (s *rowSet) orInto(dst Bitmap) {
+	if s.bitmap != nil {
+		dst[:len(s.bitmap)].Or(s.bitmap) // may predate appended rows
+		return
+	}
+	for _, r := range s.list {
+		dst.Set(int(r))
+	}
+}
+
+func buildDimensionIndex(view RecordView, key string) *DimensionIndex {
+	n := view.Len()
+	lists := make(map[string][]uint32)
+	for i := 0; i < n; i++ {
+		for _, val := range groupKeys(view, i, key) {
+			val = strings.ToLower(val)
+			if l := lists[val]; len(l) == 0 || l[len(l)-1] != uint32(i) {
+				lists[val] = append(l, uint32(i))
+			}
+		}
+	}
+
+	idx := &DimensionIndex{rows: n, values: make(map[string]*rowSet, len(lists))}
+	words := (n + 63) / 64
+	for val, list := range lists {
+		if len(list)/2 > words { // 4-byte rows vs 8-byte words
+			b := NewBitmap(n)
+			for _, r := range list {
+				b.Set(int(r))
+			}
+			idx.values[val] = &rowSet{bitmap: b}
+		} else {
+			idx.values[val] = &rowSet{list: list}
+		}
+	}
+	return idx
+}
+
+// Match returns the rows whose value is in set (lowercased filter values).
+func (d *DimensionIndex) Match(set map[string]bool) Bitmap {
+	b := NewBitmap(d.rows)
+	for val := range set {
+		if s, ok := d.values[val]; ok {
+			s.orInto(b)
+		}
+	}
+	return b
+}
+
+// Cardinality returns the number of distinct indexed values.
+func (d *DimensionIndex) Cardinality() int { return len(d.values) }
+
+// ============================================================================
+// INDEXED VIEW — lazily indexes dimensions of any view
+// ============================================================================
+
+// IndexedView wraps a RecordView and maintains per-dimension filter indexes.
+// Safe for concurrent queries.
+type IndexedView struct {
+	parent  RecordView
+	only    map[string]bool // dimensions to index; nil = any
+	mu      sync.Mutex
+	indexes map[string]*DimensionIndex
+	rows    int    // parent Len when indexes were built
+	version uint64 // parent Version when indexes were built
+}
+
+// NewIndexedView wraps view with lazily built filter indexes. With no
+// dimensions every filtered dimension is indexed on first use; otherwise only
+// the listed ones are (leave out high-churn or rarely filtered dimensions).
+func NewIndexedView(view RecordView, dimensions ...string) *IndexedView {
+	v := &IndexedView{parent: view}
+	if len(dimensions) > 0 {
+		v.only = make(map[string]bool, len(dimensions))
+		for _, d := range dimensions {
+			v.only[d] = true
+		}
+	}
+	return v
+}
+
+// FilterIndex returns the index for key, building it on first use.
+func (v *IndexedView) FilterIndex(key string) (*DimensionIndex, bool) {
+	if v.only != nil && !v.only[key] {
+		return nil, false
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.checkFresh()
+	if idx, ok := v.indexes[key]; ok {
+		return idx, true
+	}
+	if v.indexes == nil {
+		v.indexes = make(map[string]*DimensionIndex)
+	}
+	idx := buildDimensionIndex(v.parent, key)
+	v.indexes[key] = idx
+	return idx, true
+}
+
+// checkFresh drops stale indexes. Caller holds mu.
+func (v *IndexedView) checkFresh() {
+	var version uint64
+	if vv, ok := v.parent.(VersionedView); ok {
+		version = vv.Version()
+	}
+	if v.parent.Len() != v.rows || version != v.version {
+		v.indexes = nil
+		v.rows, v.version = v.parent.Len(), version
+	}
+}
+
+// Invalidate drops all indexes; they are rebuilt on the next filter.
+func (v *IndexedView) Invalidate() {
+	v.mu.Lock()
+	v.indexes = nil
+	v.mu.Unlock()
+}
+
+func (v *IndexedView) Len() int                           { return v.parent.Len() }
+func (v *IndexedView) Dimension(i int, key string) string { return v.parent.Dimension(i, key) }
+func (v *IndexedView) Measure(i int, key string) float64  { return v.parent.Measure(i, key) }
+func (v *IndexedView) HasMeasure(i int, key string) bool  { return HasMeasure(v.parent, i, key) }
+func (v *IndexedView) DimensionKeys() []string            { return v.parent.DimensionKeys() }
+func (v *IndexedView) MeasureKeys() []string              { return v.parent.MeasureKeys() }
+
+func (v *IndexedView) DimensionValues(i int, key string) ([]string, bool) {
+	return DimensionValues(v.parent, i, key)
+}
+
+func (v *IndexedView) Dictionary(key string) ([]string, bool) {
+	_, dict, ok := dimensionDictionary(v.parent, key)
+	return dict, ok
+}
+
+func (v *IndexedView) DimensionCode(i int, key string) uint32 {
+	return v.parent.(CodedView).DimensionCode(i, key)
+}
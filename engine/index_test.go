@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ============================================================================
+// BITMAP INDEX TESTS
+// ============================================================================
+
+// versionedRecords is a mutable SliceView that reports a version.
+type versionedRecords struct {
+	*SliceView
+	version uint64
+}
+
+func (v *versionedRecords) Version() uint64 { return v.version }
+
+func TestIndexedFiltersMatchScan(t *testing.T) {
+	records := largeRecords()
+	plain := NewSliceView(records)
+	indexed := NewIndexedView(plain)
+
+	filters := []Filters{
+		{Dimensions: map[string][]string{"team": {"CORE", "web"}}},
+		{Dimensions: map[string][]string{"team": {"(blank)"}, "status": {"open"}}},
+		{Dimensions: map[string][]string{"team": {"nobody"}}},
+	}
+	for _, f := range filters {
+		want, got := ApplyFilters(plain, f), ApplyFilters(indexed, f)
+		if got.Len() != want.Len() {
+			t.Errorf("%v: indexed matched %d rows, want %d", f.Dimensions, got.Len(), want.Len())
+		}
+	}
+
+	// Through Execute's wrappers, with one dimension indexed and one scanned
+	spec := QuerySpec{
+		Intent: "chart", Aggregation: "sum", Measure: "cost", GroupBy: []string{"month"}, Visualize: "bar",
+		Filters: Filters{Dimensions: map[string][]string{"team": {"data"}, "month": {"feb-2026", "mar-2026"}}},
+	}
+	want, _ := Execute(spec, plain)
+	got, _ := Execute(spec, NewIndexedView(plain, "team"))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed Execute differs from scan")
+	}
+}
+
+func TestIndexedViewBuildsOncePerDimension(t *testing.T) {
+	view := NewIndexedView(NewSliceView(largeRecords()), "team")
+
+	first, ok := view.FilterIndex("team")
+	if !ok || first.Cardinality() != 5 {
+		t.Fatalf("team index = %v, want 5 values", first)
+	}
+	if again, _ := view.FilterIndex("team"); again != first {
+		t.Error("index should be reused between filters")
+	}
+	if _, ok := view.FilterIndex("status"); ok {
+		t.Error("status is not in the indexed dimension list")
+	}
+}
+
+func TestIndexedViewInvalidation(t *testing.T) {
+	records := []Record{
+		{Dimensions: map[string]string{"team": "Core"}},
+		{Dimensions: map[string]string{"team": "Web"}},
+	}
+	source := &versionedRecords{SliceView: NewSliceView(records).(*SliceView)}
+	view := NewIndexedView(source)
+	core := Filters{Dimensions: map[string][]string{"team": {"core"}}}
+
+	if got := ApplyFilters(view, core).Len(); got != 1 {
+		t.Fatalf("matched %d rows, want 1", got)
+	}
+
+	// In-place edit with a version bump
+	records[1].Dimensions["team"] = "Core"
+	source.version++
+	if got := ApplyFilters(view, core).Len(); got != 2 {
+		t.Errorf("after version bump matched %d rows, want 2", got)
+	}
+
+	// In-place edit without a signal needs Invalidate
+	records[0].Dimensions["team"] = "Web"
+	if got := ApplyFilters(view, core).Len(); got != 2 {
+		t.Errorf("stale index should still answer 2, got %d", got)
+	}
+	view.Invalidate()
+	if got := ApplyFilters(view, core).Len(); got != 1 {
+		t.Errorf("after Invalidate matched %d rows, want 1", got)
+	}
+}
+
+func TestIndexSkipsMultiValueDimensions(t *testing.T) {
+	view := NewIndexedView(NewSliceView([]Record{
+		{Dimensions: map[string]string{"labels": "api;urgent"}},
+		{Dimensions: map[string]string{"labels": "web"}},
+	}))
+	tagged := NewTagView(view, map[string]string{"labels": ";"})
+	if _, ok := filterIndex(tagged, "labels"); ok {
+		t.Fatal("TagView must not forward a raw-cell index for tags")
+	}
+	got := ApplyFilters(tagged, Filters{Dimensions: map[string][]string{"labels": {"urgent"}}})
+	if got.Len() != 1 {
+		t.Errorf("tag filter matched %d rows, want 1", got.Len())
+	}
+}
+
+func TestBitmapOps(t *testing.T) {
+	a, b := NewBitmap(130), NewBitmap(130)
+	for _, i := range []int{0, 64, 129} {
+		a.Set(i)
+	}
+	for _, i := range []int{64, 100} {
+		b.Set(i)
+	}
+	a.Or(b)
+	if got := a.Indices(); !reflect.DeepEqual(got, []int{0, 64, 100, 129}) {
+		t.Errorf("Or = %v", got)
+	}
+	a.And(b)
+	if got := a.Indices(); !reflect.DeepEqual(got, []int{64, 100}) || a.Count() != 2 {
+		t.Errorf("And = %v", got)
+	}
+}
@@ -0,0 +1,375 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// LOCALE — number, currency and period formatting for display
+// ============================================================================
+// Without a locale the engine formats the classic way: "SGD 1,234.56",
+// "1,234", "Jan-2026". WithLocale switches every display string — text
+// values, table cells and summaries, reply placeholders, period labels and
+// month group labels — to the locale's conventions:
+//
+//   en-US   $1,234.56        Jan-2026
+//   en-IN   ₹12,34,567.00    Jan-2026   (lakh grouping)
+//   de-DE   1.234,56 €       Mär-2026
+//   fr-FR   1 234,56 €       mars-2026
+//
+// Chart values stay numeric; ChartConfig.Locale tells the renderer which
+// conventions to use for axes. WithMeasureFormat sets a measure's decimal
+// precision from a pattern such as "#,##0.00" (MeasureMeta.Format).
+// ============================================================================
+
+// Locale describes how numbers, currencies and month names are written.
+type Locale struct {
+	Code            string     `json:"code"`
+	Decimal         string     `json:"decimal"`                   // decimal separator
+	Group           string     `json:"group"`                     // thousands separator
+	IndianGrouping  bool       `json:"indianGrouping,omitempty"`  // 12,34,567 rather than 1,234,567
+	CurrencySymbols bool       `json:"currencySymbols,omitempty"` // "€" rather than "EUR" where known
+	CurrencyPattern string     `json:"currencyPattern"`           // "¤#", "¤ #" or "# ¤"; ¤ is the symbol or code
+	Months          [12]string `json:"months"`                    // short month names, January first
+}
+
+var englishMonths = [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// DefaultLocale is the classic engine formatting: "SGD 1,234.56".
+var DefaultLocale = Locale{Code: "en", Decimal: ".", Group: ",", CurrencyPattern: "¤ #", Months: englishMonths}
+
+// locales are the built-in locales by code.
+var locales = map[string]Locale{
+	"en":    DefaultLocale,
+	"en-US": {Code: "en-US", Decimal: ".", Group: ",", CurrencySymbols: true, CurrencyPattern: "¤#", Months: englishMonths},
+	"en-GB": {Code: "en-GB", Decimal: ".", Group: ",", CurrencySymbols: true, CurrencyPattern: "¤#", Months: englishMonths},
+	"en-IN": {Code: "en-IN", Decimal: ".", Group: ",", IndianGrouping: true, CurrencySymbols: true, CurrencyPattern: "¤#", Months: englishMonths},
+	"de-DE": {Code: "de-DE", Decimal: ",", Group: ".", CurrencySymbols: true, CurrencyPattern: "# ¤",
+		Months: [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"}},
+	"fr-FR": {Code: "fr-FR", Decimal: ",", Group: " ", CurrencySymbols: true, CurrencyPattern: "# ¤",
+		Months: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."}},
+	"es-ES": {Code: "es-ES", Decimal: ",", Group: ".", CurrencySymbols: true, CurrencyPattern: "# ¤",
+		Months: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"}},
+	"pt-BR": {Code: "pt-BR", Decimal: ",", Group: ".", CurrencySymbols: true, CurrencyPattern: "¤ #",
+		Months: [12]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"}},
+}
+
+// currencySymbols are used by locales with CurrencySymbols set.
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "INR": "₹", "JPY": "¥", "BRL": "R$", "KRW": "₩",
+}
+
+// LookupLocale returns a built-in locale by code ("de-DE"). A bare
+// language ("de") or a code with "_" ("de_DE") also matches.
+func LookupLocale(code string) (Locale, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), "_", "-")
+	for c, l := range locales {
+		if strings.EqualFold(c, code) {
+			return l, true
+		}
+	}
+	lang := strings.ToLower(strings.SplitN(code, "-", 2)[0])
+	best := ""
+	for c := range locales {
+		if strings.HasPrefix(strings.ToLower(c), lang+"-") && (best == "" || c < best) {
+			best = c
+		}
+	}
+	if best == "" {
+		return Locale{}, false
+	}
+	return locales[best], true
+}
+
+// WithLocale formats display strings with l's separators, currency style
+// and month names (see LookupLocale for the built-ins).
+func WithLocale(l Locale) Option {
+	return func(c *config) {
+		c.Locale = &l
+	}
+}
+
+// WithMeasureFormat sets a measure's display precision from a number
+// pattern: "#,##0.00" → 2 decimals, "#,##0" → 0, "0.0%" → 1.
+func WithMeasureFormat(measure, format string) Option {
+	return func(c *config) {
+		if c.MeasureFormats == nil {
+			c.MeasureFormats = make(map[string]string)
+		}
+		c.MeasureFormats[measure] = format
+	}
+}
+
+// formatDecimals reads the decimal places a number pattern asks for.
+func formatDecimals(format string) (int, bool) {
+	if !strings.ContainsAny(format, "0#") {
+		return 0, false
+	}
+	dot := strings.IndexByte(format, '.')
+	if dot < 0 {
+		return 0, true
+	}
+	n := 0
+	for _, r := range format[dot+1:] {
+		if r != '0' && r != '#' {
+			break
+		}
+		n++
+	}
+	return n, true
+}
+
+// FormatNumber writes v with exactly decimals places and the locale's
+// separators.
+func (l Locale) FormatNumber(v float64, decimals int) string {
+	return l.number(v, decimals, false)
+}
+
+// FormatInt writes n with the locale's grouping.
+func (l Locale) FormatInt(n int) string {
+	return l.number(float64(n), 0, false)
+}
+
+// FormatCurrency writes amount with decimals places, placing the currency's
+// symbol (where the locale uses symbols and one is known) or its code per
+// CurrencyPattern.
+func (l Locale) FormatCurrency(amount float64, currency string, decimals int) string {
+	num := l.number(math.Abs(amount), decimals, false)
+	sign := ""
+	if amount < 0 && strings.ContainsAny(num, "123456789") {
+		sign = "-"
+	}
+	if currency == "" {
+		return sign + num
+	}
+	symbol, pattern := currency, l.CurrencyPattern
+	if s, ok := currencySymbols[strings.ToUpper(currency)]; ok && l.CurrencySymbols {
+		symbol = s
+	} else {
+		// Codes always stand apart from the number: "SGD 1,234.56"
+		pattern = strings.NewReplacer("¤#", "¤ #", "#¤", "# ¤").Replace(pattern)
+	}
+	return sign + strings.NewReplacer("¤", symbol, "#", num).Replace(pattern)
+}
+
+// FormatValue formats value in unit as FormatValue does, with the locale's
+// conventions. decimals < 0 uses the unit's default precision (2 for
+// currency, at most 1 for scaled units and percent, at most 2 otherwise).
+func (l Locale) FormatValue(value float64, unit string, decimals int) string {
+	u, ok := LookupUnit(unit)
+	if !ok || u.Kind == UnitCurrency {
+		if decimals < 0 {
+			decimals = 2
+		}
+		return l.FormatCurrency(value, unit, decimals)
+	}
+	number := func(v float64, max int) string {
+		if decimals >= 0 {
+			return l.number(v, decimals, false)
+		}
+		return l.number(v, max, true)
+	}
+	switch u.Kind {
+	case UnitDuration, UnitData:
+		scales := durationUnits
+		if u.Kind == UnitData {
+			scales = dataUnits
+		}
+		base := value * u.Factor
+		s := scales[0]
+		for _, candidate := range scales[1:] {
+			if math.Abs(base) >= candidate.Factor {
+				s = candidate
+			}
+		}
+		return number(base/s.Factor, 1) + " " + s.Symbol
+	case UnitPercent:
+		return number(value, 1) + "%"
+	default:
+		if u.Symbol != "" {
+			return number(value, 2) + " " + u.Symbol
+		}
+		return number(value, 2)
+	}
+}
+
+// englishMonth matches the English month names engine labels use.
+var englishMonth = regexp.MustCompile(`\b(Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)\b`)
+
+// Period rewrites the month names in a period label ("Jan-2026",
+// "Jul-2026 – Sep-2026", "Mar 5, 2026") in the locale's language.
+func (l Locale) Period(label string) string {
+	if l.Months == englishMonths || l.Months == ([12]string{}) {
+		return label
+	}
+	return englishMonth.ReplaceAllStringFunc(label, func(m string) string {
+		for i, name := range englishMonths {
+			if name == m {
+				return l.Months[i]
+			}
+		}
+		return m
+	})
+}
+
+// monthLabel matches a month group label ("Jan-2026").
+var monthLabel = regexp.MustCompile(`^[A-Z][a-z]{2}-\d{4}$`)
+
+// number writes v with decimals places, trimming trailing zeros when trim
+// is set, grouped per the locale.
+func (l Locale) number(v float64, decimals int, trim bool) string {
+	scale := math.Pow(10, float64(decimals))
+	str := strconv.FormatFloat(math.Round(math.Abs(v)*scale)/scale, 'f', decimals, 64)
+	intPart, frac := str, ""
+	if dot := strings.IndexByte(str, '.'); dot >= 0 {
+		intPart, frac = str[:dot], str[dot+1:]
+	}
+	if trim {
+		frac = strings.TrimRight(frac, "0")
+	}
+	out := l.group(intPart)
+	if frac != "" {
+		out += l.Decimal + frac
+	}
+	if v < 0 && strings.ContainsAny(str, "123456789") {
+		out = "-" + out
+	}
+	return out
+}
+
+// group inserts the locale's grouping separator into a run of digits.
+func (l Locale) group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	size := 3
+	if l.IndianGrouping {
+		size = 2
+	}
+	var parts []string
+	for len(head) > size {
+		parts = append([]string{head[len(head)-size:]}, parts...)
+		head = head[:len(head)-size]
+	}
+	parts = append([]string{head}, parts...)
+	return strings.Join(append(parts, tail), l.Group)
+}
+
+// ============================================================================
+// VALUE FORMAT — one measure's display settings for an execution
+// ============================================================================
+
+// valueFormat formats a measure's values for display: its unit, the
+// configured locale, and its precision.
+type valueFormat struct {
+	unit     string
+	locale   *Locale // nil = classic formatting
+	decimals int     // fixed decimal places; -1 = the unit's default
+}
+
+// classicFormat formats in unit the way the engine always has.
+func classicFormat(unit string) valueFormat {
+	return valueFormat{unit: unit, decimals: -1}
+}
+
+// valueFormat returns the display settings for measure shown in unit.
+func (c *config) valueFormat(measure, unit string) valueFormat {
+	f := valueFormat{unit: unit, locale: c.Locale, decimals: -1}
+	if d, ok := formatDecimals(c.MeasureFormats[measure]); ok {
+		f.decimals = d
+	}
+	return f
+}
+
+// forAggregation adjusts f for an aggregation's result: counts (of rows or
+// distinct values) are counts.
+func (f valueFormat) forAggregation(aggregation string) valueFormat {
+	if aggregation == "count" || aggregation == "distinct" {
+		return valueFormat{unit: countUnit.Name, locale: f.locale, decimals: -1}
+	}
+	return f
+}
+
+func (f valueFormat) loc() Locale {
+	if f.locale == nil {
+		return DefaultLocale
+	}
+	return *f.locale
+}
+
+// value formats v in f's unit.
+func (f valueFormat) value(v float64) string {
+	if f.locale == nil && f.decimals < 0 {
+		return FormatValue(v, f.unit)
+	}
+	return f.loc().FormatValue(v, f.unit, f.decimals)
+}
+
+// count formats a record count.
+func (f valueFormat) count(n int) string {
+	if f.locale == nil {
+		return FormatInt(n)
+	}
+	return f.locale.FormatInt(n)
+}
+
+// cell formats v for a table cell: fixed precision, no grouping.
+func (f valueFormat) cell(v float64) string {
+	if f.locale == nil && f.decimals < 0 {
+		return fmt.Sprintf("%.2f", v)
+	}
+	decimals := f.decimals
+	if decimals < 0 {
+		decimals = 2
+	}
+	l := f.loc()
+	l.Group = ""
+	return l.FormatNumber(v, decimals)
+}
+
+// percent formats a percentage with one decimal ("12.5%").
+func (f valueFormat) percent(v float64) string {
+	if f.locale == nil {
+		return fmt.Sprintf("%.1f%%", v)
+	}
+	return f.locale.FormatNumber(v, 1) + "%"
+}
+
+// period localizes a period label.
+func (f valueFormat) period(label string) string {
+	if f.locale == nil {
+		return label
+	}
+	return f.locale.Period(label)
+}
+
+// decorateChart attaches the value unit and locale to a built chart and
+// localizes its month labels.
+func (f valueFormat) decorateChart(c *ChartConfig, aggregation string) {
+	c.YAxisUnit = unitInfo(f.forAggregation(aggregation).unit)
+	if f.locale == nil {
+		return
+	}
+	c.Locale = f.locale.Code
+	for i := range c.Series {
+		s := &c.Series[i]
+		s.Name = f.groupLabel(s.Name)
+		for j := range s.Data {
+			s.Data[j].Label = f.groupLabel(s.Data[j].Label)
+		}
+	}
+}
+
+// groupLabel localizes a month group label ("Jan-2026"); other labels pass
+// through.
+func (f valueFormat) groupLabel(label string) string {
+	if f.locale == nil || !monthLabel.MatchString(label) {
+		return label
+	}
+	return f.locale.Period(label)
+}
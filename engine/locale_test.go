@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// LOCALE TESTS — separators, currency placement, precision, month names
+// ============================================================================
+
+func mustLocale(t *testing.T, code string) Locale {
+	t.Helper()
+	l, ok := LookupLocale(code)
+	if !ok {
+		t.Fatalf("locale %q not found", code)
+	}
+	return l
+}
+
+func TestLocaleFormatCurrency(t *testing.T) {
+	tests := []struct {
+		locale   string
+		amount   float64
+		currency string
+		decimals int
+		want     string
+	}{
+		{"en", 1234.56, "SGD", 2, "SGD 1,234.56"},
+		{"en-US", 1234.56, "USD", 2, "$1,234.56"},
+		{"en-US", -5, "USD", 2, "-$5.00"},
+		{"en-US", 1234.56, "SGD", 2, "SGD 1,234.56"},
+		{"en-IN", 1234567, "INR", 0, "₹12,34,567"},
+		{"en-IN", 123456789.5, "INR", 2, "₹12,34,56,789.50"},
+		{"de-DE", 1234.56, "EUR", 2, "1.234,56 €"},
+		{"de-DE", 1234.56, "SGD", 2, "1.234,56 SGD"},
+		{"fr-FR", 1234.56, "EUR", 2, "1\u202f234,56 €"},
+		{"pt-BR", 1234.56, "BRL", 2, "R$ 1.234,56"},
+		{"de-DE", 1234.56, "", 1, "1.234,6"},
+	}
+	for _, tt := range tests {
+		got := mustLocale(t, tt.locale).FormatCurrency(tt.amount, tt.currency, tt.decimals)
+		if got != tt.want {
+			t.Errorf("%s FormatCurrency(%v, %q, %d) = %q, want %q", tt.locale, tt.amount, tt.currency, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleValuesAndPeriods(t *testing.T) {
+	de := mustLocale(t, "de")
+	if de.Code != "de-DE" {
+		t.Errorf("LookupLocale(de) = %s", de.Code)
+	}
+	if _, ok := LookupLocale("xx-YY"); ok {
+		t.Error("expected unknown locale")
+	}
+	if l := mustLocale(t, "en_in"); l.Code != "en-IN" {
+		t.Errorf("LookupLocale(en_in) = %s", l.Code)
+	}
+
+	if got := de.FormatValue(11520000, "ms", -1); got != "3,2 h" {
+		t.Errorf("duration = %q", got)
+	}
+	if got := de.FormatValue(12.5, "percent", -1); got != "12,5%" {
+		t.Errorf("percent = %q", got)
+	}
+	if got := de.Period("Mar-2026 – May-2026"); got != "Mär-2026 – Mai-2026" {
+		t.Errorf("period = %q", got)
+	}
+	if got := mustLocale(t, "fr-FR").Period("Feb-2026"); got != "févr.-2026" {
+		t.Errorf("fr period = %q", got)
+	}
+
+	for format, want := range map[string]int{"#,##0.00": 2, "#,##0": 0, "0.0%": 1, "0.000": 3} {
+		if got, ok := formatDecimals(format); !ok || got != want {
+			t.Errorf("formatDecimals(%q) = %d, %v", format, got, ok)
+		}
+	}
+	if _, ok := formatDecimals("currency"); ok {
+		t.Error("expected no precision from a pattern without digits")
+	}
+}
+
+func TestExecuteWithLocale(t *testing.T) {
+	view := NewSliceView([]Record{
+		{Dimensions: map[string]string{"month": "Mar-2026", "currency": "EUR"}, Measures: map[string]float64{"amount": 1234.5}},
+		{Dimensions: map[string]string{"month": "May-2026", "currency": "EUR"}, Measures: map[string]float64{"amount": 2000}},
+	})
+	opts := []Option{WithLocale(mustLocale(t, "de-DE")), WithMeasureFormat("amount", "#,##0")}
+
+	spec := QuerySpec{Intent: "text", Aggregation: "sum", Measure: "amount",
+		Reply: "Total {total} in {period}, top {top_category}"}
+	result, err := Execute(spec, view, opts...)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	text := result.Data.(*TextData)
+	if text.Value != "3.235" {
+		t.Errorf("value = %q", text.Value)
+	}
+	if text.Period != "Mär-2026 – Mai-2026" {
+		t.Errorf("period = %q", text.Period)
+	}
+	if !strings.Contains(result.Reply, "in Mär-2026 – Mai-2026") {
+		t.Errorf("reply = %q", result.Reply)
+	}
+
+	spec = QuerySpec{Intent: "table", Aggregation: "sum", Measure: "amount", GroupBy: []string{"month"}}
+	result, _ = Execute(spec, view, opts...)
+	rows := result.TableData.Rows
+	// Month groups sort chronologically, then display localized
+	if len(rows) != 2 || rows[0][0] != "Mär-2026" || rows[0][1] != "1235" || rows[1][0] != "Mai-2026" || rows[1][1] != "2000" {
+		t.Errorf("rows = %v", rows)
+	}
+
+	spec.Intent = "chart"
+	result, _ = Execute(spec, view, opts...)
+	chart := result.ChartConfig
+	if chart.Locale != "de-DE" {
+		t.Errorf("chart locale = %q", chart.Locale)
+	}
+	for _, p := range chart.Series[0].Data {
+		if p.Label != "Mär-2026" && p.Label != "Mai-2026" {
+			t.Errorf("chart label = %q", p.Label)
+		}
+	}
+
+	// Without a locale, output is unchanged
+	result, _ = Execute(QuerySpec{Intent: "text", Aggregation: "sum", Measure: "amount"}, view)
+	if v := result.Data.(*TextData).Value; v != FormatCurrency(3234.5, result.DisplayUnit) {
+		t.Errorf("classic value = %q", v)
+	}
+}
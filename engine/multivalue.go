@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// MULTI-VALUE DIMENSIONS — "backend;api;urgent" as three tags
+// ============================================================================
+// A multi-value dimension stores a delimiter-separated set in one cell.
+// Dimension() still returns the raw cell; DimensionValues() returns the set.
+//
+//   Filters  — a record matches if ANY of its values is selected
+//   Grouping — a record is counted in EVERY one of its value groups
+//
+// Because groups overlap, group values no longer add up to the total.
+// Execute adds a warning to Result.Warnings when grouping by such a dimension.
+// ============================================================================
+
+// MultiValueView is implemented by views that expose multi-value dimensions.
+// DimensionValues returns the split values and true for a multi-value
+// dimension, or nil and false for an ordinary one.
+type MultiValueView interface {
+	RecordView
+	DimensionValues(index int, key string) ([]string, bool)
+}
+
+// DimensionValues returns the values of a multi-value dimension at index i.
+// For ordinary dimensions (or views without multi-value support) it returns
+// nil and false.
+func DimensionValues(view RecordView, i int, key string) ([]string, bool) {
+	if mv, ok := view.(MultiValueView); ok {
+		return mv.DimensionValues(i, key)
+	}
+	return nil, false
+}
+
+// IsMultiValue reports whether key is a multi-value dimension in view.
+func IsMultiValue(view RecordView, key string) bool {
+	if view.Len() == 0 {
+		return false
+	}
+	_, ok := DimensionValues(view, 0, key)
+	return ok
+}
+
+// SplitMultiValue splits a raw cell into trimmed, de-duplicated, non-empty values.
+func SplitMultiValue(raw, delimiter string) []string {
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, delimiter)
+	values := make([]string, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		values = append(values, p)
+	}
+	return values
+}
+
+// groupKeys returns the group keys a record belongs to for a dimension:
+// one key for ordinary dimensions, one per value for multi-value dimensions.
+func groupKeys(view RecordView, i int, dimension string) []string {
+	if values, ok := DimensionValues(view, i, dimension); ok {
+		if len(values) == 0 {
+			return []string{""}
+		}
+		return values
+	}
+	return []string{getDimensionValue(view, i, dimension)}
+}
+
+// overlapWarnings explains why grouped values overlap for multi-value group-bys.
+func overlapWarnings(spec QuerySpec, view RecordView) []string {
+	var warnings []string
+	for _, dim := range spec.GroupBy {
+		if !IsMultiValue(view, dim) {
+			continue
+		}
+		label := LabelForDimension(dim)
+		if spec.Visualize == "pie" {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s values overlap — records with several values appear in more than one slice, so slices do not add up to 100%%.", label))
+		} else {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s values overlap — records with several values are counted in each group, so group totals exceed the overall total.", label))
+		}
+	}
+	return warnings
+}
+
+// ============================================================================
+// TAG VIEW — splits multi-value dimensions on read (zero-copy)
+// ============================================================================
+
+// TagView wraps a RecordView and exposes configured dimensions as
+// multi-value sets. Split results are cached per raw cell value.
+type TagView struct {
+	parent     RecordView
+	delimiters map[string]string // dimension → delimiter
+	cache      sync.Map          // dimension + "\x00" + raw value → []string
+}
+
+// NewTagView wraps view so the given dimensions (dimension → delimiter) are
+// treated as multi-value. Returns view unchanged when there are none.
+func NewTagView(view RecordView, delimiters map[string]string) RecordView {
+	if len(delimiters) == 0 {
+		return view
+	}
+	return &TagView{parent: view, delimiters: delimiters}
+}
+
+func (v *TagView) Len() int { return v.parent.Len() }
+
+func (v *TagView) Dimension(i int, key string) string { return v.parent.Dimension(i, key) }
+
+func (v *TagView) DimensionValues(i int, key string) ([]string, bool) {
+	delim, ok := v.delimiters[key]
+	if !ok {
+		return DimensionValues(v.parent, i, key)
+	}
+	raw := v.parent.Dimension(i, key)
+	cacheKey := key + "\x00" + raw
+	if values, ok := v.cache.Load(cacheKey); ok {
+		return values.([]string), true
+	}
+	values := SplitMultiValue(raw, delim)
+	v.cache.Store(cacheKey, values)
+	return values, true
+}
+
+// Dictionary forwards codes for single-value dimensions; tag sets are split on read.
+func (v *TagView) Dictionary(key string) ([]string, bool) {
+	if _, ok := v.delimiters[key]; ok {
+		return nil, false
+	}
+	_, dict, ok := dimensionDictionary(v.parent, key)
+	return dict, ok
+}
+
+func (v *TagView) DimensionCode(i int, key string) uint32 {
+	return v.parent.(CodedView).DimensionCode(i, key)
+}
+
+// FilterIndex forwards indexes for single-value dimensions only — a parent
+// index holds raw cells, not the split tags.
+func (v *TagView) FilterIndex(key string) (*DimensionIndex, bool) {
+	if _, ok := v.delimiters[key]; ok {
+		return nil, false
+	}
+	return filterIndex(v.parent, key)
+}
+
+func (v *TagView) Measure(i int, key string) float64 { return v.parent.Measure(i, key) }
+func (v *TagView) HasMeasure(i int, key string) bool { return HasMeasure(v.parent, i, key) }
+func (v *TagView) DimensionKeys() []string           { return v.parent.DimensionKeys() }
+func (v *TagView) MeasureKeys() []string             { return v.parent.MeasureKeys() }
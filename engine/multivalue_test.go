@@ -0,0 +1,83 @@
+package engine
+
+import "testing"
+
+// ============================================================================
+// MULTI-VALUE DIMENSION TESTS
+// ============================================================================
+
+func taggedView() RecordView {
+	return NewSliceView([]Record{
+		{Dimensions: map[string]string{"labels": "backend;api"}, Measures: map[string]float64{"points": 5}},
+		{Dimensions: map[string]string{"labels": "backend; urgent"}, Measures: map[string]float64{"points": 3}},
+		{Dimensions: map[string]string{"labels": "frontend"}, Measures: map[string]float64{"points": 2}},
+		{Dimensions: map[string]string{"labels": ""}, Measures: map[string]float64{"points": 1}},
+	})
+}
+
+func TestSplitMultiValue(t *testing.T) {
+	got := SplitMultiValue(" api ; backend;;api ", ";")
+	if len(got) != 2 || got[0] != "api" || got[1] != "backend" {
+		t.Errorf("SplitMultiValue = %v, want [api backend]", got)
+	}
+}
+
+func TestExecuteGroupByMultiValue(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "chart",
+		Aggregation: "sum",
+		Measure:     "points",
+		GroupBy:     []string{"labels"},
+		SortBy:      "label_asc",
+		Visualize:   "pie",
+	}
+
+	result, err := Execute(spec, taggedView(), WithMultiValue("labels", ";"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	got := make(map[string]float64)
+	for _, p := range result.ChartConfig.Series[0].Data {
+		got[p.Label] = p.Value
+	}
+	want := map[string]float64{"(blank)": 1, "api": 5, "backend": 8, "frontend": 2, "urgent": 3}
+	if len(got) != len(want) {
+		t.Fatalf("groups = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("group %q = %v, want %v", k, got[k], v)
+		}
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want one overlap warning", result.Warnings)
+	}
+}
+
+func TestExecuteFilterMultiValue(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "text",
+		Aggregation: "sum",
+		Measure:     "points",
+		Filters:     Filters{Dimensions: map[string][]string{"labels": {"backend"}}},
+	}
+
+	result, err := Execute(spec, taggedView(), WithMultiValue("labels", ";"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if text := result.Data.(*TextData); text.RawValue != 8 {
+		t.Errorf("RawValue = %v, want 8 (both records tagged backend)", text.RawValue)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("ungrouped query should not warn, got %v", result.Warnings)
+	}
+}
+
+func TestGroupByWithoutMultiValueKeepsCombinations(t *testing.T) {
+	groups := GroupAndAggregate(taggedView(), []string{"labels"}, "points", "sum", "", 0)
+	if len(groups) != 4 {
+		t.Errorf("got %d groups, want 4 distinct raw combinations", len(groups))
+	}
+}
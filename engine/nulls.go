@@ -0,0 +1,131 @@
+package engine
+
+// ============================================================================
+// NULLS & BLANKS — Missing measures and empty dimension values
+// ============================================================================
+// Measures: a view that implements NullableView reports missing values.
+// avg / min / max / median skip them; sum treats them as 0; count counts rows.
+//
+// Dimensions: an empty string is a blank. When grouping, blanks are gathered
+// under DefaultBlankLabel ("(blank)") — configurable with WithBlankLabel,
+// or dropped entirely with WithExcludeBlanks.
+// ============================================================================
+
+// DefaultBlankLabel is the group label for records with an empty dimension value.
+const DefaultBlankLabel = "(blank)"
+
+// HasMeasure reports whether the record at index i has a value for measure key.
+// Views that do not implement NullableView always report true.
+func HasMeasure(view RecordView, i int, key string) bool {
+	if nv, ok := view.(NullableView); ok {
+		return nv.HasMeasure(i, key)
+	}
+	return true
+}
+
+// blankLabel returns the configured label for blank group-by values.
+func (c *config) blankLabel() string {
+	if c.BlankLabel != "" {
+		return c.BlankLabel
+	}
+	return DefaultBlankLabel
+}
+
+// applyBlankPolicy prepares a view for grouping: records with a blank
+// group-by value are dropped (WithExcludeBlanks) or relabelled.
+func applyBlankPolicy(view RecordView, groupBy []string, cfg *config) RecordView {
+	if len(groupBy) == 0 {
+		return view
+	}
+	if cfg.ExcludeBlanks {
+		return excludeBlanks(view, groupBy)
+	}
+	return newBlankView(view, groupBy, cfg.blankLabel())
+}
+
+// excludeBlanks returns the records whose group-by dimensions are all non-empty.
+func excludeBlanks(view RecordView, dims []string) RecordView {
+	n := view.Len()
+	indices := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		keep := true
+		for _, dim := range dims {
+			if keys := groupKeys(view, i, dim); len(keys) == 1 && keys[0] == "" {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) == n {
+		return view
+	}
+	return newSubView(view, indices)
+}
+
+// ============================================================================
+// BLANK VIEW — labels empty group-by values on read (zero-copy)
+// ============================================================================
+
+// BlankView wraps a RecordView and answers the blank label for empty values
+// of the wrapped dimensions. Other dimensions and all measures pass through.
+type BlankView struct {
+	parent RecordView
+	dims   map[string]bool
+	label  string
+}
+
+func newBlankView(parent RecordView, dims []string, label string) RecordView {
+	set := make(map[string]bool, len(dims))
+	for _, d := range dims {
+		set[d] = true
+	}
+	return &BlankView{parent: parent, dims: set, label: label}
+}
+
+func (v *BlankView) Len() int { return v.parent.Len() }
+
+func (v *BlankView) Dimension(i int, key string) string {
+	if !v.dims[key] {
+		return v.parent.Dimension(i, key)
+	}
+	if val := getDimensionValue(v.parent, i, key); val != "" {
+		return val
+	}
+	return v.label
+}
+
+func (v *BlankView) DimensionValues(i int, key string) ([]string, bool) {
+	values, ok := DimensionValues(v.parent, i, key)
+	if ok && len(values) == 0 && v.dims[key] {
+		return []string{v.label}, true
+	}
+	return values, ok
+}
+
+// Dictionary forwards codes for dimensions this view does not relabel.
+func (v *BlankView) Dictionary(key string) ([]string, bool) {
+	if v.dims[key] {
+		return nil, false
+	}
+	_, dict, ok := dimensionDictionary(v.parent, key)
+	return dict, ok
+}
+
+func (v *BlankView) DimensionCode(i int, key string) uint32 {
+	return v.parent.(CodedView).DimensionCode(i, key)
+}
+
+func (v *BlankView) FilterIndex(key string) (*DimensionIndex, bool) {
+	if v.dims[key] {
+		return nil, false
+	}
+	return filterIndex(v.parent, key)
+}
+
+func (v *BlankView) Measure(i int, key string) float64 { return v.parent.Measure(i, key) }
+func (v *BlankView) HasMeasure(i int, key string) bool { return HasMeasure(v.parent, i, key) }
+func (v *BlankView) DimensionKeys() []string           { return v.parent.DimensionKeys() }
+func (v *BlankView) MeasureKeys() []string             { return v.parent.MeasureKeys() }
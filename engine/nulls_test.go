@@ -0,0 +1,150 @@
+package engine
+
+import "testing"
+
+// ============================================================================
+// NULL & BLANK TESTS
+// ============================================================================
+
+// sparseView has a missing score on one record and a blank team on another.
+func sparseView() RecordView {
+	return NewSliceView([]Record{
+		{Dimensions: map[string]string{"team": "Core"}, Measures: map[string]float64{"score": 10}},
+		{Dimensions: map[string]string{"team": "Core"}, Measures: map[string]float64{}},
+		{Dimensions: map[string]string{"team": "Web"}, Measures: map[string]float64{"score": 4}},
+		{Dimensions: map[string]string{"team": ""}, Measures: map[string]float64{"score": 6}},
+	})
+}
+
+func TestAggregationsSkipMissingMeasures(t *testing.T) {
+	view := sparseView()
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"avg", AvgMeasure(view, "score"), 20.0 / 3},
+		{"min", MinMeasure(view, "score"), 4},
+		{"max", MaxMeasure(view, "score"), 10},
+		{"median", MedianMeasure(view, "score"), 6},
+		{"sum", SumMeasure(view, "score"), 20},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if HasMeasure(view, 1, "score") {
+		t.Error("HasMeasure should be false for a missing score")
+	}
+}
+
+func TestDomainViewNullableMeasure(t *testing.T) {
+	type row struct{ score *float64 }
+	v := 8.0
+	view := NewDomainAdapter[row]().
+		NullableMeasure("score", func(r row) (float64, bool) {
+			if r.score == nil {
+				return 0, false
+			}
+			return *r.score, true
+		}).
+		Bind([]row{{&v}, {nil}})
+
+	if got := MinMeasure(view, "score"); got != 8 {
+		t.Errorf("MinMeasure = %v, want 8 (nil skipped)", got)
+	}
+}
+
+func TestExecuteBlankGroupLabel(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "chart",
+		Aggregation: "count",
+		Measure:     "score",
+		GroupBy:     []string{"team"},
+		SortBy:      "label_asc",
+		Visualize:   "bar",
+	}
+
+	labels := func(opts ...Option) []string {
+		result, err := Execute(spec, sparseView(), opts...)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		var out []string
+		for _, p := range result.ChartConfig.Series[0].Data {
+			out = append(out, p.Label)
+		}
+		return out
+	}
+
+	if got := labels(); len(got) != 3 || got[0] != "(blank)" {
+		t.Errorf("default labels = %v, want (blank) group", got)
+	}
+	if got := labels(WithBlankLabel("Unassigned")); len(got) != 3 || got[1] != "Unassigned" {
+		t.Errorf("custom labels = %v, want Unassigned group", got)
+	}
+	if got := labels(WithExcludeBlanks()); len(got) != 2 {
+		t.Errorf("excluded labels = %v, want 2 groups", got)
+	}
+}
+
+func TestFilterSelectsBlank(t *testing.T) {
+	filtered := ApplyFilters(sparseView(), Filters{Dimensions: map[string][]string{"team": {"(blank)"}}})
+	if filtered.Len() != 1 || filtered.Measure(0, "score") != 6 {
+		t.Errorf("filter on (blank) matched %d records", filtered.Len())
+	}
+}
+
+func TestFilterSelectsCustomBlankLabel(t *testing.T) {
+	records := []Record{
+		{Dimensions: map[string]string{"team": "Core"}, Measures: map[string]float64{"score": 10}},
+		{Dimensions: map[string]string{"team": "Web"}, Measures: map[string]float64{"score": 4}},
+		{Dimensions: map[string]string{"team": ""}, Measures: map[string]float64{"score": 6}},
+	}
+	appendable := NewAppendableView([]string{"team"}, []string{"score"})
+	if err := appendable.RegisterIndex("team"); err != nil {
+		t.Fatalf("RegisterIndex failed: %v", err)
+	}
+	if err := appendable.Append(records[:2]...); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := appendable.Append(records[2]); err != nil { // extends the index
+		t.Fatalf("Append failed: %v", err)
+	}
+	cube, err := BuildCube(NewSliceView(records), CubeSpec{Dimensions: []string{"team"}, Measures: []string{"score"}, Aggregations: []string{"sum"}})
+	if err != nil {
+		t.Fatalf("BuildCube failed: %v", err)
+	}
+
+	views := map[string]RecordView{
+		"slice":    NewSliceView(records),
+		"columnar": NewColumnarView(records),
+		"indexed":  NewIndexedView(NewSliceView(records), "team"),
+		"append":   appendable,
+	}
+	spec := QuerySpec{Intent: "text", Aggregation: "sum", Measure: "score",
+		Filters: Filters{Dimensions: map[string][]string{"team": {"unassigned"}}}}
+	check := func(name string, view RecordView, opts ...Option) {
+		result, err := Execute(spec, view, append(opts, WithBlankLabel("Unassigned"))...)
+		if err != nil {
+			t.Fatalf("%s: Execute failed: %v", name, err)
+		}
+		if text, ok := result.Data.(*TextData); !ok || text.RawValue != 6 {
+			t.Errorf("%s: filter on custom blank label = %+v, want the blank record's 6", name, result.Data)
+		}
+	}
+	for name, view := range views {
+		check(name, view)
+	}
+	check("cube", NewSliceView(records), WithCube(cube))
+
+	// The default label no longer names the blank group
+	result, err := Execute(spec, NewSliceView(records))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if text, ok := result.Data.(*TextData); ok && text.RawValue != 0 {
+		t.Errorf("without WithBlankLabel, \"unassigned\" matched %v", text.RawValue)
+	}
+}
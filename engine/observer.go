@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/spektr-org/spektr/observe"
+)
+
+// ============================================================================
+// OBSERVER — logs, spans and metrics for each execution
+// ============================================================================
+// Execute reports to the Observer injected with WithObserver, or to
+// observe.Default() (a no-op unless the host sets one):
+//
+//   span   spektr.execute              attrs: intent, aggregation, measure
+//   span   spektr.execute.<stage>      cube, time range, filter, currency,
+//                                      group, aggregate, build
+//   metric spektr.execute.records      rows in the queried view
+//   metric spektr.execute.matched      rows left after filtering
+//   metric spektr.execute.duration_ms  wall time of the whole query
+//
+// Errors (including cancellation and exceeded limits) are recorded on the
+// spektr.execute span.
+// ============================================================================
+
+// WithObserver sends this execution's logs, spans and metrics to o.
+func WithObserver(o observe.Observer) Option {
+	return func(c *config) {
+		c.Observer = o
+	}
+}
+
+func (c *config) observer() observe.Observer {
+	return observe.Or(c.Observer)
+}
+
+func (c *config) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c *config) log(level observe.Level, msg string, attrs ...observe.Attr) {
+	c.observer().Log(c.context(), level, msg, attrs...)
+}
+
+func (c *config) metric(name string, value float64, attrs ...observe.Attr) {
+	c.observer().Metric(c.context(), name, value, attrs...)
+}
+
+// stage is one timed pipeline stage: a child span, plus a StageTrace in
+// explain mode.
+type stage struct {
+	name  string
+	start time.Time
+	span  observe.Span
+	cfg   *config
+	ended bool
+}
+
+func (c *config) stage(name string) *stage {
+	_, span := c.observer().Start(c.context(), "spektr.execute."+name)
+	s := &stage{name: name, start: time.Now(), span: span, cfg: c}
+	c.openStage = s
+	return s
+}
+
+func (s *stage) end() {
+	if s.ended {
+		return
+	}
+	s.ended = true
+	s.span.End()
+	if tr := s.cfg.trace; tr != nil {
+		tr.Stages = append(tr.Stages, StageTrace{Stage: s.name, Ms: millis(time.Since(s.start))})
+	}
+	if s.cfg.openStage == s {
+		s.cfg.openStage = nil
+	}
+}
+
+// endOpenStage, deferred after recoverAbort, ends the stage an abort (or
+// any panic) left open, recording *err on its span, so exporters see every
+// child span end and the trace keeps the stage.
+func (c *config) endOpenStage(err *error) {
+	if s := c.openStage; s != nil {
+		if *err != nil {
+			s.span.RecordError(*err)
+		}
+		s.end()
+	}
+}
+
+// startExecute opens the spektr.execute span; the returned func, deferred,
+// records err and the execution metrics and ends the span.
+func (c *config) startExecute(ctx context.Context, spec QuerySpec, view RecordView) func(err *error) {
+	o := c.observer()
+	ctx, span := observe.Start(ctx, o, "spektr.execute",
+		observe.String("intent", spec.Intent), observe.String("aggregation", spec.Aggregation),
+		observe.String("measure", spec.Measure))
+	c.ctx = ctx
+	start := time.Now()
+	return func(err *error) {
+		elapsed := time.Since(start)
+		span.SetAttributes(observe.Int("records", view.Len()))
+		if *err != nil {
+			span.RecordError(*err)
+		}
+		span.End()
+		o.Metric(ctx, "spektr.execute.records", float64(view.Len()))
+		o.Metric(ctx, "spektr.execute.duration_ms", millis(elapsed))
+	}
+}
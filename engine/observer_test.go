@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/spektr-org/spektr/observe"
+)
+
+// ============================================================================
+// OBSERVER TESTS
+// ============================================================================
+
+type recordingObserver struct {
+	mu      sync.Mutex
+	spans   []string
+	errs    map[string]error
+	metrics map[string]float64
+	logs    int
+}
+
+func newRecordingObserver() *recordingObserver {
+	return &recordingObserver{errs: map[string]error{}, metrics: map[string]float64{}}
+}
+
+func (o *recordingObserver) Start(ctx context.Context, name string, _ ...observe.Attr) (context.Context, observe.Span) {
+	return ctx, &recordingSpan{o: o, name: name}
+}
+
+func (o *recordingObserver) Log(context.Context, observe.Level, string, ...observe.Attr) {
+	o.mu.Lock()
+	o.logs++
+	o.mu.Unlock()
+}
+
+func (o *recordingObserver) Metric(_ context.Context, name string, value float64, _ ...observe.Attr) {
+	o.mu.Lock()
+	o.metrics[name] = value
+	o.mu.Unlock()
+}
+
+type recordingSpan struct {
+	o    *recordingObserver
+	name string
+	err  error
+}
+
+func (s *recordingSpan) SetAttributes(...observe.Attr) {}
+func (s *recordingSpan) RecordError(err error)         { s.err = err }
+
+func (s *recordingSpan) End() {
+	s.o.mu.Lock()
+	s.o.spans = append(s.o.spans, s.name)
+	if s.err != nil {
+		s.o.errs[s.name] = s.err
+	}
+	s.o.mu.Unlock()
+}
+
+func TestObserverSpansAndMetrics(t *testing.T) {
+	o := newRecordingObserver()
+	spec := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "amount", GroupBy: []string{"team"}, Visualize: "bar",
+		Filters: Filters{Dimensions: map[string][]string{"status": {"open"}}}}
+	if _, err := Execute(spec, NewSliceView(explainRecords()), WithObserver(o)); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	want := []string{"spektr.execute.filter", "spektr.execute.currency", "spektr.execute.group", "spektr.execute.build", "spektr.execute"}
+	if !reflect.DeepEqual(o.spans, want) {
+		t.Errorf("spans = %v, want %v", o.spans, want)
+	}
+	if o.metrics["spektr.execute.records"] != 6 || o.metrics["spektr.execute.matched"] != 4 {
+		t.Errorf("metrics = %v", o.metrics)
+	}
+	if _, ok := o.metrics["spektr.execute.duration_ms"]; !ok {
+		t.Error("no duration metric")
+	}
+	if o.logs == 0 {
+		t.Error("no logs")
+	}
+}
+
+func TestObserverRecordsErrors(t *testing.T) {
+	o := newRecordingObserver()
+	spec := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "amount", GroupBy: []string{"team"}, Visualize: "bar"}
+	_, err := Execute(spec, NewSliceView(explainRecords()), WithObserver(o), WithLimits(Limits{MaxGroups: 1}))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("err = %v, want ErrLimitExceeded", err)
+	}
+	if !errors.Is(o.errs["spektr.execute"], ErrLimitExceeded) {
+		t.Errorf("execute span error = %v", o.errs["spektr.execute"])
+	}
+
+	// The stage the limit stopped still ends, before its parent
+	want := []string{"spektr.execute.filter", "spektr.execute.currency", "spektr.execute.group", "spektr.execute"}
+	if !reflect.DeepEqual(o.spans, want) {
+		t.Errorf("spans = %v, want %v", o.spans, want)
+	}
+	if !errors.Is(o.errs["spektr.execute.group"], ErrLimitExceeded) {
+		t.Errorf("group span error = %v", o.errs["spektr.execute.group"])
+	}
+}
+
+func TestObserverEndsCancelledStage(t *testing.T) {
+	o := newRecordingObserver()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	view := &cancellingView{RecordView: NewSliceView(largeRecords()), after: 100, cancel: cancel}
+	spec := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "cost", GroupBy: []string{"team"}, Visualize: "bar"}
+	if _, err := ExecuteContext(ctx, spec, view, WithObserver(o)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	n := len(o.spans)
+	if n < 2 || o.spans[n-1] != "spektr.execute" {
+		t.Fatalf("spans = %v, want the cancelled stage and spektr.execute", o.spans)
+	}
+	if stage := o.spans[n-2]; !errors.Is(o.errs[stage], context.Canceled) {
+		t.Errorf("%s span error = %v, want context.Canceled", stage, o.errs[stage])
+	}
+}
+
+func TestObserverDefault(t *testing.T) {
+	o := newRecordingObserver()
+	observe.SetDefault(o)
+	defer observe.SetDefault(nil)
+
+	Execute(QuerySpec{Intent: "text", Aggregation: "sum", Measure: "amount"}, NewSliceView(explainRecords()))
+	if len(o.spans) == 0 || o.spans[len(o.spans)-1] != "spektr.execute" {
+		t.Errorf("default observer spans = %v", o.spans)
+	}
+}
@@ -1,5 +1,13 @@
 package engine
 
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/spektr-org/spektr/observe"
+)
+
 // ============================================================================
 // ENGINE OPTIONS — Functional options for Execute()
 // ============================================================================
@@ -9,9 +17,30 @@ type Option func(*config)
 
 type config struct {
 	BaseCurrency      string
-	CurrencyDimension string             // dimension key holding currency codes
-	ExchangeRates     map[string]float64 // foreign → base rate
-	DefaultMeasure    string             // default measure key if QuerySpec.Measure is empty
+	CurrencyDimension string              // dimension key holding currency codes
+	ExchangeRates     map[string]float64  // foreign → base rate
+	RateTable         *RateTable          // dated rates, looked up per record
+	RateDimension     string              // temporal dimension dating each record for RateTable
+	RateMode          RateMode            // RatePrevious (default) or RateAverage
+	CurrencyMeasures  []string            // measures holding currency amounts; empty = all
+	MeasureUnits      map[string]string   // measure → unit its values are stored in
+	MeasureFormats    map[string]string   // measure → number pattern setting display precision
+	Locale            *Locale             // display formatting; nil = classic "SGD 1,234.56"
+	DefaultMeasure    string              // default measure key if QuerySpec.Measure is empty
+	Clock             func() time.Time    // "now" for relative time ranges
+	Calendar          Calendar            // fiscal year, week start, time zone
+	BlankLabel        string              // group label for empty dimension values (default "(blank)")
+	ExcludeBlanks     bool                // drop records with an empty group-by value
+	MultiValue        map[string]string   // multi-value dimension → delimiter
+	ValueOrders       map[string][]string // dimension → ordinal value order
+	Parallelism       int                 // filter/aggregate workers; 0 = sequential
+	Cubes             []*Cube             // pre-aggregated rollups tried before raw records
+	Limits            Limits              // records scanned, groups, output rows
+	Explain           bool                // attach a Trace to the Result
+	Observer          observe.Observer    // logs, spans and metrics; nil = observe.Default()
+	trace             *Trace              // this execution's trace when Explain is set
+	ctx               context.Context     // carries the spektr.execute span
+	openStage         *stage              // stage started and not yet ended
 }
 
 // WithCurrency configures multi-currency normalization.
@@ -26,6 +55,33 @@ func WithCurrency(baseCurrency, dimension string, rates map[string]float64) Opti
 	}
 }
 
+// WithCurrencyMeasures marks which measures are currency amounts. Only
+// these are converted and shown with a currency unit; other measures (hours,
+// quantities) pass through untouched. Without it every measure queried is
+// treated as an amount.
+func WithCurrencyMeasures(measures ...string) Option {
+	return func(c *config) {
+		c.CurrencyMeasures = append(c.CurrencyMeasures, measures...)
+	}
+}
+
+// isCurrencyMeasure reports whether measure is a currency amount. A
+// measure declared in a non-currency unit (WithMeasureUnit) never is.
+func (c *config) isCurrencyMeasure(measure string) bool {
+	if _, ok := c.measureUnit(measure); ok {
+		return false
+	}
+	if len(c.CurrencyMeasures) == 0 {
+		return true
+	}
+	for _, m := range c.CurrencyMeasures {
+		if m == measure {
+			return true
+		}
+	}
+	return false
+}
+
 // WithDefaultMeasure sets the measure to aggregate when QuerySpec.Measure is empty.
 func WithDefaultMeasure(measure string) Option {
 	return func(c *config) {
@@ -33,6 +89,92 @@ func WithDefaultMeasure(measure string) Option {
 	}
 }
 
+// WithClock sets the clock used to resolve relative time ranges
+// ("last_quarter", "ytd"). Defaults to time.Now. Inject a fixed clock
+// for reproducible results and tests.
+func WithClock(now func() time.Time) Option {
+	return func(c *config) {
+		c.Clock = now
+	}
+}
+
+// WithCalendar sets the calendar used for temporal bucketing ("year",
+// "quarter", "week" groupings), relative time ranges, and growth periods.
+// Example: Calendar{FiscalYearStart: 4, WeekStart: "sunday"} — April fiscal
+// years labelled "FY26 Q1", Sunday-start weeks.
+func WithCalendar(cal Calendar) Option {
+	return func(c *config) {
+		c.Calendar = cal
+	}
+}
+
+// WithBlankLabel sets the group label for records whose group-by dimension
+// is empty. Defaults to "(blank)".
+func WithBlankLabel(label string) Option {
+	return func(c *config) {
+		c.BlankLabel = label
+	}
+}
+
+// WithExcludeBlanks drops records with an empty group-by dimension instead of
+// gathering them under the blank label.
+func WithExcludeBlanks() Option {
+	return func(c *config) {
+		c.ExcludeBlanks = true
+	}
+}
+
+// WithMultiValue marks a dimension as a delimiter-separated set
+// (e.g., labels "backend;api;urgent" with delimiter ";"). Filters match any
+// contained value; grouping counts the record in every value's group.
+func WithMultiValue(dimension, delimiter string) Option {
+	return func(c *config) {
+		if c.MultiValue == nil {
+			c.MultiValue = make(map[string]string)
+		}
+		c.MultiValue[dimension] = delimiter
+	}
+}
+
+// WithValueOrder sets the ordinal order of a dimension's values, used by the
+// "ordinal" sort (e.g., "priority": ["P1", "P2", "P3", "P4"]).
+func WithValueOrder(dimension string, values []string) Option {
+	return func(c *config) {
+		if c.ValueOrders == nil {
+			c.ValueOrders = make(map[string][]string)
+		}
+		c.ValueOrders[dimension] = values
+	}
+}
+
+// WithParallelism shards filtering, grouping and aggregation over n
+// goroutines (n <= 0 uses runtime.GOMAXPROCS). Results are identical for any
+// n; see parallel.go for how sums stay deterministic.
+func WithParallelism(n int) Option {
+	return func(c *config) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		c.Parallelism = n
+	}
+}
+
+// WithCube lets Execute answer compatible queries from a pre-built cube
+// (see BuildCube). May be given several times; the first compatible cube wins.
+func WithCube(cube *Cube) Option {
+	return func(c *config) {
+		c.Cubes = append(c.Cubes, cube)
+	}
+}
+
+// now returns the current time from the configured clock.
+func (c *config) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock()
+	}
+	return time.Now()
+}
+
 // applyOptions creates a config from functional options.
 func applyOptions(opts []Option) *config {
 	cfg := &config{
@@ -0,0 +1,450 @@
+package engine
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// ============================================================================
+// PARALLEL EXECUTION — sharded filtering, grouping and aggregation
+// ============================================================================
+// Enabled with WithParallelism(n). Work is split over index ranges:
+//
+//   Filtering / grouping — one contiguous range per worker; per-range index
+//                          lists are concatenated in range order, so the
+//                          output is identical to a sequential pass.
+//   Aggregation          — fixed shards of parallelChunk rows. Each shard
+//                          produces a partialAggregate; partials are merged
+//                          in shard order. Medians and distinct counts merge
+//                          bounded sketches (sketch.go).
+//
+// Shard boundaries depend only on the row count, never on the worker count,
+// so floating-point sums are bit-for-bit identical for any n (including 1).
+// They may differ in the last bits from a sequential (non-parallel) sum, and
+// medians of large groups are estimates of the exact sequential median.
+// ============================================================================
+
+// parallelChunk is the fixed shard size for mergeable aggregates.
+const parallelChunk = 4096
+
+// parallelFor runs fn(0..tasks-1) on up to workers goroutines.
+// Runs inline when there is only one worker or one task. A panic in fn
+// stops further tasks and is re-raised on the calling goroutine.
+func parallelFor(tasks, workers int, fn func(task int)) {
+	if workers > tasks {
+		workers = tasks
+	}
+	if workers <= 1 {
+		for t := 0; t < tasks; t++ {
+			fn(t)
+		}
+		return
+	}
+
+	var next int64 = -1
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicked interface{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			defer func() {
+				// Re-raised below, so an abort (cancellation, limits) reaches
+				// ExecuteContext instead of crashing the process
+				if r := recover(); r != nil {
+					panicOnce.Do(func() { panicked = r })
+					atomic.StoreInt64(&next, int64(tasks))
+				}
+			}()
+			for {
+				t := int(atomic.AddInt64(&next, 1))
+				if t >= tasks {
+					return
+				}
+				fn(t)
+			}
+		}()
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// splitRanges divides [0, n) into at most parts contiguous ranges.
+func splitRanges(n, parts int) [][2]int {
+	if parts > n {
+		parts = n
+	}
+	if parts < 1 {
+		parts = 1
+	}
+	ranges := make([][2]int, 0, parts)
+	size := (n + parts - 1) / parts
+	for lo := 0; lo < n; lo += size {
+		hi := lo + size
+		if hi > n {
+			hi = n
+		}
+		ranges = append(ranges, [2]int{lo, hi})
+	}
+	return ranges
+}
+
+// useParallel reports whether a pass over n rows is worth sharding.
+func useParallel(n, workers int) bool {
+	return workers > 1 && n > parallelChunk
+}
+
+// ============================================================================
+// PARALLEL FILTER & GROUP
+// ============================================================================
+
+// filterIndices returns the indices in [0, n) for which match is true.
+func filterIndices(n, workers int, match func(i int) bool) []int {
+	if !useParallel(n, workers) {
+		return filterRange(0, n, match)
+	}
+	ranges := splitRanges(n, workers)
+	parts := make([][]int, len(ranges))
+	parallelFor(len(ranges), workers, func(p int) {
+		parts[p] = filterRange(ranges[p][0], ranges[p][1], match)
+	})
+	total := 0
+	for _, p := range parts {
+		total += len(p)
+	}
+	indices := make([]int, 0, total)
+	for _, p := range parts {
+		indices = append(indices, p...)
+	}
+	return indices
+}
+
+func filterRange(lo, hi int, match func(i int) bool) []int {
+	indices := make([]int, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		if match(i) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// keyGroups is a partial grouping: keys in first-seen order and their rows.
+type keyGroups struct {
+	order   []string
+	grouped map[string][]int
+}
+
+func groupRange(view RecordView, dimension string, lo, hi int) keyGroups {
+	g := keyGroups{grouped: make(map[string][]int)}
+	for i := lo; i < hi; i++ {
+		// Multi-value dimensions put the record in every one of its groups
+		for _, key := range groupKeys(view, i, dimension) {
+			if _, exists := g.grouped[key]; !exists {
+				g.order = append(g.order, key)
+			}
+			g.grouped[key] = append(g.grouped[key], i)
+		}
+	}
+	return g
+}
+
+// groupKeyRanges groups [0, view.Len()) by string key, sharded by range.
+// Merging in range order keeps first-seen order and ascending row indices.
+func groupKeyRanges(view RecordView, dimension string, workers int) keyGroups {
+	n := view.Len()
+	if !useParallel(n, workers) {
+		return groupRange(view, dimension, 0, n)
+	}
+	ranges := splitRanges(n, workers)
+	parts := make([]keyGroups, len(ranges))
+	parallelFor(len(ranges), workers, func(p int) {
+		parts[p] = groupRange(view, dimension, ranges[p][0], ranges[p][1])
+	})
+	merged := parts[0]
+	for _, part := range parts[1:] {
+		for _, key := range part.order {
+			if _, exists := merged.grouped[key]; !exists {
+				merged.order = append(merged.order, key)
+			}
+			merged.grouped[key] = append(merged.grouped[key], part.grouped[key]...)
+		}
+	}
+	return merged
+}
+
+// codeGroups is a partial grouping by dictionary code: codes in first-seen
+// order and their rows (rows[k] belongs to order[k]).
+type codeGroups struct {
+	order []uint32
+	rows  [][]int
+	slots []int32        // code → position in order + 1 (dense lookup)
+	index map[uint32]int // code → position in order (sparse lookup)
+}
+
+// denseCodeRatio bounds the dictionary size, relative to the rows grouped,
+// for which codeGroups keeps a slot per code. Larger dictionaries (e.g. a
+// sub-group of a few rows over a million-value dimension) use a map over the
+// codes actually seen.
+const denseCodeRatio = 4
+
+func newCodeGroups(size, rows int) codeGroups {
+	if size <= denseCodeRatio*rows {
+		return codeGroups{slots: make([]int32, size)}
+	}
+	return codeGroups{index: make(map[uint32]int)}
+}
+
+// position returns the position of code in order, adding it if unseen.
+func (g *codeGroups) position(code uint32) int {
+	if g.slots != nil {
+		if s := g.slots[code]; s > 0 {
+			return int(s) - 1
+		}
+		g.slots[code] = int32(len(g.order) + 1)
+	} else {
+		if p, ok := g.index[code]; ok {
+			return p
+		}
+		g.index[code] = len(g.order)
+	}
+	g.order = append(g.order, code)
+	g.rows = append(g.rows, nil)
+	return len(g.order) - 1
+}
+
+func groupCodeRange(cv CodedView, size int, dimension string, lo, hi int) codeGroups {
+	g := newCodeGroups(size, hi-lo)
+	for i := lo; i < hi; i++ {
+		p := g.position(cv.DimensionCode(i, dimension))
+		g.rows[p] = append(g.rows[p], i)
+	}
+	return g
+}
+
+// groupCodeRanges is groupKeyRanges for a dictionary-encoded dimension.
+func groupCodeRanges(cv CodedView, dict []string, dimension string, workers int) codeGroups {
+	n := cv.Len()
+	if !useParallel(n, workers) {
+		return groupCodeRange(cv, len(dict), dimension, 0, n)
+	}
+	ranges := splitRanges(n, workers)
+	parts := make([]codeGroups, len(ranges))
+	parallelFor(len(ranges), workers, func(p int) {
+		parts[p] = groupCodeRange(cv, len(dict), dimension, ranges[p][0], ranges[p][1])
+	})
+	merged := parts[0]
+	for _, part := range parts[1:] {
+		for k, code := range part.order {
+			p := merged.position(code)
+			merged.rows[p] = append(merged.rows[p], part.rows[k]...)
+		}
+	}
+	return merged
+}
+
+// ============================================================================
+// MERGEABLE PARTIAL AGGREGATES
+// ============================================================================
+
+// partialAggregate holds the mergeable state of one shard.
+// Medians and distinct counts keep a bounded sketch (see sketch.go), only
+// collected for the aggregation that needs it. Distinct counts equal
+// DistinctMeasure; medians of more than quantileCapacity values are
+// estimates, so they may differ slightly from MedianMeasure.
+type partialAggregate struct {
+	rows      int             // rows in the shard
+	sum       float64         // Measure() over all rows, as SumMeasure
+	n         int             // rows with a value
+	sumN      float64         // sum over rows with a value, as AvgMeasure
+	min       float64         // smallest value (valid when n > 0)
+	max       float64         // largest value (valid when n > 0)
+	quantiles *quantileSketch // values, only collected for median
+	distinct  *distinctSketch // value hashes, only collected for distinct
+}
+
+func newPartialAggregate() partialAggregate {
+	return partialAggregate{min: math.Inf(1), max: math.Inf(-1)}
+}
+
+// accumulate reads rows [lo, hi) of view into the partial, with the sketch
+// aggregation needs.
+func (p *partialAggregate) accumulate(view RecordView, measure string, lo, hi int, aggregation string) {
+	switch aggregation {
+	case "median":
+		p.quantiles = newQuantileSketch()
+	case "distinct":
+		p.distinct = newDistinctSketch()
+		key := distinctKeys(view, measure)
+		for i := lo; i < hi; i++ {
+			if h, ok := key(i); ok {
+				p.distinct.add(h)
+			}
+		}
+	}
+	for i := lo; i < hi; i++ {
+		p.add(view.Measure(i, measure), HasMeasure(view, i, measure))
+	}
+}
+
+// add folds one row into the partial. Missing values count as rows only.
+func (p *partialAggregate) add(v float64, present bool) {
+	p.rows++
+	p.sum += v
+	if !present {
+		return
+	}
+	p.n++
+	p.sumN += v
+	if v < p.min {
+		p.min = v
+	}
+	if v > p.max {
+		p.max = v
+	}
+	if p.quantiles != nil {
+		p.quantiles.add(v)
+	}
+}
+
+// merge folds another shard (the next one in shard order) into p.
+func (p *partialAggregate) merge(o partialAggregate) {
+	p.rows += o.rows
+	p.sum += o.sum
+	p.n += o.n
+	p.sumN += o.sumN
+	if o.min < p.min {
+		p.min = o.min
+	}
+	if o.max > p.max {
+		p.max = o.max
+	}
+	if o.quantiles != nil {
+		if p.quantiles == nil {
+			p.quantiles = newQuantileSketch()
+		}
+		p.quantiles.merge(o.quantiles)
+	}
+	if o.distinct != nil {
+		if p.distinct == nil {
+			p.distinct = newDistinctSketch()
+		}
+		p.distinct.merge(o.distinct)
+	}
+}
+
+// result finalizes the partial for an aggregation (see aggregateView).
+func (p *partialAggregate) result(aggregation string) float64 {
+	switch aggregation {
+	case "count":
+		return float64(p.rows)
+	case "avg":
+		if p.n == 0 {
+			return 0
+		}
+		return p.sumN / float64(p.n)
+	case "max":
+		if p.n == 0 {
+			return 0
+		}
+		return p.max
+	case "min":
+		if p.n == 0 {
+			return 0
+		}
+		return p.min
+	case "median":
+		if p.quantiles == nil {
+			return 0
+		}
+		return p.quantiles.quantile(0.5)
+	case "distinct":
+		if p.distinct == nil {
+			return 0
+		}
+		return p.distinct.count()
+	default:
+		return p.sum
+	}
+}
+
+// aggregateSharded computes an aggregate over fixed parallelChunk shards.
+// The result does not depend on workers.
+func aggregateSharded(view RecordView, measure, aggregation string, workers int) float64 {
+	n := view.Len()
+	shards := (n + parallelChunk - 1) / parallelChunk
+	if shards == 0 {
+		shards = 1
+	}
+	parts := make([]partialAggregate, shards)
+	parallelFor(shards, workers, func(s int) {
+		lo := s * parallelChunk
+		hi := lo + parallelChunk
+		if hi > n {
+			hi = n
+		}
+		parts[s] = newPartialAggregate()
+		parts[s].accumulate(view, measure, lo, hi, aggregation)
+	})
+	total := newPartialAggregate()
+	for _, p := range parts {
+		total.merge(p)
+	}
+	return total.result(aggregation)
+}
+
+// aggregateGroups aggregates every group (and subgroup). With parallelism,
+// large groups are sharded one at a time; small groups are spread over the
+// workers and aggregated sequentially, one group per task.
+func aggregateGroups(groups []Group, measure, aggregation string, workers int) {
+	if workers <= 0 {
+		for i := range groups {
+			aggregateGroup(&groups[i], measure, aggregation)
+			for j := range groups[i].SubGroups {
+				aggregateGroup(&groups[i].SubGroups[j], measure, aggregation)
+			}
+		}
+		return
+	}
+
+	var small []*Group
+	visit := func(g *Group) {
+		if g.View.Len() > parallelChunk {
+			aggregateGroupSharded(g, measure, aggregation, workers)
+		} else {
+			small = append(small, g)
+		}
+	}
+	for i := range groups {
+		visit(&groups[i])
+		for j := range groups[i].SubGroups {
+			visit(&groups[i].SubGroups[j])
+		}
+	}
+	parallelFor(len(small), workers, func(t int) {
+		aggregateGroup(small[t], measure, aggregation)
+	})
+}
+
+func aggregateGroupSharded(group *Group, measure, aggregation string, workers int) {
+	group.Count = group.View.Len()
+	if group.Count == 0 || aggregation == "none" {
+		return
+	}
+	if aggregation == "list" {
+		aggregation = "sum" // for sorting
+	}
+	group.Value = aggregateSharded(group.View, measure, aggregation, workers)
+}
+
+// sumMeasure is SumMeasure, sharded when parallelism is enabled.
+func sumMeasure(view RecordView, measure string, workers int) float64 {
+	if workers <= 0 || view.Len() <= parallelChunk {
+		return SumMeasure(view, measure)
+	}
+	return aggregateSharded(view, measure, "sum", workers)
+}
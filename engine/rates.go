@@ -0,0 +1,335 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// DATED EXCHANGE RATES — historical rates looked up per record
+// ============================================================================
+// WithCurrency's static map converts every record at one rate. For
+// multi-year data, load a rate history and convert each record at the rate
+// in force on its date:
+//
+//   table, _ := engine.LoadRatesCSV(file) // date,currency,rate
+//   engine.Execute(spec, view,
+//       engine.WithCurrency("SGD", "currency", nil),
+//       engine.WithDatedRates(table, "date", engine.RatePrevious))
+//
+// RatePrevious uses the latest rate dated on or before the start of the
+// record's period; RateAverage averages the rates dated within the record's
+// period ("2024-03" → every March 2024 rate), falling back to the previous
+// rate when the period has none. Records without a usable date, or dated
+// before a currency's first rate, fall back to the static WithCurrency map.
+// Result.RateDates reports which rate dates were applied.
+// ============================================================================
+
+// RateMode selects how a record's date picks a rate.
+type RateMode string
+
+const (
+	RatePrevious RateMode = "previous" // nearest rate on or before the record date
+	RateAverage  RateMode = "average"  // mean of the rates within the record's period
+)
+
+// DatedRate is one row of a rate history: 1 Currency = Rate base units on Date.
+type DatedRate struct {
+	Date     time.Time `json:"date"`
+	Currency string    `json:"currency"`
+	Rate     float64   `json:"rate"`
+}
+
+// RateTable is an immutable, time-indexed rate history.
+type RateTable struct {
+	rates       map[string][]DatedRate // currency → sorted by date
+	fingerprint string
+}
+
+// NewRateTable indexes rates by currency and date. Non-positive rates are
+// ignored; a later duplicate (same currency and date) replaces an earlier one.
+func NewRateTable(rates []DatedRate) *RateTable {
+	byKey := make(map[string]DatedRate, len(rates))
+	for _, r := range rates {
+		if r.Currency == "" || r.Rate <= 0 {
+			continue
+		}
+		byKey[r.Currency+"\x00"+r.Date.Format(time.RFC3339)] = r
+	}
+
+	t := &RateTable{rates: make(map[string][]DatedRate)}
+	for _, r := range byKey {
+		t.rates[r.Currency] = append(t.rates[r.Currency], r)
+	}
+	h := sha256.New()
+	for _, c := range t.Currencies() {
+		series := t.rates[c]
+		sort.Slice(series, func(i, j int) bool { return series[i].Date.Before(series[j].Date) })
+		for _, r := range series {
+			fmt.Fprintf(h, "%s,%s,%g\n", r.Currency, r.Date.Format(time.RFC3339), r.Rate)
+		}
+	}
+	t.fingerprint = hex.EncodeToString(h.Sum(nil))
+	return t
+}
+
+// LoadRatesCSV reads a rate history in the "date,currency,rate" layout. A
+// header row is optional; when present, its date/currency/rate columns may
+// appear in any order. Dates accept the formats temporal dimensions do.
+func LoadRatesCSV(r io.Reader) (*RateTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates CSV: %w", err)
+	}
+
+	cols := [3]int{0, 1, 2}
+	first := 1 // line number of rows[0]
+	if len(rows) > 0 && isRateHeader(rows[0]) {
+		cols = [3]int{-1, -1, -1}
+		for i, name := range rows[0] {
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "date":
+				cols[0] = i
+			case "currency":
+				cols[1] = i
+			case "rate":
+				cols[2] = i
+			}
+		}
+		if cols[0] < 0 || cols[1] < 0 || cols[2] < 0 {
+			return nil, fmt.Errorf("rates CSV header must name date, currency and rate columns")
+		}
+		rows = rows[1:]
+		first = 2
+	}
+
+	var rates []DatedRate
+	for n, row := range rows {
+		line := n + first
+		if len(row) == 1 && strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+		for _, c := range cols {
+			if c >= len(row) {
+				return nil, fmt.Errorf("rates CSV row %d: expected date, currency and rate", line)
+			}
+		}
+		date, _, ok := ParseTemporalValue(row[cols[0]])
+		if !ok {
+			return nil, fmt.Errorf("rates CSV row %d: invalid date %q", line, row[cols[0]])
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(row[cols[2]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("rates CSV row %d: invalid rate %q: %w", line, row[cols[2]], err)
+		}
+		rates = append(rates, DatedRate{Date: date, Currency: strings.TrimSpace(row[cols[1]]), Rate: rate})
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("rates CSV has no rates")
+	}
+	return NewRateTable(rates), nil
+}
+
+// isRateHeader reports whether row is a header: its last field is not a number.
+func isRateHeader(row []string) bool {
+	if len(row) < 3 {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.TrimSpace(row[2]), 64)
+	return err != nil
+}
+
+// Fingerprint identifies the table's contents ("" for a nil table).
+func (t *RateTable) Fingerprint() string {
+	if t == nil {
+		return ""
+	}
+	return t.fingerprint
+}
+
+// Currencies returns the currencies with at least one rate, sorted.
+func (t *RateTable) Currencies() []string {
+	out := make([]string, 0, len(t.rates))
+	for c := range t.rates {
+		out = append(out, c)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Rates returns a currency's history, oldest first.
+func (t *RateTable) Rates(currency string) []DatedRate {
+	return append([]DatedRate(nil), t.rates[currency]...)
+}
+
+// Lookup returns the rate for currency over the period [start, end) and a
+// label naming the rate date(s) used.
+func (t *RateTable) Lookup(currency string, start, end time.Time, mode RateMode) (float64, string, bool) {
+	series := t.rates[currency]
+	if len(series) == 0 {
+		return 0, "", false
+	}
+	if mode == RateAverage {
+		from := sort.Search(len(series), func(i int) bool { return !series[i].Date.Before(start) })
+		to := sort.Search(len(series), func(i int) bool { return !series[i].Date.Before(end) })
+		if to > from {
+			sum := 0.0
+			for _, r := range series[from:to] {
+				sum += r.Rate
+			}
+			label := formatRateDate(series[from].Date)
+			if to-from > 1 {
+				label += ".." + formatRateDate(series[to-1].Date)
+			}
+			return sum / float64(to-from), label, true
+		}
+	}
+	// Latest rate dated on or before start
+	i := sort.Search(len(series), func(i int) bool { return series[i].Date.After(start) })
+	if i == 0 {
+		return 0, "", false
+	}
+	r := series[i-1]
+	return r.Rate, formatRateDate(r.Date), true
+}
+
+func formatRateDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// WithDatedRates converts each record at the rate in force on its date,
+// read from timeDimension ("" = the calendar's temporal dimension). Base
+// currency and currency dimension come from WithCurrency, whose static
+// rates remain the fallback.
+func WithDatedRates(table *RateTable, timeDimension string, mode RateMode) Option {
+	return func(c *config) {
+		c.RateTable = table
+		c.RateDimension = timeDimension
+		c.RateMode = mode
+	}
+}
+
+// ============================================================================
+// CURRENCY RATES — per-record rate resolution
+// ============================================================================
+
+// currencyRates resolves the rate converting one record to the base
+// currency, from the dated table first and the static map second. Lookups
+// are memoized per (currency, date) and safe for concurrent use.
+type currencyRates struct {
+	base      string
+	dimension string
+	timeDim   string
+	static    map[string]float64
+	table     *RateTable
+	mode      RateMode
+	memo      sync.Map // currency\x00date → rateLookup
+}
+
+type rateLookup struct {
+	rate float64
+	date string // "" for a static rate
+	ok   bool
+}
+
+// currencyRates returns the configured conversion, or nil when currency
+// normalization is off.
+func (c *config) currencyRates() *currencyRates {
+	if c.BaseCurrency == "" || c.CurrencyDimension == "" || (len(c.ExchangeRates) == 0 && c.RateTable == nil) {
+		return nil
+	}
+	r := &currencyRates{base: c.BaseCurrency, dimension: c.CurrencyDimension, static: c.ExchangeRates, table: c.RateTable, mode: c.RateMode}
+	if r.table != nil {
+		r.timeDim = c.RateDimension
+		if r.timeDim == "" {
+			r.timeDim = c.Calendar.TimeDimension()
+		}
+	}
+	return r
+}
+
+// rate returns the rate for a record in currency dated date.
+func (r *currencyRates) rate(currency, date string) rateLookup {
+	if currency == "" || currency == r.base {
+		return rateLookup{}
+	}
+	if r.table == nil || date == "" {
+		return r.staticRate(currency)
+	}
+	key := currency + "\x00" + date
+	if v, ok := r.memo.Load(key); ok {
+		return v.(rateLookup)
+	}
+	l := r.staticRate(currency)
+	if start, end, ok := ParseTemporalValue(date); ok {
+		if rate, label, found := r.table.Lookup(currency, start, end, r.mode); found {
+			l = rateLookup{rate: rate, date: label, ok: true}
+		}
+	}
+	r.memo.Store(key, l)
+	return l
+}
+
+func (r *currencyRates) staticRate(currency string) rateLookup {
+	rate, ok := r.static[currency]
+	return rateLookup{rate: rate, ok: ok && rate > 0}
+}
+
+// record returns the rate for row i of view.
+func (r *currencyRates) record(view RecordView, i int) rateLookup {
+	date := ""
+	if r.table != nil {
+		date = view.Dimension(i, r.timeDim)
+	}
+	return r.rate(view.Dimension(i, r.dimension), date)
+}
+
+// knows reports whether currency has any rate.
+func (r *currencyRates) knows(currency string) bool {
+	if rate, ok := r.static[currency]; ok && rate > 0 {
+		return true
+	}
+	return r.table != nil && len(r.table.rates[currency]) > 0
+}
+
+// datesUsed lists, per currency, the rate dates applied to view's records.
+// nil without a rate table.
+func (r *currencyRates) datesUsed(view RecordView) map[string][]string {
+	if r == nil || r.table == nil {
+		return nil
+	}
+	seen := make(map[string]map[string]bool)
+	for i := 0; i < view.Len(); i++ {
+		l := r.record(view, i)
+		if !l.ok || l.date == "" {
+			continue
+		}
+		currency := view.Dimension(i, r.dimension)
+		if seen[currency] == nil {
+			seen[currency] = make(map[string]bool)
+		}
+		seen[currency][l.date] = true
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(seen))
+	for currency, dates := range seen {
+		for d := range dates {
+			out[currency] = append(out[currency], d)
+		}
+		sort.Strings(out[currency])
+	}
+	return out
+}
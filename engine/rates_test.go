@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// DATED RATE TESTS
+// ============================================================================
+
+const testRatesCSV = `date,currency,rate
+2023-01-01,INR,0.02
+2023-06-01,INR,0.03
+2024-01-01,INR,0.01
+2024-01-15,INR,0.03
+2023-01-01,USD,1.5
+`
+
+func datedRecords() []Record {
+	return records([]string{"month", "currency"}, []string{"amount"},
+		row{"2023-03", "INR", 1000}, // Jan-23 rate → 20
+		row{"2023-07", "INR", 1000}, // Jun-23 rate → 30
+		row{"2024-01", "INR", 1000}, // previous: Jan-01 → 10; average: 0.02 → 20
+		row{"2022-12", "INR", 1000}, // before the history → static 0.05 → 50
+		row{"2023-05", "USD", 10},   // 15
+		row{"2023-05", "SGD", 5},    // base
+	)
+}
+
+func TestLoadRatesCSV(t *testing.T) {
+	table, err := LoadRatesCSV(strings.NewReader(testRatesCSV))
+	if err != nil {
+		t.Fatalf("LoadRatesCSV failed: %v", err)
+	}
+	if got := table.Currencies(); !reflect.DeepEqual(got, []string{"INR", "USD"}) {
+		t.Errorf("currencies = %v", got)
+	}
+	if n := len(table.Rates("INR")); n != 4 {
+		t.Errorf("INR rates = %d, want 4", n)
+	}
+
+	reordered, err := LoadRatesCSV(strings.NewReader("rate,date,currency\n0.02,2023-01-01,INR\n"))
+	if err != nil || len(reordered.Rates("INR")) != 1 {
+		t.Errorf("reordered header: %v", err)
+	}
+	headerless, err := LoadRatesCSV(strings.NewReader("2023-01-01,INR,0.02\n"))
+	if err != nil || headerless.Fingerprint() != reordered.Fingerprint() {
+		t.Errorf("headerless: %v", err)
+	}
+
+	for _, bad := range []string{"", "date,currency,rate\n", "2023-13-45,INR,0.02\n", "date,currency,rate\n2023-01-01,INR,abc\n", "when,ccy,fx\n"} {
+		if _, err := LoadRatesCSV(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadRatesCSV(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestExecuteDatedRates(t *testing.T) {
+	table, _ := LoadRatesCSV(strings.NewReader(testRatesCSV))
+	view := NewSliceView(datedRecords())
+	spec := QuerySpec{Intent: "text", Aggregation: "sum", Measure: "amount"}
+	static := WithCurrency("SGD", "currency", map[string]float64{"INR": 0.05})
+
+	tests := []struct {
+		mode  RateMode
+		total float64
+		dates map[string][]string
+	}{
+		{RatePrevious, 20 + 30 + 10 + 50 + 15 + 5, map[string][]string{
+			"INR": {"2023-01-01", "2023-06-01", "2024-01-01"}, "USD": {"2023-01-01"}}},
+		{RateAverage, 20 + 30 + 20 + 50 + 15 + 5, map[string][]string{
+			"INR": {"2023-01-01", "2023-06-01", "2024-01-01..2024-01-15"}, "USD": {"2023-01-01"}}},
+	}
+	for _, tt := range tests {
+		result, err := Execute(spec, view, static, WithDatedRates(table, "month", tt.mode))
+		if err != nil {
+			t.Fatalf("%s: Execute failed: %v", tt.mode, err)
+		}
+		if got := result.Data.(*TextData).RawValue; math.Abs(got-tt.total) > 1e-9 {
+			t.Errorf("%s: total = %v, want %v", tt.mode, got, tt.total)
+		}
+		if !reflect.DeepEqual(result.RateDates, tt.dates) {
+			t.Errorf("%s: rateDates = %v", tt.mode, result.RateDates)
+		}
+	}
+}
+
+func TestStreamDatedRates(t *testing.T) {
+	table, _ := LoadRatesCSV(strings.NewReader(testRatesCSV))
+	spec := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "amount", GroupBy: []string{"currency"}, Visualize: "bar"}
+	opts := []Option{WithCurrency("SGD", "currency", map[string]float64{"INR": 0.05}), WithDatedRates(table, "", RatePrevious)}
+
+	want, err := Execute(spec, NewSliceView(datedRecords()), opts...)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	stream, err := NewStreamExecutor(spec, opts...)
+	if err != nil {
+		t.Fatalf("NewStreamExecutor failed: %v", err)
+	}
+	for _, rec := range datedRecords() {
+		stream.Add(rec)
+	}
+	got, err := stream.Result()
+	if err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.ChartConfig, want.ChartConfig) || !reflect.DeepEqual(got.RateDates, want.RateDates) {
+		t.Errorf("stream = %+v / %v, want %+v / %v", got.ChartConfig, got.RateDates, want.ChartConfig, want.RateDates)
+	}
+}
@@ -0,0 +1,296 @@
+package engine
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// ============================================================================
+// SKETCHES — bounded, mergeable state for distinct counts and quantiles
+// ============================================================================
+// Sharded aggregation (see parallel.go) merges one partial per shard. Sums,
+// counts, min and max merge exactly in constant space; distinct counts and
+// quantiles would need every value, so partials keep a sketch instead:
+//
+//   distinctSketch  exact set of value hashes up to distinctExactLimit, then
+//                   a HyperLogLog (2^14 registers, ~0.8% standard error).
+//                   The state depends only on the set of values, so the
+//                   count is the same for any sharding and for DistinctMeasure.
+//   quantileSketch  a merging t-digest. Values are kept exactly up to
+//                   quantileCapacity, then compressed into at most about
+//                   quantileCompression centroids (median rank error well
+//                   under 1%). Shards merge in shard order, so the estimate
+//                   is deterministic for any worker count.
+//
+// Both use bounded memory per partial, whatever the group size.
+// ============================================================================
+
+const (
+	distinctExactLimit  = 4096
+	hllPrecision        = 14
+	quantileCapacity    = 4096
+	quantileCompression = 200
+)
+
+// ============================================================================
+// DISTINCT COUNT
+// ============================================================================
+
+// distinctSketch counts distinct 64-bit value hashes.
+type distinctSketch struct {
+	exact     map[uint64]struct{} // hashes, until distinctExactLimit
+	registers []uint8             // HyperLogLog registers once exact overflows
+}
+
+func newDistinctSketch() *distinctSketch {
+	return &distinctSketch{exact: make(map[uint64]struct{})}
+}
+
+func (s *distinctSketch) add(h uint64) {
+	if s.registers != nil {
+		s.addRegister(h)
+		return
+	}
+	s.exact[h] = struct{}{}
+	if len(s.exact) > distinctExactLimit {
+		s.toRegisters()
+	}
+}
+
+// merge folds o into s. The result equals adding o's values to s.
+func (s *distinctSketch) merge(o *distinctSketch) {
+	if o.registers != nil {
+		if s.registers == nil {
+			s.toRegisters()
+		}
+		for i, r := range o.registers {
+			if r > s.registers[i] {
+				s.registers[i] = r
+			}
+		}
+	}
+	for h := range o.exact {
+		s.add(h)
+	}
+}
+
+func (s *distinctSketch) toRegisters() {
+	s.registers = make([]uint8, 1<<hllPrecision)
+	for h := range s.exact {
+		s.addRegister(h)
+	}
+	s.exact = nil
+}
+
+func (s *distinctSketch) addRegister(h uint64) {
+	idx := h >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(h<<hllPrecision|1<<(hllPrecision-1))) + 1
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+// count returns the number of distinct values, exact below
+// distinctExactLimit and estimated above it.
+func (s *distinctSketch) count() float64 {
+	if s.registers == nil {
+		return float64(len(s.exact))
+	}
+	m := float64(len(s.registers))
+	var sum float64
+	zeros := 0
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros)) // linear counting
+	}
+	return math.Round(estimate)
+}
+
+// distinctKeys returns a function hashing row i's value of field: the
+// non-blank value when field is a dimension, otherwise the present measure
+// value. Returns false for rows without a value.
+func distinctKeys(view RecordView, field string) func(i int) (uint64, bool) {
+	for _, k := range view.DimensionKeys() {
+		if k == field {
+			return func(i int) (uint64, bool) {
+				v := view.Dimension(i, field)
+				if v == "" {
+					return 0, false
+				}
+				return hashString(v), true
+			}
+		}
+	}
+	return func(i int) (uint64, bool) {
+		if !HasMeasure(view, i, field) {
+			return 0, false
+		}
+		v := view.Measure(i, field)
+		if v == 0 {
+			v = 0 // -0 and 0 are one value
+		}
+		return mix64(math.Float64bits(v)), true
+	}
+}
+
+// hashString is FNV-1a, finished with mix64 so every bit is usable by the
+// HyperLogLog registers.
+func hashString(s string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return mix64(h)
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	return h ^ h>>31
+}
+
+// DistinctMeasure returns the number of distinct values of a field across a
+// view: non-blank values of a dimension, or present values of a measure.
+// Exact up to 4096 distinct values, a HyperLogLog estimate above.
+func DistinctMeasure(view RecordView, field string) float64 {
+	s := newDistinctSketch()
+	key := distinctKeys(view, field)
+	for i := 0; i < view.Len(); i++ {
+		if h, ok := key(i); ok {
+			s.add(h)
+		}
+	}
+	return s.count()
+}
+
+// ============================================================================
+// QUANTILES
+// ============================================================================
+
+// centroid is a t-digest centroid: the mean of weight values.
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// quantileSketch is a merging t-digest. Until quantileCapacity values every
+// centroid holds one value, so quantiles are exact.
+type quantileSketch struct {
+	centroids []centroid
+	count     float64
+	min, max  float64
+	sorted    bool
+}
+
+func newQuantileSketch() *quantileSketch {
+	return &quantileSketch{min: math.Inf(1), max: math.Inf(-1), sorted: true}
+}
+
+func (s *quantileSketch) add(v float64) {
+	s.centroids = append(s.centroids, centroid{mean: v, weight: 1})
+	s.count++
+	s.min = math.Min(s.min, v)
+	s.max = math.Max(s.max, v)
+	s.sorted = false
+	if len(s.centroids) > quantileCapacity {
+		s.compress()
+	}
+}
+
+// merge folds o into s.
+func (s *quantileSketch) merge(o *quantileSketch) {
+	if o.count == 0 {
+		return
+	}
+	s.centroids = append(s.centroids, o.centroids...)
+	s.count += o.count
+	s.min = math.Min(s.min, o.min)
+	s.max = math.Max(s.max, o.max)
+	s.sorted = false
+	if len(s.centroids) > quantileCapacity {
+		s.compress()
+	}
+}
+
+func (s *quantileSketch) sort() {
+	if s.sorted {
+		return
+	}
+	sort.Slice(s.centroids, func(a, b int) bool {
+		ca, cb := s.centroids[a], s.centroids[b]
+		if ca.mean != cb.mean {
+			return ca.mean < cb.mean
+		}
+		return ca.weight < cb.weight
+	})
+	s.sorted = true
+}
+
+// compress merges neighbouring centroids while they span at most one unit of
+// the t-digest k1 scale, which keeps centroids small near the tails.
+func (s *quantileSketch) compress() {
+	s.sort()
+	scale := func(q float64) float64 {
+		return quantileCompression / (2 * math.Pi) * math.Asin(2*math.Min(q, 1)-1)
+	}
+	out := s.centroids[:0]
+	cur := s.centroids[0]
+	before := 0.0 // weight of the centroids already emitted
+	lo := scale(0)
+	for _, c := range s.centroids[1:] {
+		if scale((before+cur.weight+c.weight)/s.count)-lo <= 1 {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+			continue
+		}
+		before += cur.weight
+		lo = scale(before / s.count)
+		out = append(out, cur)
+		cur = c
+	}
+	s.centroids = append(out, cur)
+}
+
+// quantile returns the q-quantile (0 ≤ q ≤ 1), interpolating between
+// centroid centres. With single-value centroids the median is exact, the
+// mean of the middle two values for an even count.
+func (s *quantileSketch) quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	s.sort()
+	target := q * s.count
+	cum := 0.0
+	prevCentre, prevMean := 0.0, s.min
+	for i, c := range s.centroids {
+		centre := cum + c.weight/2
+		if target <= centre {
+			if i == 0 && c.weight == 1 {
+				return c.mean
+			}
+			return interpolate(prevMean, c.mean, prevCentre, centre, target)
+		}
+		cum += c.weight
+		prevCentre, prevMean = centre, c.mean
+	}
+	if s.centroids[len(s.centroids)-1].weight == 1 {
+		return prevMean
+	}
+	return interpolate(prevMean, s.max, prevCentre, s.count, target)
+}
+
+// interpolate returns the value at x on the line through (x0, a), (x1, b).
+func interpolate(a, b, x0, x1, x float64) float64 {
+	return (a*(x1-x) + b*(x-x0)) / (x1 - x0)
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// ============================================================================
+// SKETCH TESTS
+// ============================================================================
+
+func TestDistinctSketch(t *testing.T) {
+	for _, n := range []int{10, distinctExactLimit, 50000, 300000} {
+		whole := newDistinctSketch()
+		parts := []*distinctSketch{newDistinctSketch(), newDistinctSketch(), newDistinctSketch()}
+		for i := 0; i < 2*n; i++ {
+			h := hashString(strconv.Itoa(i % n)) // every value twice
+			whole.add(h)
+			parts[i%len(parts)].add(h)
+		}
+		merged := newDistinctSketch()
+		for _, p := range parts {
+			merged.merge(p)
+		}
+		if merged.count() != whole.count() {
+			t.Errorf("n=%d: merged count %v differs from sequential %v", n, merged.count(), whole.count())
+		}
+
+		want := float64(n)
+		got := whole.count()
+		if n <= distinctExactLimit && got != want {
+			t.Errorf("n=%d: count = %v, want exactly %v", n, got, want)
+		}
+		if math.Abs(got-want)/want > 0.03 {
+			t.Errorf("n=%d: count = %v, want %v ± 3%%", n, got, want)
+		}
+	}
+}
+
+func TestQuantileSketch(t *testing.T) {
+	// Small inputs are exact, matching MedianMeasure
+	for _, values := range [][]float64{{5}, {3, 1}, {4, 1, 9}, {2, 8, 6, 4}} {
+		s := newQuantileSketch()
+		records := make([]Record, len(values))
+		for i, v := range values {
+			s.add(v)
+			records[i] = Record{Measures: map[string]float64{"x": v}}
+		}
+		if got, want := s.quantile(0.5), MedianMeasure(NewSliceView(records), "x"); got != want {
+			t.Errorf("median of %v = %v, want %v", values, got, want)
+		}
+	}
+
+	// Large inputs stay bounded and close in rank, merged or not
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 200000)
+	whole := newQuantileSketch()
+	merged := newQuantileSketch()
+	for lo := 0; lo < len(values); lo += parallelChunk {
+		part := newQuantileSketch()
+		for i := lo; i < lo+parallelChunk && i < len(values); i++ {
+			values[i] = rng.ExpFloat64() * 100
+			whole.add(values[i])
+			part.add(values[i])
+		}
+		merged.merge(part)
+	}
+	sort.Float64s(values)
+	for name, s := range map[string]*quantileSketch{"sequential": whole, "merged": merged} {
+		if len(s.centroids) > quantileCapacity {
+			t.Errorf("%s: %d centroids, want at most %d", name, len(s.centroids), quantileCapacity)
+		}
+		for _, q := range []float64{0.01, 0.25, 0.5, 0.9, 0.99} {
+			est := s.quantile(q)
+			rank := float64(sort.SearchFloat64s(values, est)) / float64(len(values))
+			if math.Abs(rank-q) > 0.01 {
+				t.Errorf("%s: q%.2f = %v has rank %.4f", name, q, est, rank)
+			}
+		}
+	}
+}
+
+func TestDistinctAggregation(t *testing.T) {
+	view := NewSliceView(largeRecords())
+	spec := QuerySpec{Intent: "text", Aggregation: "distinct", Measure: "team"}
+	result, err := Execute(spec, view)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if text := result.Data.(*TextData); text.RawValue != 4 || text.Value != "4" {
+		t.Errorf("distinct teams = %v (%q), want 4", text.RawValue, text.Value)
+	}
+
+	// A distinct count is a count, whatever the measure's unit
+	spec.Measure = "cost"
+	result, err = Execute(spec, view, WithMeasureUnit("cost", "h"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if text := result.Data.(*TextData); text.Value != FormatInt(int(text.RawValue)) {
+		t.Errorf("distinct costs = %q, want a plain count of %v", text.Value, text.RawValue)
+	}
+}
@@ -0,0 +1,523 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"unsafe"
+)
+
+// ============================================================================
+// SNAPSHOTS — versioned, checksummed on-disk datasets
+// ============================================================================
+// A snapshot stores a dataset as dictionary-encoded columns so it can be
+// reloaded without re-parsing CSV:
+//
+//   var buf bytes.Buffer
+//   engine.WriteSnapshot(&buf, view, schemaJSON)
+//   snap, err := engine.ReadSnapshot(buf.Bytes())
+//   engine.Execute(spec, snap.View)
+//
+// Layout (little-endian, every section 8-byte aligned):
+//
+//   header   64 bytes: magic "SPKTSNAP", format version, row count,
+//            manifest offset / length / CRC, header CRC
+//   sections dictionaries (uint32 length + bytes per value), codes
+//            ([]uint32), measure values ([]float64), validity bitmaps
+//            ([]uint64), caller metadata
+//   manifest JSON: keys, section offsets / lengths / CRCs, column stats
+//
+// Every section carries a CRC-32C; ReadSnapshot checks all of them, every
+// bound and every dictionary code before returning, so a corrupt or truncated
+// file yields ErrCorruptSnapshot rather than a panic or wrong answers.
+//
+// On little-endian hosts, codes and values of an 8-byte aligned buffer (a
+// memory-mapped file, or a fresh allocation) are used in place, not copied:
+// the buffer must then stay valid and unmodified while the view is in use.
+// ============================================================================
+
+// SnapshotVersion is the format version written by WriteSnapshot.
+const SnapshotVersion = 1
+
+// snapshotMagic starts every snapshot file.
+const snapshotMagic = "SPKTSNAP"
+
+const snapshotHeaderSize = 64
+
+// ErrCorruptSnapshot is returned (wrapped) when a snapshot fails validation.
+var ErrCorruptSnapshot = errors.New("corrupt snapshot")
+
+// castagnoli is the CRC-32C table (hardware-accelerated on most CPUs).
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Snapshot is a loaded dataset.
+type Snapshot struct {
+	Version uint32        // format version of the file
+	Meta    []byte        // caller metadata (e.g. schema JSON), as written
+	Stats   SnapshotStats // column statistics computed when written
+	View    *ColumnarView
+}
+
+// SnapshotStats are per-column statistics precomputed at write time.
+type SnapshotStats struct {
+	Rows       int              `json:"rows"`
+	Dimensions []DimensionStats `json:"dimensions"`
+	Measures   []MeasureStats   `json:"measures"`
+}
+
+// DimensionStats describes one dimension column.
+type DimensionStats struct {
+	Key         string `json:"key"`
+	Cardinality int    `json:"cardinality"` // distinct non-empty values
+	Blanks      int    `json:"blanks"`      // rows with an empty value
+}
+
+// MeasureStats describes one measure column. Min and Max are 0 when Count is 0.
+type MeasureStats struct {
+	Key   string  `json:"key"`
+	Count int     `json:"count"` // rows with a value
+	Nulls int     `json:"nulls"` // rows without one
+	Sum   float64 `json:"sum"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+// IsSnapshot reports whether data starts with the snapshot magic.
+func IsSnapshot(data []byte) bool {
+	return len(data) >= len(snapshotMagic) && string(data[:len(snapshotMagic)]) == snapshotMagic
+}
+
+// snapshotSection locates one section in the file.
+type snapshotSection struct {
+	Offset uint64 `json:"offset"`
+	Length uint64 `json:"length"`
+	CRC    uint32 `json:"crc"`
+}
+
+type snapshotDimension struct {
+	Key     string          `json:"key"`
+	Entries int             `json:"entries"`
+	Dict    snapshotSection `json:"dict"`
+	Codes   snapshotSection `json:"codes"`
+}
+
+type snapshotMeasure struct {
+	Key    string          `json:"key"`
+	Values snapshotSection `json:"values"`
+	Valid  snapshotSection `json:"valid"`
+}
+
+type snapshotManifest struct {
+	Dimensions []snapshotDimension `json:"dimensions"`
+	Measures   []snapshotMeasure   `json:"measures"`
+	Meta       snapshotSection     `json:"meta"`
+	Stats      SnapshotStats       `json:"stats"`
+}
+
+// ============================================================================
+// WRITE
+// ============================================================================
+
+// WriteSnapshot encodes view and caller metadata as a snapshot. Views that
+// are not columnar are encoded first; multi-value and derived calendar
+// dimensions are stored as their raw cells.
+func WriteSnapshot(w io.Writer, view RecordView, meta []byte) error {
+	cv := columnarOf(view)
+	n := cv.Len()
+	words := (n + 63) / 64
+
+	// Each section is an encoder run twice: once to measure and checksum,
+	// once to write. Nothing is buffered beyond one chunk.
+	type section struct {
+		target *snapshotSection
+		encode func(io.Writer) error
+	}
+	manifest := snapshotManifest{Stats: cv.stats()}
+	manifest.Dimensions = make([]snapshotDimension, len(cv.dims))
+	manifest.Measures = make([]snapshotMeasure, len(cv.meas))
+	var sections []section
+	for c := range cv.dims {
+		col := &cv.dims[c]
+		manifest.Dimensions[c] = snapshotDimension{Key: cv.dimKeys[c], Entries: len(col.dict)}
+		sections = append(sections,
+			section{&manifest.Dimensions[c].Dict, func(w io.Writer) error { return writeDictionary(w, col.dict) }},
+			section{&manifest.Dimensions[c].Codes, func(w io.Writer) error { return writeUint32s(w, col.codes) }})
+	}
+	for c := range cv.meas {
+		col := &cv.meas[c]
+		manifest.Measures[c] = snapshotMeasure{Key: cv.mesKeys[c]}
+		sections = append(sections,
+			section{&manifest.Measures[c].Values, func(w io.Writer) error { return writeFloat64s(w, col.values) }},
+			section{&manifest.Measures[c].Valid, func(w io.Writer) error { return writeBitmap(w, col.valid, words) }})
+	}
+	sections = append(sections, section{&manifest.Meta, func(w io.Writer) error {
+		_, err := w.Write(meta)
+		return err
+	}})
+
+	offset := uint64(snapshotHeaderSize)
+	for _, s := range sections {
+		h := crc32.New(castagnoli)
+		cw := &countingWriter{w: h}
+		if err := s.encode(cw); err != nil {
+			return fmt.Errorf("failed to encode snapshot: %w", err)
+		}
+		*s.target = snapshotSection{Offset: offset, Length: cw.n, CRC: h.Sum32()}
+		offset = align8(offset + cw.n)
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot manifest: %w", err)
+	}
+
+	header := make([]byte, snapshotHeaderSize)
+	copy(header, snapshotMagic)
+	binary.LittleEndian.PutUint32(header[8:], SnapshotVersion)
+	binary.LittleEndian.PutUint64(header[16:], uint64(n))
+	binary.LittleEndian.PutUint64(header[24:], offset)
+	binary.LittleEndian.PutUint64(header[32:], uint64(len(manifestJSON)))
+	binary.LittleEndian.PutUint32(header[40:], crc32.Checksum(manifestJSON, castagnoli))
+	binary.LittleEndian.PutUint32(header[44:], crc32.Checksum(header[:44], castagnoli))
+
+	bw := bufio.NewWriter(w)
+	out := &countingWriter{w: bw}
+	out.Write(header)
+	for _, s := range sections {
+		if err := s.encode(out); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		out.Write(make([]byte, align8(out.n)-out.n))
+	}
+	out.Write(manifestJSON)
+	if out.err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", out.err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// columnarOf returns view as a ColumnarView, encoding it when needed.
+func columnarOf(view RecordView) *ColumnarView {
+	if cv, ok := view.(*ColumnarView); ok {
+		return cv
+	}
+	dimKeys, mesKeys := view.DimensionKeys(), view.MeasureKeys()
+	b := NewColumnarBuilder(dimKeys, mesKeys)
+	dims := make([]string, len(dimKeys))
+	meas := make([]float64, len(mesKeys))
+	present := make([]bool, len(mesKeys))
+	for i := 0; i < view.Len(); i++ {
+		for c, k := range dimKeys {
+			dims[c] = view.Dimension(i, k)
+		}
+		for c, k := range mesKeys {
+			meas[c], present[c] = view.Measure(i, k), HasMeasure(view, i, k)
+		}
+		b.Append(dims, meas, present)
+	}
+	return b.Build()
+}
+
+// stats computes the column statistics stored in a snapshot.
+func (v *ColumnarView) stats() SnapshotStats {
+	s := SnapshotStats{Rows: v.n}
+	for c := range v.dims {
+		col := &v.dims[c]
+		blanks := 0
+		for _, code := range col.codes {
+			if code == 0 {
+				blanks++
+			}
+		}
+		s.Dimensions = append(s.Dimensions, DimensionStats{
+			Key: v.dimKeys[c], Cardinality: len(col.dict) - 1, Blanks: blanks,
+		})
+	}
+	for c := range v.meas {
+		col := &v.meas[c]
+		p := newPartialAggregate()
+		for i, val := range col.values {
+			p.add(val, col.isValid(i))
+		}
+		ms := MeasureStats{Key: v.mesKeys[c], Count: p.n, Nulls: v.n - p.n, Sum: p.sumN}
+		if p.n > 0 {
+			ms.Min, ms.Max = p.min, p.max
+		}
+		s.Measures = append(s.Measures, ms)
+	}
+	return s
+}
+
+// snapshotChunk is the number of values encoded per write.
+const snapshotChunk = 8192
+
+func writeDictionary(w io.Writer, dict []string) error {
+	var buf [4]byte
+	for _, val := range dict {
+		binary.LittleEndian.PutUint32(buf[:], uint32(len(val)))
+		if _, err := w.Write(buf[:]); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeUint32s(w io.Writer, values []uint32) error {
+	buf := make([]byte, 4*snapshotChunk)
+	for lo := 0; lo < len(values); lo += snapshotChunk {
+		chunk := values[lo:min(lo+snapshotChunk, len(values))]
+		for i, v := range chunk {
+			binary.LittleEndian.PutUint32(buf[4*i:], v)
+		}
+		if _, err := w.Write(buf[:4*len(chunk)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFloat64s(w io.Writer, values []float64) error {
+	buf := make([]byte, 8*snapshotChunk)
+	for lo := 0; lo < len(values); lo += snapshotChunk {
+		chunk := values[lo:min(lo+snapshotChunk, len(values))]
+		for i, v := range chunk {
+			binary.LittleEndian.PutUint64(buf[8*i:], math.Float64bits(v))
+		}
+		if _, err := w.Write(buf[:8*len(chunk)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBitmap writes exactly words words, zero-padding a short bitmap.
+func writeBitmap(w io.Writer, bitmap []uint64, words int) error {
+	var buf [8]byte
+	for i := 0; i < words; i++ {
+		var word uint64
+		if i < len(bitmap) {
+			word = bitmap[i]
+		}
+		binary.LittleEndian.PutUint64(buf[:], word)
+		if _, err := w.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countingWriter counts bytes written and keeps the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   uint64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	c.err = err
+	return n, err
+}
+
+func align8(n uint64) uint64 { return (n + 7) &^ 7 }
+
+// ============================================================================
+// READ
+// ============================================================================
+
+// ReadSnapshot validates and decodes a snapshot. Columns may alias data (see
+// the package notes above); data must not be modified while the view is used.
+func ReadSnapshot(data []byte) (*Snapshot, error) {
+	if len(data) < snapshotHeaderSize || !IsSnapshot(data) {
+		return nil, fmt.Errorf("%w: not a snapshot file", ErrCorruptSnapshot)
+	}
+	header := data[:snapshotHeaderSize]
+	if crc32.Checksum(header[:44], castagnoli) != binary.LittleEndian.Uint32(header[44:]) {
+		return nil, fmt.Errorf("%w: header checksum mismatch", ErrCorruptSnapshot)
+	}
+	version := binary.LittleEndian.Uint32(header[8:])
+	if version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (this build reads version %d)", version, SnapshotVersion)
+	}
+	rows := binary.LittleEndian.Uint64(header[16:])
+	manifestBytes, err := sectionBytes(data, snapshotSection{
+		Offset: binary.LittleEndian.Uint64(header[24:]),
+		Length: binary.LittleEndian.Uint64(header[32:]),
+		CRC:    binary.LittleEndian.Uint32(header[40:]),
+	}, "manifest")
+	if err != nil {
+		return nil, err
+	}
+	var manifest snapshotManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", ErrCorruptSnapshot, err)
+	}
+	if rows > math.MaxInt32*64 || manifest.Stats.Rows != int(rows) {
+		return nil, fmt.Errorf("%w: invalid row count %d", ErrCorruptSnapshot, rows)
+	}
+	n := int(rows)
+
+	dimKeys := make([]string, len(manifest.Dimensions))
+	mesKeys := make([]string, len(manifest.Measures))
+	for i, d := range manifest.Dimensions {
+		dimKeys[i] = d.Key
+	}
+	for i, m := range manifest.Measures {
+		mesKeys[i] = m.Key
+	}
+	v := NewColumnarBuilder(dimKeys, mesKeys).Build()
+	if len(v.dimIndex) != len(dimKeys) || len(v.mesIndex) != len(mesKeys) {
+		return nil, fmt.Errorf("%w: duplicate column keys", ErrCorruptSnapshot)
+	}
+	v.n = n
+
+	for c, d := range manifest.Dimensions {
+		raw, err := sectionBytes(data, d.Dict, "dictionary "+d.Key)
+		if err != nil {
+			return nil, err
+		}
+		dict, err := readDictionary(raw, d.Entries)
+		if err != nil {
+			return nil, fmt.Errorf("%w: dictionary %s: %v", ErrCorruptSnapshot, d.Key, err)
+		}
+		raw, err = sectionBytes(data, d.Codes, "codes "+d.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) != 4*n {
+			return nil, fmt.Errorf("%w: codes %s: %d bytes for %d rows", ErrCorruptSnapshot, d.Key, len(raw), n)
+		}
+		codes := readUint32s(raw)
+		for _, code := range codes {
+			if int(code) >= len(dict) {
+				return nil, fmt.Errorf("%w: codes %s: code %d outside dictionary", ErrCorruptSnapshot, d.Key, code)
+			}
+		}
+		v.dims[c] = dictColumn{dict: dict, codes: codes}
+	}
+
+	words := (n + 63) / 64
+	for c, m := range manifest.Measures {
+		raw, err := sectionBytes(data, m.Values, "values "+m.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) != 8*n {
+			return nil, fmt.Errorf("%w: values %s: %d bytes for %d rows", ErrCorruptSnapshot, m.Key, len(raw), n)
+		}
+		values := readFloat64s(raw)
+		raw, err = sectionBytes(data, m.Valid, "validity "+m.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) != 8*words {
+			return nil, fmt.Errorf("%w: validity %s: %d bytes for %d rows", ErrCorruptSnapshot, m.Key, len(raw), n)
+		}
+		v.meas[c] = measureColumn{values: values, valid: readUint64s(raw)}
+	}
+
+	meta, err := sectionBytes(data, manifest.Meta, "metadata")
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Version: version, Meta: meta, Stats: manifest.Stats, View: v}, nil
+}
+
+// sectionBytes bounds-checks and checksums one section.
+func sectionBytes(data []byte, s snapshotSection, name string) ([]byte, error) {
+	size := uint64(len(data))
+	if s.Offset > size || s.Length > size-s.Offset {
+		return nil, fmt.Errorf("%w: %s extends past end of file", ErrCorruptSnapshot, name)
+	}
+	raw := data[s.Offset : s.Offset+s.Length]
+	if crc32.Checksum(raw, castagnoli) != s.CRC {
+		return nil, fmt.Errorf("%w: %s checksum mismatch", ErrCorruptSnapshot, name)
+	}
+	return raw, nil
+}
+
+func readDictionary(raw []byte, entries int) ([]string, error) {
+	if entries < 1 || entries > len(raw)/4 {
+		return nil, fmt.Errorf("invalid entry count %d", entries)
+	}
+	dict := make([]string, 0, entries)
+	for len(dict) < entries {
+		if len(raw) < 4 {
+			return nil, fmt.Errorf("truncated entry %d", len(dict))
+		}
+		size := binary.LittleEndian.Uint32(raw)
+		raw = raw[4:]
+		if uint64(size) > uint64(len(raw)) {
+			return nil, fmt.Errorf("truncated entry %d", len(dict))
+		}
+		dict = append(dict, string(raw[:size]))
+		raw = raw[size:]
+	}
+	if len(raw) != 0 || dict[0] != "" {
+		return nil, fmt.Errorf("malformed dictionary")
+	}
+	return dict, nil
+}
+
+// littleEndian reports whether the host stores integers little-endian, so
+// file columns can be used in place.
+var littleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// inPlace reports whether raw can be reinterpreted as a slice of size-byte
+// words without copying.
+func inPlace(raw []byte, size uintptr) bool {
+	return littleEndian && len(raw) > 0 && uintptr(unsafe.Pointer(&raw[0]))%size == 0
+}
+
+func readUint32s(raw []byte) []uint32 {
+	n := len(raw) / 4
+	if inPlace(raw, 4) {
+		return unsafe.Slice((*uint32)(unsafe.Pointer(&raw[0])), n)
+	}
+	out := make([]uint32, n)
+	for i := range out {
+		out[i] = binary.LittleEndian.Uint32(raw[4*i:])
+	}
+	return out
+}
+
+func readUint64s(raw []byte) []uint64 {
+	n := len(raw) / 8
+	if inPlace(raw, 8) {
+		return unsafe.Slice((*uint64)(unsafe.Pointer(&raw[0])), n)
+	}
+	out := make([]uint64, n)
+	for i := range out {
+		out[i] = binary.LittleEndian.Uint64(raw[8*i:])
+	}
+	return out
+}
+
+func readFloat64s(raw []byte) []float64 {
+	n := len(raw) / 8
+	if inPlace(raw, 8) {
+		return unsafe.Slice((*float64)(unsafe.Pointer(&raw[0])), n)
+	}
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw[8*i:]))
+	}
+	return out
+}
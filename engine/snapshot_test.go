@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// ============================================================================
+// SNAPSHOT TESTS
+// ============================================================================
+
+func writeTestSnapshot(t *testing.T, view RecordView, meta string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, view, []byte(meta)); err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	records := cubeRecords()
+	view := NewSliceView(records)
+	data := writeTestSnapshot(t, view, `{"name":"tickets"}`)
+	if !IsSnapshot(data) {
+		t.Fatal("IsSnapshot = false for a written snapshot")
+	}
+
+	// Aligned (in place) and unaligned (copied) buffers decode the same
+	unaligned := make([]byte, len(data)+1)[1:]
+	copy(unaligned, data)
+	for _, buf := range [][]byte{data, unaligned} {
+		snap, err := ReadSnapshot(buf)
+		if err != nil {
+			t.Fatalf("ReadSnapshot failed: %v", err)
+		}
+		if string(snap.Meta) != `{"name":"tickets"}` || snap.Version != SnapshotVersion {
+			t.Errorf("meta = %q, version = %d", snap.Meta, snap.Version)
+		}
+		specs := []QuerySpec{
+			{Intent: "chart", Aggregation: "avg", Measure: "hours", GroupBy: []string{"team"}, Visualize: "bar"},
+			{Intent: "text", Aggregation: "min", Measure: "hours",
+				Filters: Filters{Dimensions: map[string][]string{"status": {"open"}}}},
+		}
+		for _, spec := range specs {
+			want, _ := Execute(spec, view)
+			got, _ := Execute(spec, snap.View)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %s: snapshot result differs", spec.Intent, spec.Aggregation)
+			}
+		}
+	}
+}
+
+func TestSnapshotStats(t *testing.T) {
+	view := NewColumnarView([]Record{
+		{Dimensions: map[string]string{"team": "Core"}, Measures: map[string]float64{"hours": 2}},
+		{Dimensions: map[string]string{"team": ""}, Measures: map[string]float64{"hours": -1}},
+		{Dimensions: map[string]string{"team": "Web"}, Measures: map[string]float64{}},
+	})
+	snap, err := ReadSnapshot(writeTestSnapshot(t, view, ""))
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	want := SnapshotStats{
+		Rows:       3,
+		Dimensions: []DimensionStats{{Key: "team", Cardinality: 2, Blanks: 1}},
+		Measures:   []MeasureStats{{Key: "hours", Count: 2, Nulls: 1, Sum: 1, Min: -1, Max: 2}},
+	}
+	if !reflect.DeepEqual(snap.Stats, want) {
+		t.Errorf("stats = %+v, want %+v", snap.Stats, want)
+	}
+	if snap.View.HasMeasure(2, "hours") {
+		t.Error("null measure should survive the round trip")
+	}
+}
+
+func TestSnapshotRejectsCorruption(t *testing.T) {
+	data := writeTestSnapshot(t, NewSliceView(cubeRecords()), "meta")
+
+	// A flipped byte anywhere in the header, a section or the manifest is detected
+	snap, _ := ReadSnapshot(data)
+	offsets := []int{0, 8, 16, 24, 40, 44, len(data) - 1}
+	for i := 0; i < snap.View.Len(); i += 37 {
+		offsets = append(offsets, snapshotHeaderSize+i*4)
+	}
+	for _, i := range offsets {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0x40
+		if _, err := ReadSnapshot(corrupt); err == nil {
+			t.Errorf("flipped byte %d was not detected", i)
+		}
+	}
+	for _, n := range []int{0, 8, snapshotHeaderSize, len(data) / 2, len(data) - 1} {
+		if _, err := ReadSnapshot(data[:n]); !errors.Is(err, ErrCorruptSnapshot) {
+			t.Errorf("truncated to %d bytes: err = %v, want ErrCorruptSnapshot", n, err)
+		}
+	}
+}
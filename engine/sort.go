@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// SORTING — Multi-key group ordering
+// ============================================================================
+// QuerySpec.SortBy names a single mode ("value_desc", "chronological", …).
+// QuerySpec.SortKeys, when set, takes precedence and sorts by several keys
+// in turn — e.g. by parent dimension, then by value:
+//
+//	[{"by": "dimension", "dimension": "category"}, {"by": "value", "desc": true}]
+//
+// Ordinal sorts follow a value order per dimension, configured with
+// WithValueOrder (typically from schema SortHint "P1 > P2 > P3 > P4").
+// Values missing from the order sort after the listed ones, alphabetically.
+// ============================================================================
+
+// SortKey is one key of a multi-key group sort.
+type SortKey struct {
+	By        string `json:"by"`                  // "value", "count", "label", "date", "ordinal", "dimension", "measure"
+	Dimension string `json:"dimension,omitempty"` // "ordinal" / "dimension": dimension to read (default: the grouped one)
+	Measure   string `json:"measure,omitempty"`   // "measure": sort by this measure's aggregate instead of the plotted one
+	Desc      bool   `json:"desc,omitempty"`      // descending
+}
+
+// validSortKeys lists the accepted SortKey.By values.
+var validSortKeys = map[string]bool{
+	"value": true, "count": true, "label": true, "date": true,
+	"ordinal": true, "dimension": true, "measure": true,
+}
+
+// validateSortKeys checks SortKeys before execution.
+func validateSortKeys(keys []SortKey) error {
+	for i, k := range keys {
+		if !validSortKeys[k.By] {
+			return fmt.Errorf("sortKeys[%d]: unknown sort %q", i, k.By)
+		}
+		if k.By == "measure" && k.Measure == "" {
+			return fmt.Errorf("sortKeys[%d]: sort by measure requires a measure", i)
+		}
+	}
+	return nil
+}
+
+// sortKeysFor maps a QuerySpec.SortBy mode to sort keys.
+func sortKeysFor(sortBy string) []SortKey {
+	switch sortBy {
+	case "value_desc", "amount_desc":
+		return []SortKey{{By: "value", Desc: true}}
+	case "value_asc", "amount_asc":
+		return []SortKey{{By: "value"}}
+	case "chronological", "date_asc":
+		return []SortKey{{By: "date"}}
+	case "reverse_chronological", "date_desc":
+		return []SortKey{{By: "date", Desc: true}}
+	case "label_asc", "alpha_asc":
+		return []SortKey{{By: "label"}}
+	case "label_desc":
+		return []SortKey{{By: "label", Desc: true}}
+	case "ordinal":
+		return []SortKey{{By: "ordinal"}}
+	case "ordinal_desc":
+		return []SortKey{{By: "ordinal", Desc: true}}
+	}
+	return nil // preserve grouping order
+}
+
+// groupSorter orders groups by a list of sort keys.
+type groupSorter struct {
+	keys        []SortKey
+	groupDim    string              // dimension the groups are keyed by
+	aggregation string              // aggregation for "measure" keys
+	orders      map[string][]string // dimension → ordinal value order
+}
+
+// newGroupSorter builds a sorter from a spec: SortKeys if set, else SortBy.
+func newGroupSorter(spec QuerySpec, orders map[string][]string) groupSorter {
+	s := groupSorter{
+		keys:        spec.SortKeys,
+		aggregation: spec.Aggregation,
+		orders:      orders,
+	}
+	if len(s.keys) == 0 {
+		s.keys = sortKeysFor(spec.SortBy)
+	}
+	if len(spec.GroupBy) > 0 {
+		s.groupDim = spec.GroupBy[0]
+	}
+	return s
+}
+
+// sortValue is a group's precomputed value for one key.
+type sortValue struct {
+	num float64
+	str string
+}
+
+func (s groupSorter) sort(groups []Group) {
+	if len(s.keys) == 0 || len(groups) < 2 {
+		return
+	}
+
+	// Precompute every key once per group — comparators run O(n log n) times.
+	values := make([][]sortValue, len(groups))
+	for i := range groups {
+		values[i] = make([]sortValue, len(s.keys))
+		for k, key := range s.keys {
+			values[i][k] = s.value(groups[i], key)
+		}
+	}
+
+	idx := make([]int, len(groups))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		va, vb := values[idx[a]], values[idx[b]]
+		for k, key := range s.keys {
+			c := compareSortValues(va[k], vb[k])
+			if c == 0 {
+				continue
+			}
+			if key.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	sorted := make([]Group, len(groups))
+	for i, j := range idx {
+		sorted[i] = groups[j]
+	}
+	copy(groups, sorted)
+}
+
+// value computes a group's sort value for one key.
+func (s groupSorter) value(g Group, key SortKey) sortValue {
+	switch key.By {
+	case "value":
+		return sortValue{num: g.Value}
+	case "count":
+		return sortValue{num: float64(g.Count)}
+	case "label":
+		return sortValue{str: strings.ToLower(g.Key)}
+	case "date":
+		return sortValue{num: float64(periodOrder(g.Key))}
+	case "ordinal":
+		dim := s.dimension(key)
+		return ordinalValue(s.orders[dim], s.groupValue(g, dim))
+	case "dimension":
+		return sortValue{str: strings.ToLower(s.groupValue(g, s.dimension(key)))}
+	case "measure":
+		return sortValue{num: aggregateView(g.View, key.Measure, s.aggregation)}
+	}
+	return sortValue{}
+}
+
+func (s groupSorter) dimension(key SortKey) string {
+	if key.Dimension != "" {
+		return key.Dimension
+	}
+	return s.groupDim
+}
+
+// groupValue returns a group's value for a dimension: its key when grouped by
+// that dimension, otherwise the value on its first record (e.g. the parent).
+func (s groupSorter) groupValue(g Group, dim string) string {
+	if dim == s.groupDim || g.View == nil || g.View.Len() == 0 {
+		return g.Key
+	}
+	return getDimensionValue(g.View, 0, dim)
+}
+
+// ordinalValue ranks a value within an order. Values match case-insensitively,
+// either exactly or by the longest listed prefix that ends at a word boundary
+// ("P1 - Critical" ranks as "P1"; "P10" and "P1x" do not). Unlisted values
+// rank last and fall back to alphabetical order.
+func ordinalValue(order []string, value string) sortValue {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	best, bestLen := -1, 0
+	for i, o := range order {
+		o = strings.ToLower(strings.TrimSpace(o))
+		if o == lower {
+			return sortValue{num: float64(i)}
+		}
+		if len(o) > bestLen && strings.HasPrefix(lower, o) && !continuesWord(lower[len(o):]) {
+			best, bestLen = i, len(o)
+		}
+	}
+	if best >= 0 {
+		return sortValue{num: float64(best)}
+	}
+	return sortValue{num: float64(len(order)), str: lower}
+}
+
+// continuesWord reports whether rest starts with a letter or digit, i.e.
+// the prefix before it ends mid-word.
+func continuesWord(rest string) bool {
+	r, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func compareSortValues(a, b sortValue) int {
+	switch {
+	case a.num < b.num:
+		return -1
+	case a.num > b.num:
+		return 1
+	}
+	return strings.Compare(a.str, b.str)
+}
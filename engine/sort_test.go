@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// SORT TESTS
+// ============================================================================
+
+func ticketView() RecordView {
+	return NewSliceView(records([]string{"component", "priority"}, []string{"points", "hours"},
+		row{"Backend", "P3 - Medium", 5, 2},
+		row{"Backend", "P1 - Critical", 3, 9},
+		row{"Frontend", "P2 - High", 8, 1},
+		row{"Frontend", "P4 - Low", 1, 4},
+		row{"Mobile", "P1 - Critical", 2, 3},
+		row{"Docs", "Unranked", 1, 1},
+	))
+}
+
+func groupLabels(groups []Group) string {
+	labels := make([]string, len(groups))
+	for i, g := range groups {
+		labels[i] = g.Label
+	}
+	return strings.Join(labels, ", ")
+}
+
+func TestOrdinalSortFollowsValueOrder(t *testing.T) {
+	spec := QuerySpec{GroupBy: []string{"priority"}, Aggregation: "sum", SortBy: "ordinal"}
+	sorter := newGroupSorter(spec, map[string][]string{"priority": {"P1", "P2", "P3", "P4"}})
+	groups := groupAndAggregate(ticketView(), spec.GroupBy, "points", "sum", sorter, 0, &config{})
+
+	want := "P1 - Critical, P2 - High, P3 - Medium, P4 - Low, Unranked"
+	if got := groupLabels(groups); got != want {
+		t.Errorf("ordinal order = %s, want %s", got, want)
+	}
+}
+
+func TestOrdinalPrefixStopsAtWordBoundary(t *testing.T) {
+	order := []string{"P1", "P2", "P3"}
+	ranks := map[string]float64{
+		"P1 - Critical": 0,
+		"p2: high":      1,
+		"P3":            2,
+		"P10":           3, // unlisted, not "P1"
+		"P2x":           3,
+		"P1é":           3,
+	}
+	for value, want := range ranks {
+		if got := ordinalValue(order, value).num; got != want {
+			t.Errorf("ordinalValue(%q) rank = %v, want %v", value, got, want)
+		}
+	}
+
+	// Unlisted values sort after every listed one
+	spec := QuerySpec{GroupBy: []string{"priority"}, Aggregation: "count", SortBy: "ordinal"}
+	view := NewSliceView([]Record{
+		{Dimensions: map[string]string{"priority": "P10"}},
+		{Dimensions: map[string]string{"priority": "P2 - High"}},
+		{Dimensions: map[string]string{"priority": "P1 - Critical"}},
+	})
+	groups := groupAndAggregate(view, spec.GroupBy, "", "count", newGroupSorter(spec, map[string][]string{"priority": order}), 0, &config{})
+	if got, want := groupLabels(groups), "P1 - Critical, P2 - High, P10"; got != want {
+		t.Errorf("ordinal order = %s, want %s", got, want)
+	}
+}
+
+func TestMultiKeySort(t *testing.T) {
+	// Priorities grouped, ordered by their component (parent) then by value
+	spec := QuerySpec{
+		GroupBy:     []string{"priority"},
+		Aggregation: "sum",
+		SortKeys: []SortKey{
+			{By: "dimension", Dimension: "component"},
+			{By: "value", Desc: true},
+		},
+	}
+	groups := groupAndAggregate(ticketView(), spec.GroupBy, "points", "sum", newGroupSorter(spec, nil), 0, &config{})
+	want := "P3 - Medium, P1 - Critical, Unranked, P2 - High, P4 - Low"
+	if got := groupLabels(groups); got != want {
+		t.Errorf("multi-key order = %s, want %s", got, want)
+	}
+}
+
+func TestExecuteSortByOtherMeasure(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "chart",
+		Aggregation: "sum",
+		Measure:     "points",
+		GroupBy:     []string{"component"},
+		SortKeys:    []SortKey{{By: "measure", Measure: "hours", Desc: true}},
+		Limit:       2,
+		Visualize:   "bar",
+	}
+	result, err := Execute(spec, ticketView())
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	points := result.ChartConfig.Series[0].Data
+	// Backend has the most hours (11), then Frontend (5); values plotted are points
+	if len(points) != 2 || points[0].Label != "Backend" || points[0].Value != 8 || points[1].Label != "Frontend" {
+		t.Errorf("points = %+v, want Backend(8), Frontend", points)
+	}
+}
+
+func TestExecuteInvalidSortKey(t *testing.T) {
+	spec := QuerySpec{Intent: "chart", GroupBy: []string{"component"}, SortKeys: []SortKey{{By: "vibes"}}}
+	if _, err := Execute(spec, ticketView()); err == nil {
+		t.Error("expected error for unknown sort key")
+	}
+}
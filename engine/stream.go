@@ -0,0 +1,394 @@
+package engine
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+
+	"github.com/spektr-org/spektr/observe"
+)
+
+// ============================================================================
+// STREAMING EXECUTION — single pass, bounded memory
+// ============================================================================
+// Execute needs the whole dataset as a RecordView. For files that do not fit
+// in memory, a StreamExecutor answers a QuerySpec one record at a time:
+//
+//   exec, err := engine.NewStreamExecutor(spec, opts...)
+//   for rec := range records { exec.Add(rec) }
+//   result, err := exec.Result()
+//
+// Each record is filtered as it arrives (dimension filters and time ranges)
+// and folded into a cell per distinct group tuple, holding the same mergeable
+// partials as a rollup cube. Memory grows with the number of groups, not the
+// number of records.
+//
+// Limits:
+//   - aggregations must be mergeable: sum, count, avg, min, max, list;
+//     median, growth and ratio need every value and are rejected
+//   - list results keep only the top-K records by measure (K = spec.Limit,
+//     or DefaultStreamListLimit), in measure order rather than file order
+//   - multi-value dimensions can be filtered on but not grouped by
+//   - derived calendar buckets follow the first record's dimension keys
+//   - sums are merged per cell, so they may differ from Execute in the last
+//     floating-point bits (also after currency conversion)
+// ============================================================================
+
+// DefaultStreamListLimit bounds list results when the spec sets no limit.
+const DefaultStreamListLimit = 100
+
+// streamAggregations lists the aggregations a StreamExecutor can compute.
+var streamAggregations = map[string]bool{
+	"": true, "sum": true, "count": true, "avg": true, "min": true, "max": true, "list": true,
+}
+
+// StreamExecutor computes one QuerySpec over a stream of records.
+// Not safe for concurrent use.
+type StreamExecutor struct {
+	spec    QuerySpec
+	measure string
+	cfg     *config
+
+	row  *streamRow // the record being added
+	view RecordView // row wrapped in calendar / tag views (built on first Add)
+
+	sets       map[string]map[string]bool // lowercase filter sets
+	timeRange  *DateRange
+	timeDim    string
+	timeValues map[string]bool // temporal value → in range
+
+	cells *cellBuilder
+	top   *topRecords    // list aggregation only
+	rates *currencyRates // nil without currency normalization
+
+	seen, matched int
+}
+
+// NewStreamExecutor validates spec for streaming and prepares an executor.
+// Accepts the same options as Execute, except WithParallelism and WithCube,
+// which have no effect.
+func NewStreamExecutor(spec QuerySpec, opts ...Option) (*StreamExecutor, error) {
+	cfg := applyOptions(opts)
+	if err := cfg.Calendar.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid calendar: %w", err)
+	}
+	if err := validateSortKeys(spec.SortKeys); err != nil {
+		return nil, fmt.Errorf("invalid sortKeys: %w", err)
+	}
+	if !streamAggregations[spec.Aggregation] {
+		return nil, fmt.Errorf("aggregation %q cannot be streamed; use sum, count, avg, min, max or list", spec.Aggregation)
+	}
+	if spec.CompareFilters != nil {
+		return nil, fmt.Errorf("ratio queries cannot be streamed")
+	}
+	if spec.Intent == "chart" && len(spec.Measures) > 1 {
+		return nil, fmt.Errorf("multi-measure charts cannot be streamed")
+	}
+	for _, dim := range spec.GroupBy {
+		if _, ok := cfg.MultiValue[dim]; ok {
+			return nil, fmt.Errorf("cannot stream a group by multi-value dimension %q", dim)
+		}
+	}
+
+	measure := spec.Measure
+	if measure == "" {
+		measure = cfg.DefaultMeasure
+	}
+	if measure == "" {
+		measure = "amount" // last-resort default
+	}
+
+	e := &StreamExecutor{
+		spec:    spec,
+		measure: measure,
+		cfg:     cfg,
+		row:     &streamRow{},
+		sets:    make(map[string]map[string]bool),
+	}
+	for dim, allowed := range spec.Filters.Dimensions {
+		if len(allowed) > 0 {
+			e.sets[dim] = toLowerSet(allowed)
+		}
+	}
+
+	if spec.TimeRange != nil && !spec.TimeRange.IsZero() {
+		tr := *spec.TimeRange
+		r, err := cfg.Calendar.ResolveTimeRange(tr, cfg.now())
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeRange: %w", err)
+		}
+		tr.resolved = r
+		e.spec.TimeRange = &tr
+		e.timeRange = r
+		e.timeDim = tr.Dimension
+		if e.timeDim == "" {
+			e.timeDim = cfg.Calendar.TimeDimension()
+		}
+		e.timeValues = make(map[string]bool)
+	}
+
+	// Cells keep every dimension the builders read
+	dims := append([]string(nil), spec.GroupBy...)
+	for _, k := range spec.SortKeys {
+		if k.By == "measure" {
+			return nil, fmt.Errorf("sort by another measure cannot be streamed")
+		}
+		if k.Dimension != "" {
+			dims = append(dims, k.Dimension)
+		}
+	}
+	dims = append(dims, cubeMonthDim)
+	if cfg.CurrencyDimension != "" {
+		dims = append(dims, cfg.CurrencyDimension)
+	}
+	e.rates = cfg.currencyRates()
+	if e.rates != nil && e.rates.table != nil {
+		dims = append(dims, e.rates.timeDim) // dated rates convert per cell
+	}
+	e.cells = newCellBuilder(uniqueStrings(dims), []string{measure})
+
+	if spec.Aggregation == "list" {
+		limit := spec.Limit
+		if limit <= 0 {
+			limit = DefaultStreamListLimit
+		}
+		e.top = &topRecords{limit: limit, asc: spec.SortBy == "value_asc" || spec.SortBy == "amount_asc"}
+	}
+	return e, nil
+}
+
+// Add folds one record into the result. Records kept for a list result are
+// retained, so callers must not modify rec after adding it.
+func (e *StreamExecutor) Add(rec Record) {
+	e.row.rec = rec
+	if e.view == nil {
+		e.row.cacheKeys()
+		e.view = NewTagView(NewCalendarView(e.row, e.cfg.Calendar), e.cfg.MultiValue)
+	}
+	e.seen++
+	if !e.matches() {
+		return
+	}
+	e.matched++
+	e.cells.add(e.view, 0)
+	if e.top != nil {
+		e.top.offer(rec, e.rankValue(rec), e.matched)
+	}
+}
+
+// matches applies the dimension filters and time range to the current row.
+func (e *StreamExecutor) matches() bool {
+	for dim, set := range e.sets {
+		if _, multi := e.cfg.MultiValue[dim]; multi {
+			if !matchesAny(e.view, 0, dim, set) {
+				return false
+			}
+		} else if !matchesValue(e.view, 0, dim, set) {
+			return false
+		}
+	}
+	if e.timeRange == nil {
+		return true
+	}
+	val := e.view.Dimension(0, e.timeDim)
+	in, ok := e.timeValues[val]
+	if !ok {
+		start, end, parsed := ParseTemporalValue(val)
+		in = val != "" && parsed && e.timeRange.Overlaps(start, end)
+		e.timeValues[val] = in
+	}
+	return in
+}
+
+// rankValue is the record's measure in the base currency, so list rankings
+// hold across currencies.
+func (e *StreamExecutor) rankValue(rec Record) float64 {
+	val := rec.Measures[e.measure]
+	if e.rates == nil || !e.cfg.isCurrencyMeasure(e.measure) {
+		return val
+	}
+	if l := e.rates.record(e.view, 0); l.ok {
+		return val * l.rate
+	}
+	return val
+}
+
+// Result builds the result for the records added so far. It may be called
+// more than once; adding more records afterwards is allowed. Limits.MaxGroups
+// and Limits.MaxRows apply; MaxRecords does not (the caller owns the loop).
+func (e *StreamExecutor) Result() (result *Result, err error) {
+	defer recoverAbort(&err)
+
+	if e.seen == 0 {
+		return &Result{
+			Success: true,
+			Type:    "text",
+			Reply:   "No data available to analyze.",
+		}, nil
+	}
+
+	cells := RecordView(e.cells.build())
+	var list RecordView = cells
+	if e.top != nil {
+		list = NewSliceView(e.top.sorted())
+	}
+	e.cfg.log(observe.LevelInfo, "streamed records", observe.Int("records", e.seen),
+		observe.Int("matched", e.matched), observe.Int("cells", cells.Len()))
+
+	cfg := e.cfg
+	plan := cfg.planCurrency(cells, []string{e.measure})
+	rateDates := plan.rateDates(cells)
+	if plan.converted {
+		cells = convertCells(cells, e.cells.dims, e.measure, plan.rates)
+		list = plan.apply(list)
+	}
+
+	result = answerFromCells(e.spec, cells, list, e.measure, plan.unit, cfg)
+	result.ShouldConvert = plan.converted && e.matched > 0
+	result.RateDates = rateDates
+	if e.top != nil && e.matched > e.top.limit {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"Showing the top %d of %d matching records by %s.", e.top.limit, e.matched, LabelForDimension(e.measure)))
+	}
+	if err := checkOutputRows(result, cfg.Limits); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// convertCells rescales each cell's partials to the base currency and
+// relabels converted cells, as CurrencyView does per record.
+func convertCells(cells RecordView, dims []string, measure string, rates *currencyRates) RecordView {
+	measKeys := cellMeasureKeys([]string{measure})
+	b := NewColumnarBuilder(dims, measKeys)
+	tuple := make([]string, len(dims))
+	values := make([]float64, len(measKeys))
+	present := make([]bool, len(measKeys))
+	for i := 0; i < cells.Len(); i++ {
+		l := rates.record(cells, i)
+		for d, dim := range dims {
+			tuple[d] = cells.Dimension(i, dim)
+			if dim == rates.dimension && l.ok {
+				tuple[d] = rates.base
+			}
+		}
+		for k, key := range measKeys {
+			values[k], present[k] = cells.Measure(i, key), HasMeasure(cells, i, key)
+			if k > 0 && k != 2 && l.ok { // rows and counts are not amounts
+				values[k] *= l.rate
+			}
+		}
+		b.Append(tuple, values, present)
+	}
+	return b.Build()
+}
+
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	out := items[:0]
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// ============================================================================
+// STREAM ROW — the current record as a one-row view
+// ============================================================================
+
+type streamRow struct {
+	rec     Record
+	dimKeys []string
+	mesKeys []string
+}
+
+// cacheKeys fixes the view's keys from the current (first) record.
+func (v *streamRow) cacheKeys() {
+	for k := range v.rec.Dimensions {
+		v.dimKeys = append(v.dimKeys, k)
+	}
+	for k := range v.rec.Measures {
+		v.mesKeys = append(v.mesKeys, k)
+	}
+	sort.Strings(v.dimKeys)
+	sort.Strings(v.mesKeys)
+}
+
+func (v *streamRow) Len() int                           { return 1 }
+func (v *streamRow) Dimension(_ int, key string) string { return v.rec.Dimensions[key] }
+func (v *streamRow) Measure(_ int, key string) float64  { return v.rec.Measures[key] }
+func (v *streamRow) DimensionKeys() []string            { return v.dimKeys }
+func (v *streamRow) MeasureKeys() []string              { return v.mesKeys }
+
+func (v *streamRow) HasMeasure(_ int, key string) bool {
+	_, ok := v.rec.Measures[key]
+	return ok
+}
+
+// ============================================================================
+// TOP-K — bounded list results
+// ============================================================================
+
+// topRecords keeps the limit best records by value: largest first, or
+// smallest first when asc. Ties keep the earlier record.
+type topRecords struct {
+	limit   int
+	asc     bool
+	entries []topEntry
+}
+
+type topEntry struct {
+	rec   Record
+	value float64
+	seq   int
+}
+
+// better reports whether a ranks before b.
+func (t *topRecords) better(a, b topEntry) bool {
+	if a.value != b.value {
+		if t.asc {
+			return a.value < b.value
+		}
+		return a.value > b.value
+	}
+	return a.seq < b.seq
+}
+
+// offer adds a record if it ranks within the limit.
+func (t *topRecords) offer(rec Record, value float64, seq int) {
+	entry := topEntry{rec: rec, value: value, seq: seq}
+	if len(t.entries) < t.limit {
+		heap.Push(t, entry)
+		return
+	}
+	if t.better(entry, t.entries[0]) {
+		t.entries[0] = entry
+		heap.Fix(t, 0)
+	}
+}
+
+// sorted returns the kept records, best first.
+func (t *topRecords) sorted() []Record {
+	entries := append([]topEntry(nil), t.entries...)
+	sort.Slice(entries, func(i, j int) bool { return t.better(entries[i], entries[j]) })
+	records := make([]Record, len(entries))
+	for i, e := range entries {
+		records[i] = e.rec
+	}
+	return records
+}
+
+// heap.Interface — the root is the worst kept record.
+func (t *topRecords) Len() int           { return len(t.entries) }
+func (t *topRecords) Less(i, j int) bool { return t.better(t.entries[j], t.entries[i]) }
+func (t *topRecords) Swap(i, j int)      { t.entries[i], t.entries[j] = t.entries[j], t.entries[i] }
+func (t *topRecords) Push(x interface{}) { t.entries = append(t.entries, x.(topEntry)) }
+
+func (t *topRecords) Pop() interface{} {
+	last := t.entries[len(t.entries)-1]
+	t.entries = t.entries[:len(t.entries)-1]
+	return last
+}
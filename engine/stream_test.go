@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// STREAMING EXECUTOR TESTS
+// ============================================================================
+
+func streamAll(t *testing.T, spec QuerySpec, records []Record, opts ...Option) *Result {
+	t.Helper()
+	exec, err := NewStreamExecutor(spec, opts...)
+	if err != nil {
+		t.Fatalf("NewStreamExecutor failed: %v", err)
+	}
+	for _, rec := range records {
+		exec.Add(rec)
+	}
+	result, err := exec.Result()
+	if err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+	return result
+}
+
+func TestStreamResultsMatchExecute(t *testing.T) {
+	records := cubeRecords()
+	specs := []QuerySpec{
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, SortBy: "value_desc", Visualize: "bar"},
+		{Intent: "chart", Aggregation: "avg", Measure: "hours", GroupBy: []string{"month"}, SortBy: "chronological", Visualize: "line",
+			Filters: Filters{Dimensions: map[string][]string{"status": {"open", "BLOCKED"}}}},
+		{Intent: "chart", Aggregation: "count", Measure: "hours", GroupBy: []string{"status", "team"}, Visualize: "stacked_bar", Limit: 2},
+		{Intent: "table", Aggregation: "min", Measure: "hours", GroupBy: []string{"quarter"}, Visualize: "table"},
+		{Intent: "text", Aggregation: "max", Measure: "hours",
+			Filters: Filters{Dimensions: map[string][]string{"ticket": {"T-3", "t-40", "T-999"}}}},
+		{Intent: "text", Aggregation: "sum", Measure: "hours",
+			TimeRange: &TimeRange{Since: "Feb-2026", Until: "Mar-2026"},
+			Reply:     "{total} over {count} tickets in {period}; top {top_category}."},
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, Visualize: "pie",
+			Filters: Filters{Dimensions: map[string][]string{"team": {"nobody"}}}},
+	}
+	for _, spec := range specs {
+		for _, opts := range [][]Option{nil, {WithExcludeBlanks()}, {WithBlankLabel("Unassigned")}} {
+			opts = append(opts, WithClock(fixedClock))
+			want, err := Execute(spec, NewSliceView(records), opts...)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			got := streamAll(t, spec, records, opts...)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %s by %v: streamed result differs\n got: %+v\nwant: %+v",
+					spec.Intent, spec.Aggregation, spec.GroupBy, got, want)
+			}
+		}
+	}
+}
+
+func TestStreamConvertsCurrencies(t *testing.T) {
+	records := []Record{
+		{Dimensions: map[string]string{"category": "Food", "currency": "SGD"}, Measures: map[string]float64{"amount": 10}},
+		{Dimensions: map[string]string{"category": "Food", "currency": "USD"}, Measures: map[string]float64{"amount": 4}},
+		{Dimensions: map[string]string{"category": "Rent", "currency": "USD"}, Measures: map[string]float64{"amount": 100}},
+	}
+	opt := WithCurrency("SGD", "currency", map[string]float64{"USD": 1.25})
+	spec := QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "amount", GroupBy: []string{"category"}, Visualize: "bar"}
+
+	want, _ := Execute(spec, NewSliceView(records), opt)
+	got := streamAll(t, spec, records, opt)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("converted result differs\n got: %+v\nwant: %+v", got, want)
+	}
+	if !got.ShouldConvert || got.DisplayUnit != "SGD" {
+		t.Errorf("ShouldConvert=%v DisplayUnit=%q, want true SGD", got.ShouldConvert, got.DisplayUnit)
+	}
+}
+
+func TestStreamListKeepsTopK(t *testing.T) {
+	records := cubeRecords()
+	spec := QuerySpec{Intent: "table", Aggregation: "list", Measure: "hours", Limit: 5,
+		Filters: Filters{Dimensions: map[string][]string{"team": {"core"}}}}
+	result := streamAll(t, spec, records)
+
+	rows := result.TableData.Rows
+	if len(rows) != 5 {
+		t.Fatalf("list kept %d rows, want 5", len(rows))
+	}
+	var values []float64
+	for _, rec := range records {
+		if rec.Dimensions["team"] == "Core" {
+			values = append(values, rec.Measures["hours"])
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(values)))
+	last := len(result.TableData.Columns) - 1
+	for i, row := range rows {
+		if want := fmt.Sprintf("%.2f", values[i]); row[last] != want {
+			t.Errorf("row %d = %s, want %s", i, row[last], want)
+		}
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "top 5 of 100") {
+		t.Errorf("warnings = %v, want a truncation note", result.Warnings)
+	}
+}
+
+func TestStreamRejectsNonMergeableQueries(t *testing.T) {
+	specs := []QuerySpec{
+		{Intent: "text", Aggregation: "median", Measure: "hours"},
+		{Intent: "text", Aggregation: "growth", Measure: "hours"},
+		{Intent: "text", Aggregation: "ratio", Measure: "hours", CompareFilters: &Filters{}},
+		{Intent: "chart", Aggregation: "sum", Measures: []string{"hours", "cost"}, GroupBy: []string{"team"}},
+		{Intent: "chart", Aggregation: "sum", GroupBy: []string{"labels"}},
+		{Intent: "chart", Aggregation: "sum", GroupBy: []string{"team"}, SortKeys: []SortKey{{By: "measure", Measure: "cost"}}},
+	}
+	for _, spec := range specs {
+		if _, err := NewStreamExecutor(spec, WithMultiValue("labels", ";")); err == nil {
+			t.Errorf("%s %s by %v should be rejected", spec.Intent, spec.Aggregation, spec.GroupBy)
+		}
+	}
+}
@@ -13,17 +13,21 @@ import (
 
 // BuildTable produces a TableData from a QuerySpec, groups, filtered view, and display unit.
 func BuildTable(spec QuerySpec, groups []Group, view RecordView, measure string, unit string) *TableData {
+	return buildTable(spec, groups, view, measure, classicFormat(unit))
+}
+
+func buildTable(spec QuerySpec, groups []Group, view RecordView, measure string, f valueFormat) *TableData {
 	if spec.Aggregation == "list" {
-		return buildListTable(spec, view, measure, unit)
+		return buildListTable(spec, view, measure, f)
 	}
-	return buildAggregatedTable(spec, groups, measure, unit)
+	return buildAggregatedTable(spec, groups, measure, f)
 }
 
 // ============================================================================
 // LIST TABLE — Row per record
 // ============================================================================
 
-func buildListTable(spec QuerySpec, view RecordView, measure string, unit string) *TableData {
+func buildListTable(spec QuerySpec, view RecordView, measure string, f valueFormat) *TableData {
 	if view.Len() == 0 {
 		return &TableData{
 			Title:   spec.Title,
@@ -51,6 +55,7 @@ func buildListTable(spec QuerySpec, view RecordView, measure string, unit string
 		Label: LabelForDimension(measure),
 		Type:  "number",
 		Align: "right",
+		Unit:  unitInfo(f.unit),
 	})
 
 	// Build rows
@@ -63,7 +68,7 @@ func buildListTable(spec QuerySpec, view RecordView, measure string, unit string
 			row = append(row, view.Dimension(i, key))
 		}
 		val := view.Measure(i, measure)
-		row = append(row, fmt.Sprintf("%.2f", val))
+		row = append(row, f.cell(val))
 		rows = append(rows, row)
 		total += val
 	}
@@ -75,7 +80,7 @@ func buildListTable(spec QuerySpec, view RecordView, measure string, unit string
 		Summary: &Summary{
 			Label: fmt.Sprintf("Total (%d records)", view.Len()),
 			Values: map[string]string{
-				measure: FormatCurrency(total, unit),
+				measure: f.value(total),
 			},
 		},
 	}
@@ -85,7 +90,7 @@ func buildListTable(spec QuerySpec, view RecordView, measure string, unit string
 // AGGREGATED TABLE — Summary rows
 // ============================================================================
 
-func buildAggregatedTable(spec QuerySpec, groups []Group, measure string, unit string) *TableData {
+func buildAggregatedTable(spec QuerySpec, groups []Group, measure string, f valueFormat) *TableData {
 	if len(groups) == 0 {
 		return &TableData{
 			Title:   spec.Title,
@@ -99,11 +104,12 @@ func buildAggregatedTable(spec QuerySpec, groups []Group, measure string, unit s
 		groupLabel = LabelForDimension(spec.GroupBy[0])
 	}
 	valueLabel := LabelForAggregation(spec.Aggregation)
+	valueFormat := f.forAggregation(spec.Aggregation)
 
 	columns := []Column{
 		{Key: "group", Label: groupLabel, Type: "text", Align: "left"},
-		{Key: "value", Label: valueLabel, Type: "number", Align: "right"},
-		{Key: "count", Label: "Count", Type: "number", Align: "center"},
+		{Key: "value", Label: valueLabel, Type: "number", Align: "right", Unit: unitInfo(valueFormat.unit)},
+		{Key: "count", Label: "Count", Type: "number", Align: "center", Unit: unitInfo(countUnit.Name)},
 	}
 
 	rows := make([][]string, 0, len(groups))
@@ -112,8 +118,8 @@ func buildAggregatedTable(spec QuerySpec, groups []Group, measure string, unit s
 
 	for _, g := range groups {
 		rows = append(rows, []string{
-			g.Label,
-			fmt.Sprintf("%.2f", g.Value),
+			f.groupLabel(g.Label),
+			valueFormat.cell(g.Value),
 			fmt.Sprintf("%d", g.Count),
 		})
 		totalValue += g.Value
@@ -127,7 +133,7 @@ func buildAggregatedTable(spec QuerySpec, groups []Group, measure string, unit s
 		Summary: &Summary{
 			Label: "Total",
 			Values: map[string]string{
-				"value": FormatCurrency(totalValue, unit),
+				"value": valueFormat.value(totalValue),
 				"count": fmt.Sprintf("%d", totalCount),
 			},
 		},
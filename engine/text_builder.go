@@ -13,12 +13,16 @@ import (
 
 // BuildText produces text response data from filtered records.
 func BuildText(spec QuerySpec, groups []Group, view RecordView, measure string, unit string) *TextData {
+	return buildText(spec, view, measure, classicFormat(unit))
+}
+
+func buildText(spec QuerySpec, view RecordView, measure string, f valueFormat) *TextData {
 	if view.Len() == 0 {
 		return &TextData{
 			Value:    "0",
 			RawValue: 0,
-			Unit:     unit,
-			Period:   DerivePeriod(view),
+			Unit:     f.unit,
+			Period:   f.period(DerivePeriod(view, spec.resolvedRange())),
 			Count:    0,
 		}
 	}
@@ -35,25 +39,34 @@ func BuildText(spec QuerySpec, groups []Group, view RecordView, measure string,
 		value = MaxMeasure(view, measure)
 	case "min":
 		value = MinMeasure(view, measure)
+	case "median":
+		value = MedianMeasure(view, measure)
+	case "distinct":
+		value = DistinctMeasure(view, measure)
 	case "growth":
-		return BuildGrowthText(view, measure, unit)
+		return buildGrowthText(view, measure, f, growthBucket(spec))
 	default:
 		value = SumMeasure(view, measure)
 	}
 
+	return newTextData(spec.Aggregation, value, view.Len(), DerivePeriod(view, spec.resolvedRange()), f)
+}
+
+// newTextData formats a single aggregate as TextData.
+func newTextData(aggregation string, value float64, count int, period string, f valueFormat) *TextData {
 	var formatted string
-	if spec.Aggregation == "count" {
-		formatted = FormatInt(int(value))
+	if aggregation == "count" || aggregation == "distinct" {
+		formatted = f.count(int(value))
 	} else {
-		formatted = FormatCurrency(value, unit)
+		formatted = f.value(value)
 	}
 
 	return &TextData{
 		Value:    formatted,
 		RawValue: value,
-		Unit:     unit,
-		Period:   DerivePeriod(view),
-		Count:    view.Len(),
+		Unit:     f.unit,
+		Period:   f.period(period),
+		Count:    count,
 	}
 }
 
@@ -63,34 +76,52 @@ func BuildText(spec QuerySpec, groups []Group, view RecordView, measure string,
 
 // BuildGrowthText computes growth/change metrics from chronological data.
 func BuildGrowthText(view RecordView, measure string, unit string) *TextData {
+	return buildGrowthText(view, measure, classicFormat(unit), "month")
+}
+
+// growthBucket picks the period growth compares: a calendar bucket the query
+// groups by ("quarter", "year", "week"), otherwise months.
+func growthBucket(spec QuerySpec) string {
+	if len(spec.GroupBy) > 0 {
+		for _, b := range calendarBuckets {
+			if spec.GroupBy[0] == b {
+				return b
+			}
+		}
+	}
+	return "month"
+}
+
+// buildGrowthText compares the earliest and latest periods of the bucket dimension.
+func buildGrowthText(view RecordView, measure string, f valueFormat, bucket string) *TextData {
 	if view.Len() == 0 {
 		return &TextData{
 			Value:  "No data",
-			Unit:   unit,
+			Unit:   f.unit,
 			Period: "No data",
 			Count:  0,
 		}
 	}
 
-	// Group amounts by month
+	// Group amounts by period
 	monthTotals := make(map[string]float64)
 	for i := 0; i < view.Len(); i++ {
-		month := view.Dimension(i, "month")
+		month := getDimensionValue(view, i, bucket)
 		if month == "" {
 			continue
 		}
 		monthTotals[month] += view.Measure(i, measure)
 	}
 
-	// Need at least 2 distinct months
+	// Need at least 2 distinct periods
 	if len(monthTotals) < 2 {
 		total := SumMeasure(view, measure)
 		period := DerivePeriod(view)
 		return &TextData{
-			Value:    FormatCurrency(total, unit),
+			Value:    f.value(total),
 			RawValue: total,
-			Unit:     unit,
-			Period:   period,
+			Unit:     f.unit,
+			Period:   f.period(period),
 			Count:    view.Len(),
 			Growth: &GrowthData{
 				EarliestValue:  total,
@@ -104,7 +135,7 @@ func BuildGrowthText(view RecordView, measure string, unit string) *TextData {
 		}
 	}
 
-	// Sort months chronologically
+	// Sort periods chronologically
 	type entry struct {
 		Month string
 		Order int
@@ -114,7 +145,7 @@ func BuildGrowthText(view RecordView, measure string, unit string) *TextData {
 	for m, total := range monthTotals {
 		entries = append(entries, entry{
 			Month: m,
-			Order: ParseMonthOrder(m),
+			Order: periodOrder(m),
 			Total: total,
 		})
 	}
@@ -145,9 +176,9 @@ func BuildGrowthText(view RecordView, measure string, unit string) *TextData {
 	var displayValue string
 	switch direction {
 	case "increased":
-		displayValue = fmt.Sprintf("↑ %.1f%%", absPercent)
+		displayValue = "↑ " + f.percent(absPercent)
 	case "decreased":
-		displayValue = fmt.Sprintf("↓ %.1f%%", absPercent)
+		displayValue = "↓ " + f.percent(absPercent)
 	default:
 		displayValue = "→ No change"
 	}
@@ -155,8 +186,8 @@ func BuildGrowthText(view RecordView, measure string, unit string) *TextData {
 	return &TextData{
 		Value:    displayValue,
 		RawValue: changePercent,
-		Unit:     unit,
-		Period:   fmt.Sprintf("%s – %s", earliest.Month, latest.Month),
+		Unit:     f.unit,
+		Period:   f.period(fmt.Sprintf("%s – %s", earliest.Month, latest.Month)),
 		Count:    view.Len(),
 		Growth: &GrowthData{
 			EarliestValue:  earliest.Total,
@@ -175,7 +206,15 @@ func BuildGrowthText(view RecordView, measure string, unit string) *TextData {
 // ============================================================================
 
 // DerivePeriod builds a human-readable period string from a view.
-func DerivePeriod(view RecordView) string {
+// When the query carried a TimeRange, pass the resolved range and it is
+// reported as-is — "Jul-2026 – Sep-2026" even if August has no records.
+func DerivePeriod(view RecordView, resolved ...*DateRange) string {
+	for _, r := range resolved {
+		if r != nil {
+			return r.Label
+		}
+	}
+
 	if view.Len() == 0 {
 		return "No data"
 	}
@@ -0,0 +1,383 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// TIME RANGE — Deterministic relative-date resolution
+// ============================================================================
+// The translator describes time declaratively ("last_quarter", last 30 days,
+// since March). The engine resolves the expression against its clock and
+// turns it into concrete temporal filter values — the AI never has to do
+// calendar arithmetic.
+//
+// Pipeline:
+//   1. ResolveTimeRange(timeRange, now) → DateRange [start, end)
+//   2. TimeRangeFilter(view, dimension, range) → values of the temporal
+//      dimension whose period overlaps the range
+//   3. Execute merges those values into Filters (and CompareFilters)
+// ============================================================================
+
+// defaultTimeDimension is the temporal dimension used when a TimeRange does
+// not name one. Matches the "month" convention used by growth and periods.
+const defaultTimeDimension = "month"
+
+// TimeRange is a relative or open-ended time expression in a QuerySpec.
+// Exactly one form should be set:
+//
+//	{"relative": "last_quarter"}
+//	{"last": 30, "unit": "day"}
+//	{"since": "2026-03"}                // optionally with "until"
+type TimeRange struct {
+	Dimension string `json:"dimension,omitempty"` // temporal dimension key (default: calendar dimension)
+	Relative  string `json:"relative,omitempty"`  // "today", "this_month", "last_quarter", "ytd", ...
+	Last      int    `json:"last,omitempty"`      // N most recent units, including the current one
+	Unit      string `json:"unit,omitempty"`      // "day", "week", "month", "quarter", "year"
+	Since     string `json:"since,omitempty"`     // "March", "Mar-2026", "2026-03", "2026-03-15"
+	Until     string `json:"until,omitempty"`     // inclusive end, same formats as Since
+
+	resolved *DateRange
+}
+
+// DateRange is a resolved, concrete time interval: Start inclusive, End exclusive.
+type DateRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Label string    `json:"label"`
+}
+
+// Contains reports whether t falls inside the range.
+func (r DateRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// Overlaps reports whether [start, end) intersects the range.
+func (r DateRange) Overlaps(start, end time.Time) bool {
+	return start.Before(r.End) && end.After(r.Start)
+}
+
+// IsZero returns true if no time expression is set.
+func (tr TimeRange) IsZero() bool {
+	return tr.Relative == "" && tr.Last == 0 && tr.Since == "" && tr.Until == ""
+}
+
+// ResolveTimeRange converts a TimeRange into a concrete DateRange relative to now
+// using the Gregorian calendar. See Calendar.ResolveTimeRange.
+func ResolveTimeRange(tr TimeRange, now time.Time) (*DateRange, error) {
+	return Calendar{}.ResolveTimeRange(tr, now)
+}
+
+// ResolveTimeRange converts a TimeRange into a concrete DateRange relative to now.
+// Relative ranges are calendar-aligned: "last_quarter" on 2026-10-18 is
+// Jul 1 – Oct 1 2026 (or the previous fiscal quarter for fiscal calendars).
+// {last: N, unit: U} covers the current unit plus the N-1 before it, so
+// "last 30 days" ends today (inclusive).
+func (c Calendar) ResolveTimeRange(tr TimeRange, now time.Time) (*DateRange, error) {
+	today := c.Today(now)
+
+	switch {
+	case tr.Relative != "":
+		return c.resolveRelative(strings.ToLower(strings.TrimSpace(tr.Relative)), today)
+
+	case tr.Last > 0:
+		unit := normalizeUnit(tr.Unit)
+		if unit == "" {
+			return nil, fmt.Errorf("unknown time unit %q", tr.Unit)
+		}
+		end := addUnits(c.Truncate(today, unit), unit, 1)
+		start := addUnits(end, unit, -tr.Last)
+		return &DateRange{Start: start, End: end, Label: c.rangeLabel(start, end)}, nil
+
+	case tr.Since != "" || tr.Until != "":
+		start := time.Time{}
+		end := today.AddDate(0, 0, 1)
+		if tr.Since != "" {
+			s, _, ok := parseRangeBound(tr.Since, today)
+			if !ok {
+				return nil, fmt.Errorf("cannot parse since %q", tr.Since)
+			}
+			start = s
+		}
+		if tr.Until != "" {
+			_, e, ok := parseRangeBound(tr.Until, today)
+			if !ok {
+				return nil, fmt.Errorf("cannot parse until %q", tr.Until)
+			}
+			end = e
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("time range is empty (since %q, until %q)", tr.Since, tr.Until)
+		}
+		return &DateRange{Start: start, End: end, Label: c.rangeLabel(start, end)}, nil
+	}
+
+	return nil, fmt.Errorf("time range has no expression")
+}
+
+func (c Calendar) resolveRelative(expr string, today time.Time) (*DateRange, error) {
+	var start, end time.Time
+	switch expr {
+	case "today":
+		start, end = today, today.AddDate(0, 0, 1)
+	case "yesterday":
+		start, end = today.AddDate(0, 0, -1), today
+	case "this_week", "wtd":
+		start = c.Truncate(today, "week")
+		end = today.AddDate(0, 0, 1)
+		if expr == "this_week" {
+			end = start.AddDate(0, 0, 7)
+		}
+	case "last_week":
+		end = c.Truncate(today, "week")
+		start = end.AddDate(0, 0, -7)
+	case "this_month", "mtd":
+		start = c.Truncate(today, "month")
+		end = today.AddDate(0, 0, 1)
+		if expr == "this_month" {
+			end = start.AddDate(0, 1, 0)
+		}
+	case "last_month":
+		end = c.Truncate(today, "month")
+		start = end.AddDate(0, -1, 0)
+	case "this_quarter", "qtd":
+		start = c.Truncate(today, "quarter")
+		end = today.AddDate(0, 0, 1)
+		if expr == "this_quarter" {
+			end = start.AddDate(0, 3, 0)
+		}
+	case "last_quarter":
+		end = c.Truncate(today, "quarter")
+		start = end.AddDate(0, -3, 0)
+	case "this_year", "ytd":
+		start = c.Truncate(today, "year")
+		end = today.AddDate(0, 0, 1)
+		if expr == "this_year" {
+			end = start.AddDate(1, 0, 0)
+		}
+	case "last_year":
+		end = c.Truncate(today, "year")
+		start = end.AddDate(-1, 0, 0)
+	default:
+		return nil, fmt.Errorf("unknown relative time expression %q", expr)
+	}
+	return &DateRange{Start: start, End: end, Label: c.rangeLabel(start, end)}, nil
+}
+
+// ============================================================================
+// FILTER GENERATION
+// ============================================================================
+
+// TimeRangeFilter returns the distinct values of a temporal dimension whose
+// period overlaps the range. "Mar-2026" names the whole of March, "2026-03-15"
+// a single day, "2026" a whole year. Unparseable values never match.
+func TimeRangeFilter(view RecordView, dimension string, r DateRange) []string {
+	matched := []string{}
+	seen := make(map[string]bool)
+	for i := 0; i < view.Len(); i++ {
+		val := view.Dimension(i, dimension)
+		if val == "" || seen[val] {
+			continue
+		}
+		seen[val] = true
+		if start, end, ok := ParseTemporalValue(val); ok && r.Overlaps(start, end) {
+			matched = append(matched, val)
+		}
+	}
+	return matched
+}
+
+// applyTimeRange resolves spec.TimeRange against the clock and folds the
+// result into the spec's filters. Returns the rewritten spec.
+func applyTimeRange(spec QuerySpec, view RecordView, cfg *config) (QuerySpec, error) {
+	if spec.TimeRange == nil || spec.TimeRange.IsZero() {
+		return spec, nil
+	}
+
+	tr := *spec.TimeRange
+	r, err := cfg.Calendar.ResolveTimeRange(tr, cfg.now())
+	if err != nil {
+		return spec, fmt.Errorf("invalid timeRange: %w", err)
+	}
+	tr.resolved = r
+	spec.TimeRange = &tr
+
+	dim := tr.Dimension
+	if dim == "" {
+		dim = cfg.Calendar.TimeDimension()
+	}
+	values := TimeRangeFilter(view, dim, *r)
+
+	spec.Filters = withTimeValues(spec.Filters, dim, values)
+	if spec.CompareFilters != nil {
+		cf := withTimeValues(*spec.CompareFilters, dim, values)
+		spec.CompareFilters = &cf
+	}
+	return spec, nil
+}
+
+// withTimeValues returns a copy of f restricted to the given temporal values.
+// An existing filter on the same dimension is intersected, not replaced.
+// An empty match set becomes an unsatisfiable filter so no records pass.
+func withTimeValues(f Filters, dimension string, values []string) Filters {
+	out := Filters{Dimensions: make(map[string][]string, len(f.Dimensions)+1)}
+	for k, v := range f.Dimensions {
+		out.Dimensions[k] = v
+	}
+
+	if existing := out.Dimensions[dimension]; len(existing) > 0 {
+		allowed := toLowerSet(values)
+		var kept []string
+		for _, v := range existing {
+			if allowed[strings.ToLower(v)] {
+				kept = append(kept, v)
+			}
+		}
+		values = kept
+	}
+	if len(values) == 0 {
+		values = []string{noMatchValue}
+	}
+	out.Dimensions[dimension] = values
+	return out
+}
+
+// noMatchValue is a filter value no real dimension value can equal.
+const noMatchValue = "\x00no-match"
+
+// resolvedRange returns the DateRange the engine resolved for this spec, if any.
+func (s QuerySpec) resolvedRange() *DateRange {
+	if s.TimeRange == nil {
+		return nil
+	}
+	return s.TimeRange.resolved
+}
+
+// ============================================================================
+// TEMPORAL VALUE PARSING
+// ============================================================================
+
+var quarterPattern = regexp.MustCompile(`^Q([1-4])[\s-](\d{4})$`)
+
+// ParseTemporalValue interprets a temporal dimension value as the period it
+// names. Returns [start, end) or ok=false if the value is not a recognised
+// date, month, quarter, or year.
+func ParseTemporalValue(val string) (time.Time, time.Time, bool) {
+	val = strings.TrimSpace(val)
+
+	if m := quarterPattern.FindStringSubmatch(val); m != nil {
+		q, _ := strconv.Atoi(m[1])
+		y, _ := strconv.Atoi(m[2])
+		start := time.Date(y, time.Month((q-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, 0), true
+	}
+
+	for _, f := range []string{"Jan-2006", "2006-01", "January 2006", "Jan 2006"} {
+		if t, err := time.Parse(f, val); err == nil {
+			return t, t.AddDate(0, 1, 0), true
+		}
+	}
+
+	if len(val) == 4 {
+		if t, err := time.Parse("2006", val); err == nil {
+			return t, t.AddDate(1, 0, 0), true
+		}
+	}
+
+	for _, f := range []string{"2006-01-02", "2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05", "01/02/2006", "Jan 2, 2006", "2 Jan 2006"} {
+		if t, err := time.Parse(f, val); err == nil {
+			day := startOfDay(t)
+			return day, day.AddDate(0, 0, 1), true
+		}
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// parseRangeBound parses a since/until expression into the period it names.
+// A bare month name ("March") resolves to its most recent occurrence not
+// after today.
+func parseRangeBound(val string, today time.Time) (time.Time, time.Time, bool) {
+	val = strings.TrimSpace(val)
+	if start, end, ok := ParseTemporalValue(val); ok {
+		return start, end, true
+	}
+
+	for _, f := range []string{"January", "Jan"} {
+		if t, err := time.Parse(f, val); err == nil {
+			start := time.Date(today.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+			if start.After(today) {
+				start = start.AddDate(-1, 0, 0)
+			}
+			return start, start.AddDate(0, 1, 0), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// ============================================================================
+// CALENDAR ARITHMETIC
+// ============================================================================
+
+func normalizeUnit(unit string) string {
+	switch strings.ToLower(strings.TrimSuffix(strings.TrimSpace(unit), "s")) {
+	case "day", "d":
+		return "day"
+	case "week", "w":
+		return "week"
+	case "month", "m":
+		return "month"
+	case "quarter", "q":
+		return "quarter"
+	case "year", "y":
+		return "year"
+	}
+	return ""
+}
+
+// startOfDay returns the calendar date of t as midnight UTC. Dates are compared
+// as plain calendar days so data values and the clock line up regardless of
+// the clock's location.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func addUnits(t time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	case "quarter":
+		return t.AddDate(0, 3*n, 0)
+	case "year":
+		return t.AddDate(n, 0, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// formatRangeLabel renders a range in the coarsest unit it aligns to:
+// "Jul-2026 – Sep-2026", "2025", "Sep 19, 2026 – Oct 18, 2026".
+func formatRangeLabel(start, end time.Time) string {
+	last := end.AddDate(0, 0, -1)
+	switch {
+	case start.IsZero():
+		return "Until " + last.Format("Jan 2, 2006")
+	case start.Month() == 1 && start.Day() == 1 && end.Equal(start.AddDate(1, 0, 0)):
+		return start.Format("2006")
+	case start.Day() == 1 && end.Day() == 1:
+		first := start.Format("Jan-2006")
+		lastMonth := end.AddDate(0, -1, 0).Format("Jan-2006")
+		if first == lastMonth {
+			return first
+		}
+		return fmt.Sprintf("%s – %s", first, lastMonth)
+	case end.Equal(start.AddDate(0, 0, 1)):
+		return start.Format("Jan 2, 2006")
+	}
+	return fmt.Sprintf("%s – %s", start.Format("Jan 2, 2006"), last.Format("Jan 2, 2006"))
+}
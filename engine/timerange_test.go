@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// TIME RANGE TESTS
+// ============================================================================
+
+var fixedNow = time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+
+func fixedClock() time.Time { return fixedNow }
+
+func monthlyView() RecordView {
+	months := []string{"Jun-2026", "Jul-2026", "Aug-2026", "Sep-2026", "Oct-2026"}
+	records := make([]Record, 0, len(months))
+	for i, m := range months {
+		records = append(records, Record{
+			Dimensions: map[string]string{"month": m, "category": "Expense"},
+			Measures:   map[string]float64{"amount": float64((i + 1) * 100)},
+		})
+	}
+	return NewSliceView(records)
+}
+
+func TestResolveTimeRange(t *testing.T) {
+	tests := []struct {
+		tr    TimeRange
+		start string
+		end   string
+		label string
+	}{
+		{TimeRange{Relative: "last_quarter"}, "2026-07-01", "2026-10-01", "Jul-2026 – Sep-2026"},
+		{TimeRange{Relative: "this_month"}, "2026-10-01", "2026-11-01", "Oct-2026"},
+		{TimeRange{Relative: "last_year"}, "2025-01-01", "2026-01-01", "2025"},
+		{TimeRange{Relative: "ytd"}, "2026-01-01", "2026-10-19", "Jan 1, 2026 – Oct 18, 2026"},
+		{TimeRange{Relative: "last_week"}, "2026-10-05", "2026-10-12", "Oct 5, 2026 – Oct 11, 2026"},
+		{TimeRange{Last: 30, Unit: "day"}, "2026-09-19", "2026-10-19", "Sep 19, 2026 – Oct 18, 2026"},
+		{TimeRange{Last: 3, Unit: "months"}, "2026-08-01", "2026-11-01", "Aug-2026 – Oct-2026"},
+		{TimeRange{Since: "March"}, "2026-03-01", "2026-10-19", "Mar 1, 2026 – Oct 18, 2026"},
+		{TimeRange{Since: "November"}, "2025-11-01", "2026-10-19", "Nov 1, 2025 – Oct 18, 2026"},
+		{TimeRange{Since: "2026-01", Until: "Mar-2026"}, "2026-01-01", "2026-04-01", "Jan-2026 – Mar-2026"},
+	}
+
+	for _, tt := range tests {
+		r, err := ResolveTimeRange(tt.tr, fixedNow)
+		if err != nil {
+			t.Errorf("ResolveTimeRange(%+v) error: %v", tt.tr, err)
+			continue
+		}
+		if got := r.Start.Format("2006-01-02"); got != tt.start {
+			t.Errorf("ResolveTimeRange(%+v) start = %s, want %s", tt.tr, got, tt.start)
+		}
+		if got := r.End.Format("2006-01-02"); got != tt.end {
+			t.Errorf("ResolveTimeRange(%+v) end = %s, want %s", tt.tr, got, tt.end)
+		}
+		if r.Label != tt.label {
+			t.Errorf("ResolveTimeRange(%+v) label = %q, want %q", tt.tr, r.Label, tt.label)
+		}
+	}
+}
+
+func TestResolveTimeRangeInvalid(t *testing.T) {
+	for _, tr := range []TimeRange{
+		{Relative: "next_decade"},
+		{Last: 3, Unit: "fortnight"},
+		{Since: "sometime"},
+		{Since: "2026-05", Until: "2026-02"},
+		{},
+	} {
+		if _, err := ResolveTimeRange(tr, fixedNow); err == nil {
+			t.Errorf("ResolveTimeRange(%+v) expected error", tr)
+		}
+	}
+}
+
+func TestParseTemporalValue(t *testing.T) {
+	tests := []struct {
+		val   string
+		start string
+		end   string
+	}{
+		{"Jan-2026", "2026-01-01", "2026-02-01"},
+		{"2026-03", "2026-03-01", "2026-04-01"},
+		{"Q3-2026", "2026-07-01", "2026-10-01"},
+		{"2025", "2025-01-01", "2026-01-01"},
+		{"2026-02-14", "2026-02-14", "2026-02-15"},
+	}
+	for _, tt := range tests {
+		start, end, ok := ParseTemporalValue(tt.val)
+		if !ok {
+			t.Errorf("ParseTemporalValue(%q) not parsed", tt.val)
+			continue
+		}
+		if start.Format("2006-01-02") != tt.start || end.Format("2006-01-02") != tt.end {
+			t.Errorf("ParseTemporalValue(%q) = [%s, %s), want [%s, %s)", tt.val,
+				start.Format("2006-01-02"), end.Format("2006-01-02"), tt.start, tt.end)
+		}
+	}
+	if _, _, ok := ParseTemporalValue("Sprint 17"); ok {
+		t.Error("ParseTemporalValue(\"Sprint 17\") should not parse")
+	}
+}
+
+func TestExecuteWithTimeRange(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "text",
+		Aggregation: "sum",
+		Measure:     "amount",
+		TimeRange:   &TimeRange{Relative: "last_quarter"},
+		Reply:       "Spent {total} in {period}.",
+	}
+
+	result, err := Execute(spec, monthlyView(), WithClock(fixedClock))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	text, ok := result.Data.(*TextData)
+	if !ok {
+		t.Fatalf("expected *TextData, got %T", result.Data)
+	}
+	// Jul + Aug + Sep = 200 + 300 + 400
+	if text.RawValue != 900 {
+		t.Errorf("RawValue = %v, want 900", text.RawValue)
+	}
+	if text.Period != "Jul-2026 – Sep-2026" {
+		t.Errorf("Period = %q, want resolved range label", text.Period)
+	}
+	if result.TimeRange == nil || result.TimeRange.Label != "Jul-2026 – Sep-2026" {
+		t.Errorf("Result.TimeRange = %+v, want resolved last quarter", result.TimeRange)
+	}
+	if result.Reply != "Spent 900.00 in Jul-2026 – Sep-2026" {
+		t.Errorf("Reply = %q", result.Reply)
+	}
+}
+
+func TestExecuteTimeRangeIntersectsFilters(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "text",
+		Aggregation: "sum",
+		Measure:     "amount",
+		Filters:     Filters{Dimensions: map[string][]string{"month": {"Jun-2026", "Aug-2026"}}},
+		TimeRange:   &TimeRange{Last: 3, Unit: "month"},
+	}
+
+	result, err := Execute(spec, monthlyView(), WithClock(fixedClock))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	text := result.Data.(*TextData)
+	if text.RawValue != 300 {
+		t.Errorf("RawValue = %v, want 300 (only Aug-2026 is in both)", text.RawValue)
+	}
+}
+
+func TestExecuteTimeRangeNoMatch(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "text",
+		Aggregation: "sum",
+		Measure:     "amount",
+		TimeRange:   &TimeRange{Relative: "last_year"},
+	}
+
+	result, err := Execute(spec, monthlyView(), WithClock(fixedClock))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Data != nil {
+		t.Errorf("expected no-match result, got %+v", result.Data)
+	}
+}
+
+func TestExecuteTimeRangeInvalid(t *testing.T) {
+	spec := QuerySpec{
+		Intent:      "text",
+		Aggregation: "sum",
+		TimeRange:   &TimeRange{Relative: "someday"},
+	}
+	if _, err := Execute(spec, monthlyView(), WithClock(fixedClock)); err == nil {
+		t.Error("expected error for unknown relative expression")
+	}
+}
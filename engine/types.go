@@ -30,19 +30,21 @@ type Record struct {
 // QuerySpec defines what the engine should compute.
 // The Translator (Gemini/OpenAI) produces this; the Engine consumes it.
 type QuerySpec struct {
-	Intent         string   `json:"intent"`                   // "text", "table", "chart"
-	Filters        Filters  `json:"filters"`                  // Which records to include
-	CompareFilters *Filters `json:"compareFilters,omitempty"` // For ratio: numerator filters
-	Aggregation    string   `json:"aggregation"`              // "sum", "count", "avg", "max", "min", "list", "growth", "ratio", "none"
-	Measure        string   `json:"measure"`                  // Single measure (used when Measures is empty)
-	Measures       []string `json:"measures,omitempty"`       // Multiple measures for comparison charts (one series per measure)
-	GroupBy        []string `json:"groupBy"`                  // Dimension keys: ["month"], ["category", "location"]
-	SortBy         string   `json:"sortBy"`                   // "value_desc", "value_asc", "date_asc", "date_desc", "alpha_asc"
-	Limit          int      `json:"limit"`                    // 0 = all
-	Visualize      string   `json:"visualize"`                // "bar", "line", "pie", "stacked_bar", "area", "table", "text"
-	Title          string   `json:"title"`                    // Chart/table title
-	Reply          string   `json:"reply"`                    // Template: "You spent {total} on {filter_label} in {period}."
-	Confidence     float64  `json:"confidence"`               // 0.0–1.0
+	Intent         string     `json:"intent"`                   // "text", "table", "chart"
+	Filters        Filters    `json:"filters"`                  // Which records to include
+	CompareFilters *Filters   `json:"compareFilters,omitempty"` // For ratio: numerator filters
+	TimeRange      *TimeRange `json:"timeRange,omitempty"`      // Relative time window, resolved by the engine
+	Aggregation    string     `json:"aggregation"`              // "sum", "count", "distinct", "avg", "median", "max", "min", "list", "growth", "ratio", "none"
+	Measure        string     `json:"measure"`                  // Single measure (used when Measures is empty)
+	Measures       []string   `json:"measures,omitempty"`       // Multiple measures for comparison charts (one series per measure)
+	GroupBy        []string   `json:"groupBy"`                  // Dimension keys: ["month"], ["category", "location"]
+	SortBy         string     `json:"sortBy"`                   // "value_desc", "value_asc", "date_asc", "date_desc", "alpha_asc", "ordinal"
+	SortKeys       []SortKey  `json:"sortKeys,omitempty"`       // Multi-key sort; overrides SortBy when set
+	Limit          int        `json:"limit"`                    // 0 = all
+	Visualize      string     `json:"visualize"`                // "bar", "line", "pie", "stacked_bar", "area", "table", "text"
+	Title          string     `json:"title"`                    // Chart/table title
+	Reply          string     `json:"reply"`                    // Template: "You spent {total} on {filter_label} in {period}."
+	Confidence     float64    `json:"confidence"`               // 0.0–1.0
 }
 
 // Filters define which records to include.
@@ -95,9 +97,14 @@ type Result struct {
 	Data        interface{}  `json:"data,omitempty"` // *TextData for type="text"
 
 	// Metadata
-	DisplayUnit   string   `json:"displayUnit,omitempty"`
-	ShouldConvert bool     `json:"shouldConvert"`
-	Errors        []string `json:"errors,omitempty"`
+	DisplayUnit   string              `json:"displayUnit,omitempty"`
+	ShouldConvert bool                `json:"shouldConvert"`
+	RateDates     map[string][]string `json:"rateDates,omitempty"` // currency → dated rates applied (WithDatedRates)
+	Errors        []string            `json:"errors,omitempty"`
+	Warnings      []string            `json:"warnings,omitempty"`    // Caveats, e.g. overlapping multi-value groups
+	TimeRange     *DateRange          `json:"timeRange,omitempty"`   // Resolved QuerySpec.TimeRange
+	DataVersion   uint64              `json:"dataVersion,omitempty"` // VersionedView version the result was computed on
+	Trace         *Trace              `json:"trace,omitempty"`       // How the result was computed (WithExplain)
 
 	// Pass-through for two-phase flow
 	QuerySpec      *QuerySpec      `json:"querySpec,omitempty"`
@@ -130,10 +137,12 @@ type ChartConfig struct {
 	Title      string        `json:"title"`
 	XAxis      string        `json:"xAxis,omitempty"`
 	YAxis      string        `json:"yAxis,omitempty"`
+	YAxisUnit  *Unit         `json:"yAxisUnit,omitempty"` // unit of the plotted values
 	Series     []ChartSeries `json:"series"`
 	Colors     []string      `json:"colors,omitempty"`
 	ShowLegend bool          `json:"showLegend"`
 	ShowGrid   bool          `json:"showGrid"`
+	Locale     string        `json:"locale,omitempty"` // locale for axis and tooltip formatting
 }
 
 // ChartSeries represents a data series in a chart.
@@ -165,8 +174,9 @@ type TableData struct {
 type Column struct {
 	Key   string `json:"key"`
 	Label string `json:"label"`
-	Type  string `json:"type"`  // "text", "number", "currency"
-	Align string `json:"align"` // "left", "center", "right"
+	Type  string `json:"type"`           // "text", "number", "currency"
+	Align string `json:"align"`          // "left", "center", "right"
+	Unit  *Unit  `json:"unit,omitempty"` // unit of a numeric column's values
 }
 
 // Summary provides totals or aggregations for a table.
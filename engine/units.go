@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// UNITS — conversion and display beyond currency
+// ============================================================================
+// A measure's unit says what its stored numbers mean. Five kinds:
+//
+//   duration  ms, s, min, h, d           (helpers parse "30 mins" into the declared unit)
+//   data      B, KB, MB, GB, TB          (binary: 1 KB = 1024 B)
+//   percent   %
+//   count     count, units, points
+//   currency  any other name — an ISO code ("SGD") or "currency"
+//
+// FormatValue auto-scales for display — 11520000 ms → "3.2 h",
+// 1503238554 B → "1.4 GB", 45 % → "45%" — and falls back to FormatCurrency
+// for currency, so the engine can format every value through it. Declare
+// units with WithMeasureUnit (helpers.EngineOptions reads MeasureMeta.Unit);
+// results carry them on ChartConfig.YAxisUnit and Column.Unit.
+// ============================================================================
+
+// UnitKind groups units that convert into one another.
+type UnitKind string
+
+const (
+	UnitDuration UnitKind = "duration"
+	UnitData     UnitKind = "data"
+	UnitPercent  UnitKind = "percent"
+	UnitCount    UnitKind = "count"
+	UnitCurrency UnitKind = "currency"
+)
+
+// Unit is one named unit. Factor is its size in the kind's base unit
+// (milliseconds, bytes); 1 for percent and count.
+type Unit struct {
+	Name   string   `json:"name"`
+	Kind   UnitKind `json:"kind"`
+	Factor float64  `json:"-"`
+	Symbol string   `json:"-"` // display suffix
+}
+
+var (
+	durationUnits = []Unit{
+		{"ms", UnitDuration, 1, "ms"},
+		{"s", UnitDuration, 1000, "s"},
+		{"min", UnitDuration, 60 * 1000, "min"},
+		{"h", UnitDuration, 60 * 60 * 1000, "h"},
+		{"d", UnitDuration, 24 * 60 * 60 * 1000, "d"},
+	}
+	dataUnits = []Unit{
+		{"B", UnitData, 1, "B"},
+		{"KB", UnitData, 1 << 10, "KB"},
+		{"MB", UnitData, 1 << 20, "MB"},
+		{"GB", UnitData, 1 << 30, "GB"},
+		{"TB", UnitData, 1 << 40, "TB"},
+	}
+	percentUnit = Unit{"%", UnitPercent, 1, "%"}
+	countUnit   = Unit{"count", UnitCount, 1, ""}
+	pointsUnit  = Unit{"points", UnitCount, 1, "pts"}
+
+	// unitAliases maps lowercase names to units.
+	unitAliases = map[string]Unit{
+		"ms": durationUnits[0], "millisecond": durationUnits[0], "milliseconds": durationUnits[0],
+		"s": durationUnits[1], "sec": durationUnits[1], "secs": durationUnits[1], "second": durationUnits[1], "seconds": durationUnits[1],
+		"min": durationUnits[2], "mins": durationUnits[2], "minute": durationUnits[2], "minutes": durationUnits[2],
+		"h": durationUnits[3], "hr": durationUnits[3], "hrs": durationUnits[3], "hour": durationUnits[3], "hours": durationUnits[3],
+		"d": durationUnits[4], "day": durationUnits[4], "days": durationUnits[4],
+
+		"b": dataUnits[0], "byte": dataUnits[0], "bytes": dataUnits[0],
+		"kb": dataUnits[1], "kib": dataUnits[1], "kilobytes": dataUnits[1],
+		"mb": dataUnits[2], "mib": dataUnits[2], "megabytes": dataUnits[2],
+		"gb": dataUnits[3], "gib": dataUnits[3], "gigabytes": dataUnits[3],
+		"tb": dataUnits[4], "tib": dataUnits[4], "terabytes": dataUnits[4],
+
+		"%": percentUnit, "percent": percentUnit, "percentage": percentUnit, "pct": percentUnit,
+
+		"count": countUnit, "units": countUnit, "unit": countUnit, "items": countUnit,
+		"points": pointsUnit, "pts": pointsUnit, "point": pointsUnit,
+	}
+)
+
+// LookupUnit resolves a unit name or alias ("hours", "GB", "percent").
+// Names it does not know are currencies: LookupUnit("SGD") is a currency
+// unit named "SGD". The empty name has no unit.
+func LookupUnit(name string) (Unit, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Unit{}, false
+	}
+	if u, ok := unitAliases[strings.ToLower(name)]; ok {
+		return u, true
+	}
+	return Unit{Name: name, Kind: UnitCurrency, Factor: 1}, true
+}
+
+// ConvertUnit converts value from one unit to another of the same kind
+// ("h" → "min", "MB" → "GB"). Currencies convert through WithCurrency, not
+// here.
+func ConvertUnit(value float64, from, to string) (float64, error) {
+	f, ok := LookupUnit(from)
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", from)
+	}
+	t, ok := LookupUnit(to)
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", to)
+	}
+	if f.Kind != t.Kind {
+		return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, f.Kind, to, t.Kind)
+	}
+	if f.Kind == UnitCurrency {
+		if f.Name != t.Name {
+			return 0, fmt.Errorf("cannot convert currency %s to %s without exchange rates", f.Name, t.Name)
+		}
+		return value, nil
+	}
+	return value * f.Factor / t.Factor, nil
+}
+
+// FormatValue formats value in unit for display, scaling durations and data
+// sizes to the largest unit that keeps the number at least 1. Currencies
+// and unknown units format as FormatCurrency does. Locale.FormatValue is the
+// localized form.
+func FormatValue(value float64, unit string) string {
+	u, ok := LookupUnit(unit)
+	if !ok || u.Kind == UnitCurrency {
+		return FormatCurrency(value, unit)
+	}
+	return DefaultLocale.FormatValue(value, unit, -1)
+}
+
+// unitInfo describes unit for result metadata; nil when there is none.
+func unitInfo(unit string) *Unit {
+	u, ok := LookupUnit(unit)
+	if !ok {
+		return nil
+	}
+	return &u
+}
+
+// WithMeasureUnit declares the unit a measure's stored values are in
+// ("ms", "hours", "bytes", "percent", "points"). Measures with a
+// non-currency unit are never currency-converted.
+func WithMeasureUnit(measure, unit string) Option {
+	return func(c *config) {
+		if c.MeasureUnits == nil {
+			c.MeasureUnits = make(map[string]string)
+		}
+		c.MeasureUnits[measure] = unit
+	}
+}
+
+// measureUnit returns measure's declared non-currency unit, if any.
+func (c *config) measureUnit(measure string) (Unit, bool) {
+	u, ok := LookupUnit(c.MeasureUnits[measure])
+	if !ok || u.Kind == UnitCurrency {
+		return Unit{}, false
+	}
+	return u, true
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+// ============================================================================
+// UNITS TESTS — conversion, display and result metadata
+// ============================================================================
+
+func TestConvertUnit(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{2, "h", "min", 120},
+		{90, "minutes", "hours", 1.5},
+		{1, "GB", "MB", 1024},
+		{2048, "bytes", "KB", 2},
+		{50, "%", "percent", 50},
+	}
+	for _, tt := range tests {
+		got, err := ConvertUnit(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("ConvertUnit(%v, %s, %s) error: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ConvertUnit(%v, %s, %s) = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if _, err := ConvertUnit(1, "h", "GB"); err == nil {
+		t.Error("expected error converting duration to data size")
+	}
+	if _, err := ConvertUnit(1, "SGD", "USD"); err == nil {
+		t.Error("expected error converting currencies without rates")
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value float64
+		unit  string
+		want  string
+	}{
+		{11520000, "ms", "3.2 h"},
+		{794, "ms", "794 ms"},
+		{90, "min", "1.5 h"},
+		{1503238554, "bytes", "1.4 GB"},
+		{45, "percent", "45%"},
+		{12.5, "%", "12.5%"},
+		{1234567, "units", "1,234,567"},
+		{8.25, "points", "8.25 pts"},
+		{1234.5, "SGD", "SGD 1,234.50"},
+		{-1500, "s", "-25 min"},
+	}
+	for _, tt := range tests {
+		if got := FormatValue(tt.value, tt.unit); got != tt.want {
+			t.Errorf("FormatValue(%v, %q) = %q, want %q", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestMeasureUnitMetadata(t *testing.T) {
+	view := NewSliceView(mixedCurrencyRecords())
+	opts := []Option{sgdRates, WithMeasureUnit("hours", "h")}
+
+	// A unit-bearing measure is never currency-converted
+	spec := QuerySpec{Intent: "text", Aggregation: "sum", Measure: "hours"}
+	result, err := Execute(spec, view, opts...)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	text := result.Data.(*TextData)
+	if result.ShouldConvert || text.RawValue != 100 || text.Value != "4.2 d" || result.DisplayUnit != "h" {
+		t.Errorf("convert = %v, value = %v (%q), unit = %q", result.ShouldConvert, text.RawValue, text.Value, result.DisplayUnit)
+	}
+
+	spec = QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"country"}}
+	result, _ = Execute(spec, view, opts...)
+	if u := result.ChartConfig.YAxisUnit; u == nil || u.Name != "h" || u.Kind != UnitDuration {
+		t.Errorf("yAxisUnit = %+v", u)
+	}
+
+	spec.Intent = "table"
+	result, _ = Execute(spec, view, opts...)
+	cols := result.TableData.Columns
+	if cols[1].Unit == nil || cols[1].Unit.Kind != UnitDuration || cols[2].Unit == nil || cols[2].Unit.Kind != UnitCount {
+		t.Errorf("columns = %+v", cols)
+	}
+
+	// Counting records yields a count, whatever the measure's unit
+	spec.Aggregation = "count"
+	result, _ = Execute(spec, view, opts...)
+	if u := result.TableData.Columns[1].Unit; u == nil || u.Kind != UnitCount {
+		t.Errorf("count column unit = %+v", u)
+	}
+
+	// Currency measures keep their currency unit
+	spec = QuerySpec{Intent: "chart", Aggregation: "sum", Measure: "spend", GroupBy: []string{"country"}}
+	result, _ = Execute(spec, view, opts...)
+	if u := result.ChartConfig.YAxisUnit; u == nil || u.Name != "SGD" || u.Kind != UnitCurrency {
+		t.Errorf("spend yAxisUnit = %+v", u)
+	}
+}
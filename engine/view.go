@@ -10,6 +10,11 @@ package engine
 //   DomainView[T]  — reads typed structs via accessor functions (zero-copy)
 //   SubView        — filtered subset (indices into parent, zero-copy)
 //   CurrencyView   — wraps any view, normalizes currency on read
+//   CalendarView   — wraps any view, derives year/quarter/week buckets on read
+//   BlankView      — wraps any view, labels blank group-by values "(blank)"
+//   TagView        — wraps any view, splits multi-value dimensions into sets
+//   ColumnarView   — dictionary-encoded columns for large datasets
+//   IndexedView    — wraps any view, keeps bitmap indexes for repeated filters
 //   ConcatView     — virtual concatenation of two views
 //
 // Consumers register accessors once at init; engine reads millions of times.
@@ -25,6 +30,14 @@ type RecordView interface {
 	MeasureKeys() []string   // available measure keys
 }
 
+// NullableView is implemented by views that can tell a missing measure apart
+// from a real zero. Views that don't implement it report every measure present.
+// See HasMeasure.
+type NullableView interface {
+	RecordView
+	HasMeasure(index int, key string) bool
+}
+
 // ============================================================================
 // SLICE VIEW — wraps []Record (backward compatible)
 // ============================================================================
@@ -82,6 +95,14 @@ func (v *SliceView) Measure(i int, key string) float64 {
 	return v.records[i].Measures[key]
 }
 
+func (v *SliceView) HasMeasure(i int, key string) bool {
+	if i < 0 || i >= len(v.records) {
+		return false
+	}
+	_, ok := v.records[i].Measures[key]
+	return ok
+}
+
 func (v *SliceView) DimensionKeys() []string { return v.dimKeys }
 func (v *SliceView) MeasureKeys() []string   { return v.mesKeys }
 
@@ -116,6 +137,29 @@ func (v *SubView) Measure(i int, key string) float64 {
 	return v.parent.Measure(v.indices[i], key)
 }
 
+func (v *SubView) HasMeasure(i int, key string) bool {
+	if i < 0 || i >= len(v.indices) {
+		return false
+	}
+	return HasMeasure(v.parent, v.indices[i], key)
+}
+
+func (v *SubView) DimensionValues(i int, key string) ([]string, bool) {
+	if i < 0 || i >= len(v.indices) {
+		return nil, false
+	}
+	return DimensionValues(v.parent, v.indices[i], key)
+}
+
+func (v *SubView) Dictionary(key string) ([]string, bool) {
+	_, dict, ok := dimensionDictionary(v.parent, key)
+	return dict, ok
+}
+
+func (v *SubView) DimensionCode(i int, key string) uint32 {
+	return v.parent.(CodedView).DimensionCode(v.indices[i], key)
+}
+
 func (v *SubView) DimensionKeys() []string { return v.parent.DimensionKeys() }
 func (v *SubView) MeasureKeys() []string   { return v.parent.MeasureKeys() }
 
@@ -149,6 +193,20 @@ func (v *ConcatView) Measure(i int, key string) float64 {
 	return v.b.Measure(i-v.a.Len(), key)
 }
 
+func (v *ConcatView) HasMeasure(i int, key string) bool {
+	if i < v.a.Len() {
+		return HasMeasure(v.a, i, key)
+	}
+	return HasMeasure(v.b, i-v.a.Len(), key)
+}
+
+func (v *ConcatView) DimensionValues(i int, key string) ([]string, bool) {
+	if i < v.a.Len() {
+		return DimensionValues(v.a, i, key)
+	}
+	return DimensionValues(v.b, i-v.a.Len(), key)
+}
+
 func (v *ConcatView) DimensionKeys() []string { return v.a.DimensionKeys() }
 func (v *ConcatView) MeasureKeys() []string   { return v.a.MeasureKeys() }
 
@@ -157,34 +215,33 @@ func (v *ConcatView) MeasureKeys() []string   { return v.a.MeasureKeys() }
 // ============================================================================
 
 // CurrencyView wraps a RecordView and normalizes currency on read.
-// No data copy — conversion happens per Measure() call.
+// No data copy — conversion happens per Measure() call, at the record's
+// dated rate when a RateTable is configured.
 type CurrencyView struct {
-	parent       RecordView
-	measure      string
-	dimension    string
-	baseCurrency string
-	rates        map[string]float64
+	parent   RecordView
+	measures map[string]bool // currency amounts; other measures pass through
+	rates    *currencyRates
 }
 
-func newCurrencyView(parent RecordView, measure, dimension, baseCurrency string, rates map[string]float64) RecordView {
+func newCurrencyView(parent RecordView, measures []string, rates *currencyRates) RecordView {
+	set := make(map[string]bool, len(measures))
+	for _, m := range measures {
+		set[m] = true
+	}
 	return &CurrencyView{
-		parent:       parent,
-		measure:      measure,
-		dimension:    dimension,
-		baseCurrency: baseCurrency,
-		rates:        rates,
+		parent:   parent,
+		measures: set,
+		rates:    rates,
 	}
 }
 
 func (v *CurrencyView) Len() int { return v.parent.Len() }
 
 func (v *CurrencyView) Dimension(i int, key string) string {
-	if key == v.dimension {
+	if key == v.rates.dimension {
 		orig := v.parent.Dimension(i, key)
-		if orig != v.baseCurrency {
-			if _, ok := v.rates[orig]; ok {
-				return v.baseCurrency
-			}
+		if orig != v.rates.base && v.rates.record(v.parent, i).ok {
+			return v.rates.base
 		}
 		return orig
 	}
@@ -193,17 +250,40 @@ func (v *CurrencyView) Dimension(i int, key string) string {
 
 func (v *CurrencyView) Measure(i int, key string) float64 {
 	val := v.parent.Measure(i, key)
-	if key == v.measure {
-		currency := v.parent.Dimension(i, v.dimension)
-		if currency != v.baseCurrency {
-			if rate, ok := v.rates[currency]; ok && rate > 0 {
-				return val * rate
-			}
+	if v.measures[key] {
+		if l := v.rates.record(v.parent, i); l.ok {
+			return val * l.rate
 		}
 	}
 	return val
 }
 
+func (v *CurrencyView) HasMeasure(i int, key string) bool { return HasMeasure(v.parent, i, key) }
+
+func (v *CurrencyView) DimensionValues(i int, key string) ([]string, bool) {
+	return DimensionValues(v.parent, i, key)
+}
+
+// Dictionary forwards codes for every dimension but the rewritten currency one.
+func (v *CurrencyView) Dictionary(key string) ([]string, bool) {
+	if key == v.rates.dimension {
+		return nil, false
+	}
+	_, dict, ok := dimensionDictionary(v.parent, key)
+	return dict, ok
+}
+
+func (v *CurrencyView) DimensionCode(i int, key string) uint32 {
+	return v.parent.(CodedView).DimensionCode(i, key)
+}
+
+func (v *CurrencyView) FilterIndex(key string) (*DimensionIndex, bool) {
+	if key == v.rates.dimension {
+		return nil, false
+	}
+	return filterIndex(v.parent, key)
+}
+
 func (v *CurrencyView) DimensionKeys() []string { return v.parent.DimensionKeys() }
 func (v *CurrencyView) MeasureKeys() []string   { return v.parent.MeasureKeys() }
 
@@ -229,13 +309,15 @@ type DomainAdapter[T any] struct {
 	mesOrder []string
 	dims     map[string]func(T) string
 	meas     map[string]func(T) float64
+	present  map[string]func(T) bool
 }
 
 // NewDomainAdapter creates a new adapter for type T.
 func NewDomainAdapter[T any]() *DomainAdapter[T] {
 	return &DomainAdapter[T]{
-		dims: make(map[string]func(T) string),
-		meas: make(map[string]func(T) float64),
+		dims:    make(map[string]func(T) string),
+		meas:    make(map[string]func(T) float64),
+		present: make(map[string]func(T) bool),
 	}
 }
 
@@ -257,12 +339,28 @@ func (a *DomainAdapter[T]) Measure(key string, fn func(T) float64) *DomainAdapte
 	return a
 }
 
+// NullableMeasure registers a measure accessor that can report a missing
+// value (e.g., a nil *float64 field). Missing values are skipped by avg,
+// min, max and median.
+func (a *DomainAdapter[T]) NullableMeasure(key string, fn func(T) (float64, bool)) *DomainAdapter[T] {
+	a.Measure(key, func(t T) float64 {
+		v, _ := fn(t)
+		return v
+	})
+	a.present[key] = func(t T) bool {
+		_, ok := fn(t)
+		return ok
+	}
+	return a
+}
+
 // Bind creates a RecordView from a data slice. Zero-copy — holds reference.
 func (a *DomainAdapter[T]) Bind(data []T) RecordView {
 	return &DomainView[T]{
 		data:     data,
 		dims:     a.dims,
 		meas:     a.meas,
+		present:  a.present,
 		dimKeys:  a.dimOrder,
 		measKeys: a.mesOrder,
 	}
@@ -273,6 +371,7 @@ type DomainView[T any] struct {
 	data     []T
 	dims     map[string]func(T) string
 	meas     map[string]func(T) float64
+	present  map[string]func(T) bool
 	dimKeys  []string
 	measKeys []string
 }
@@ -299,5 +398,16 @@ func (v *DomainView[T]) Measure(i int, key string) float64 {
 	return 0
 }
 
+func (v *DomainView[T]) HasMeasure(i int, key string) bool {
+	if i < 0 || i >= len(v.data) {
+		return false
+	}
+	if fn, ok := v.present[key]; ok {
+		return fn(v.data[i])
+	}
+	_, ok := v.meas[key]
+	return ok
+}
+
 func (v *DomainView[T]) DimensionKeys() []string { return v.dimKeys }
 func (v *DomainView[T]) MeasureKeys() []string   { return v.measKeys }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/schema"
+)
+
+// ============================================================================
+// APPEND HELPER — CSV chunks → engine.AppendableView
+// ============================================================================
+// For data that keeps arriving: create the view once from the schema, then
+// append each new CSV chunk as it lands.
+//
+//   view := helpers.NewAppendableView(sch)
+//   view.RegisterIndex("status")
+//   helpers.AppendCSV(view, firstChunk, sch)
+//   helpers.AppendCSV(view, nextChunk, sch)   // view.Version() == 2
+//
+// Each chunk carries its own header row and must contain every schema
+// column; otherwise nothing from it is appended.
+// ============================================================================
+
+// NewAppendableView creates an empty appendable view with the schema's
+// dimension and measure columns.
+func NewAppendableView(sch schema.Config) *engine.AppendableView {
+	dims := make([]string, len(sch.Dimensions))
+	for i, d := range sch.Dimensions {
+		dims[i] = d.Key
+	}
+	measures := make([]string, len(sch.Measures))
+	for i, m := range sch.Measures {
+		measures[i] = m.Key
+	}
+	return engine.NewAppendableView(dims, measures)
+}
+
+// AppendCSV parses a CSV chunk (header row included) and appends its rows
+// to view as one new version.
+func AppendCSV(view *engine.AppendableView, r io.Reader, sch schema.Config) (*ParseReport, error) {
+	chunk, report, err := ParseCSVColumnarReader(r, sch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every schema column must be present in the chunk's header
+	have := make(map[string]bool)
+	for _, k := range chunk.DimensionKeys() {
+		have[k] = true
+	}
+	for _, k := range chunk.MeasureKeys() {
+		have[k] = true
+	}
+	for _, d := range sch.Dimensions {
+		if !have[d.Key] {
+			return report, fmt.Errorf("chunk is missing dimension column %q", d.Key)
+		}
+	}
+	for _, m := range sch.Measures {
+		if !have[m.Key] {
+			return report, fmt.Errorf("chunk is missing measure column %q", m.Key)
+		}
+	}
+
+	if err := view.AppendView(chunk); err != nil {
+		return report, fmt.Errorf("failed to append chunk: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spektr-org/spektr/engine"
+)
+
+// ============================================================================
+// APPEND TESTS
+// ============================================================================
+
+func TestAppendCSVMatchesParse(t *testing.T) {
+	chunks := []string{
+		"Team,Status,Hours\nCore,Open,4\nWeb,Closed,2.5\n",
+		"Hours,Team,Status\n7,Data,Closed\n1.5,Core,Open\n", // columns in another order
+	}
+	view := NewAppendableView(streamSchema)
+	for _, chunk := range chunks {
+		if _, err := AppendCSV(view, strings.NewReader(chunk), streamSchema); err != nil {
+			t.Fatalf("AppendCSV failed: %v", err)
+		}
+	}
+	if view.Version() != 2 || view.Len() != 4 {
+		t.Fatalf("version %d with %d rows, want 2 with 4", view.Version(), view.Len())
+	}
+
+	records, err := ParseCSV([]byte("Team,Status,Hours\nCore,Open,4\nWeb,Closed,2.5\nData,Closed,7\nCore,Open,1.5\n"), streamSchema)
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	spec := engine.QuerySpec{Intent: "table", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team", "status"}, Visualize: "table"}
+	want, err := engine.Execute(spec, engine.NewSliceView(records))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	got, err := engine.Execute(spec, view)
+	if err != nil {
+		t.Fatalf("Execute over appended view failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.TableData, want.TableData) {
+		t.Errorf("appended table = %+v, want %+v", got.TableData, want.TableData)
+	}
+}
+
+func TestAppendCSVRejectsMissingColumn(t *testing.T) {
+	view := NewAppendableView(streamSchema)
+	if _, err := AppendCSV(view, bytes.NewReader(streamCSV), streamSchema); err != nil {
+		t.Fatalf("AppendCSV failed: %v", err)
+	}
+	version, rows := view.Version(), view.Len()
+
+	_, err := AppendCSV(view, strings.NewReader("Team,Hours\nCore,3\nWeb,5\n"), streamSchema)
+	if err == nil || !strings.Contains(err.Error(), `"status"`) {
+		t.Fatalf("err = %v, want a missing status column", err)
+	}
+	if view.Version() != version || view.Len() != rows {
+		t.Errorf("after a rejected chunk: version %d with %d rows, want %d with %d",
+			view.Version(), view.Len(), version, rows)
+	}
+}
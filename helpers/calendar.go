@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/schema"
+)
+
+// ============================================================================
+// CALENDAR HELPER — schema.CalendarConfig → engine options
+// ============================================================================
+// The engine has no dependency on the schema package, so the schema-level
+// calendar setting is converted here.
+// ============================================================================
+
+// Calendar converts a schema calendar setting into an engine.Calendar.
+func Calendar(c schema.CalendarConfig) engine.Calendar {
+	return engine.Calendar{
+		Dimension:       c.Dimension,
+		FiscalYearStart: c.FiscalYearStart,
+		WeekStart:       c.WeekStart,
+		TimeZone:        c.TimeZone,
+	}
+}
+
+// CalendarOptions returns the engine options implied by a schema's calendar.
+// Returns nil when the schema has no calendar setting.
+func CalendarOptions(sch schema.Config) []engine.Option {
+	if sch.Calendar == nil {
+		return nil
+	}
+	return []engine.Option{engine.WithCalendar(Calendar(*sch.Calendar))}
+}
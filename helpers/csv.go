@@ -1,10 +1,11 @@
 package helpers
 
 import (
+	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 
 	"github.com/spektr-org/spektr/engine"
@@ -16,57 +17,175 @@ import (
 // ============================================================================
 // Consumer reads the CSV from wherever it lives (file, S3, Sheets).
 // This helper converts the raw bytes into generic Records using the schema.
+// StreamCSV reads from an io.Reader instead, for files too large to hold.
+// Files are read in the schema's Dialect (delimiter, encoding, title and
+// summary rows), sniffed from the data when the schema has none.
 // ============================================================================
 
+// ParseReport records what parsing left out. Empty cells are blanks or null
+// markers ("N/A", "null") in the source; unparseable cells held text that could not be read as a number.
+// Both leave the measure missing (null). Malformed rows (broken quoting)
+// and ragged rows (more or fewer fields than the header) are left out
+// whole. Of two headers with the same key, the first column is read and
+// the later one ignored.
+type ParseReport struct {
+	Rows    int                      `json:"rows"`
+	Columns map[string]*ColumnReport `json:"columns,omitempty"` // keyed by schema key; only columns with gaps
+
+	MalformedRows    int        `json:"malformedRows,omitempty"`
+	RaggedRows       int        `json:"raggedRows,omitempty"`
+	FooterRows       int        `json:"footerRows,omitempty"`       // summary rows dropped (schema.Dialect)
+	DuplicateHeaders []string   `json:"duplicateHeaders,omitempty"` // later headers whose key was already taken
+	SkippedRows      []RowIssue `json:"skippedRows,omitempty"`      // first few malformed and ragged rows
+
+	headerWidth int
+}
+
+// RowIssue describes a row left out of the parse.
+type RowIssue struct {
+	Line   int      `json:"line"`   // 1-based line in the file
+	Reason string   `json:"reason"` // "malformed" or "ragged"
+	Detail string   `json:"detail"`
+	Values []string `json:"values,omitempty"` // fields read, for ragged rows
+}
+
+// ColumnReport counts missing cells in one column.
+type ColumnReport struct {
+	Empty       int      `json:"empty"`
+	Unparseable int      `json:"unparseable"`
+	Samples     []string `json:"samples,omitempty"` // first few unparseable values
+}
+
+// maxReportSamples caps the unparseable values kept per column and the
+// skipped rows kept per report.
+const maxReportSamples = 5
+
+// Clean reports whether nothing was left out apart from empty cells.
+func (r *ParseReport) Clean() bool {
+	if r.MalformedRows > 0 || r.RaggedRows > 0 || len(r.DuplicateHeaders) > 0 {
+		return false
+	}
+	for _, c := range r.Columns {
+		if c.Unparseable > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseOption adjusts how CSV is parsed.
+type ParseOption func(*parseConfig)
+
+type parseConfig struct {
+	strict bool
+}
+
+// Strict makes parsing fail on the first problem the report would record:
+// a duplicate header, a malformed or ragged row, or a number that cannot be
+// parsed. Empty cells and null markers are not problems. The error is a
+// *StrictError.
+func Strict() ParseOption {
+	return func(c *parseConfig) { c.strict = true }
+}
+
+func newParseConfig(opts []ParseOption) parseConfig {
+	var c parseConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// StrictError is the problem that stopped a strict parse.
+type StrictError struct {
+	Line   int    // 1-based line of the row, 0 when not known
+	Row    int    // 1-based data row, 0 for header problems
+	Column string // header or schema key, when the problem is one cell
+	Detail string
+}
+
+func (e *StrictError) Error() string {
+	var where []string
+	if e.Line > 0 {
+		where = append(where, fmt.Sprintf("line %d", e.Line))
+	} else if e.Row > 0 {
+		where = append(where, fmt.Sprintf("row %d", e.Row))
+	}
+	if e.Column != "" {
+		where = append(where, fmt.Sprintf("column %q", e.Column))
+	}
+	if len(where) == 0 {
+		return "strict parse: " + e.Detail
+	}
+	return fmt.Sprintf("strict parse: %s: %s", strings.Join(where, ", "), e.Detail)
+}
+
+func (r *ParseReport) column(key string) *ColumnReport {
+	if r.Columns == nil {
+		r.Columns = make(map[string]*ColumnReport)
+	}
+	c, ok := r.Columns[key]
+	if !ok {
+		c = &ColumnReport{}
+		r.Columns[key] = c
+	}
+	return c
+}
+
 // ParseCSV parses CSV bytes into Records using schema for classification.
 // Each row becomes a Record with dimensions (string) and measures (numeric).
-func ParseCSV(data []byte, sch schema.Config) ([]engine.Record, error) {
-	reader := csv.NewReader(strings.NewReader(string(data)))
+// Empty or unparseable measure cells are left out of Record.Measures, so the
+// engine treats them as missing rather than zero.
+func ParseCSV(data []byte, sch schema.Config, opts ...ParseOption) ([]engine.Record, error) {
+	records, _, err := ParseCSVWithReport(data, sch, opts...)
+	return records, err
+}
 
-	// Read header
-	headers, err := reader.Read()
+// ParseCSVWithReport is ParseCSV plus a report of what was left out. A
+// strict parse that fails still returns the report up to the problem.
+func ParseCSVWithReport(data []byte, sch schema.Config, opts ...ParseOption) ([]engine.Record, *ParseReport, error) {
+	var records []engine.Record
+	report, err := StreamCSV(bytes.NewReader(data), sch, func(rec engine.Record) error {
+		records = append(records, rec)
+		return nil
+	}, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV headers: %w", err)
+		return nil, report, err
 	}
+	return records, report, nil
+}
 
-	// Build column index → schema mapping
-	dimSet := make(map[string]bool)
-	for _, d := range sch.Dimensions {
-		dimSet[d.Key] = true
-	}
-	measSet := make(map[string]bool)
-	for _, m := range sch.Measures {
-		if !m.IsSynthetic {
-			measSet[m.Key] = true
-		}
+// StreamCSV reads CSV from r one row at a time and calls fn with each Record,
+// without holding the file in memory. Each call gets freshly allocated maps,
+// so fn may keep the record. An error from fn stops the stream and is
+// returned wrapped.
+func StreamCSV(r io.Reader, sch schema.Config, fn func(engine.Record) error, opts ...ParseOption) (*ParseReport, error) {
+	cfg := newParseConfig(opts)
+	reader, err := newCSVReader(r, sch)
+	if err != nil {
+		return nil, err
 	}
+	reader.ReuseRecord = true
 
-	type colMapping struct {
-		schemaKey string
-		isDimension bool
-		isMeasure   bool
+	// Read header
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV headers: %w", err)
 	}
-
-	mappings := make([]colMapping, len(headers))
-	for i, h := range headers {
-		key := toSnakeCase(strings.TrimSpace(h))
-		if dimSet[key] {
-			mappings[i] = colMapping{schemaKey: key, isDimension: true}
-		} else if measSet[key] {
-			mappings[i] = colMapping{schemaKey: key, isMeasure: true}
-		}
-		// Unmapped columns are silently skipped
+	report := &ParseReport{}
+	mappings, err := report.mapColumns(headers, sch, cfg)
+	if err != nil {
+		return report, err
 	}
 
 	// Read rows
-	var records []engine.Record
 	for {
-		row, err := reader.Read()
+		row, err := report.next(reader, cfg)
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			continue // skip malformed rows
+			return report, err
 		}
 
 		rec := engine.Record{
@@ -82,9 +201,13 @@ func ParseCSV(data []byte, sch schema.Config) ([]engine.Record, error) {
 			val = strings.TrimSpace(val)
 
 			if m.isDimension {
-				rec.Dimensions[m.schemaKey] = val
+				rec.Dimensions[m.schemaKey] = report.dimension(m.schemaKey, val)
 			} else if m.isMeasure {
-				if f, ok := cleanAndParseNumeric(val); ok {
+				f, ok, err := report.measure(m, val, cfg)
+				if err != nil {
+					return report, err
+				}
+				if ok {
 					rec.Measures[m.schemaKey] = f
 				}
 			}
@@ -97,17 +220,270 @@ func ParseCSV(data []byte, sch schema.Config) ([]engine.Record, error) {
 			}
 		}
 
-		records = append(records, rec)
+		report.Rows++
+		if err := fn(rec); err != nil {
+			return report, fmt.Errorf("row %d: %w", report.Rows, err)
+		}
+	}
+
+	return report, nil
+}
+
+// newCSVReader reads r in the dialect recorded in the schema at discovery,
+// or, for schemas without one, the dialect sniffed from r itself.
+func newCSVReader(r io.Reader, sch schema.Config) (*schema.CSVReader, error) {
+	if sch.Dialect != nil {
+		return sch.Dialect.NewReader(r), nil
+	}
+	dialect, r, err := schema.SniffReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return dialect.NewReader(r), nil
+}
+
+// ParseCSVColumnar parses CSV straight into a dictionary-encoded
+// engine.ColumnarView, without building a Record (two maps) per row.
+// Prefer it over ParseCSVView for large files; results are identical.
+func ParseCSVColumnar(data []byte, sch schema.Config, opts ...ParseOption) (*engine.ColumnarView, *ParseReport, error) {
+	return ParseCSVColumnarReader(bytes.NewReader(data), sch, opts...)
+}
+
+// ParseCSVColumnarReader is ParseCSVColumnar over an io.Reader. Only the
+// encoded columns are held in memory, never the raw file.
+func ParseCSVColumnarReader(r io.Reader, sch schema.Config, opts ...ParseOption) (*engine.ColumnarView, *ParseReport, error) {
+	cfg := newParseConfig(opts)
+	reader, err := newCSVReader(r, sch)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader.ReuseRecord = true
+
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV headers: %w", err)
+	}
+	report := &ParseReport{}
+	mappings, err := report.mapColumns(headers, sch, cfg)
+	if err != nil {
+		return nil, report, err
+	}
+
+	// One slot per distinct mapped key, in header order
+	var dimKeys, measKeys []string
+	slots := make([]int, len(mappings))
+	dimSlot := make(map[string]int)
+	measSlot := make(map[string]int)
+	for i, m := range mappings {
+		switch {
+		case m.isDimension:
+			if _, ok := dimSlot[m.schemaKey]; !ok {
+				dimSlot[m.schemaKey] = len(dimKeys)
+				dimKeys = append(dimKeys, m.schemaKey)
+			}
+			slots[i] = dimSlot[m.schemaKey]
+		case m.isMeasure:
+			if _, ok := measSlot[m.schemaKey]; !ok {
+				measSlot[m.schemaKey] = len(measKeys)
+				measKeys = append(measKeys, m.schemaKey)
+			}
+			slots[i] = measSlot[m.schemaKey]
+		}
+	}
+	var synthetic []int
+	for _, m := range sch.Measures {
+		if m.IsSynthetic && m.DefaultAggregation == "count" {
+			synthetic = append(synthetic, len(measKeys))
+			measKeys = append(measKeys, m.Key)
+		}
+	}
+
+	builder := engine.NewColumnarBuilder(dimKeys, measKeys)
+	dims := make([]string, len(dimKeys))
+	meas := make([]float64, len(measKeys))
+	present := make([]bool, len(measKeys))
+	for {
+		row, err := report.next(reader, cfg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, report, err
+		}
+
+		for i := range dims {
+			dims[i] = ""
+		}
+		for i := range present {
+			meas[i], present[i] = 0, false
+		}
+
+		for i, val := range row {
+			if i >= len(mappings) {
+				break
+			}
+			m := mappings[i]
+			val = strings.TrimSpace(val)
+
+			if m.isDimension {
+				dims[slots[i]] = report.dimension(m.schemaKey, val)
+			} else if m.isMeasure {
+				f, ok, err := report.measure(m, val, cfg)
+				if err != nil {
+					return nil, report, err
+				}
+				if ok {
+					meas[slots[i]], present[slots[i]] = f, true
+				}
+			}
+		}
+		for _, s := range synthetic {
+			meas[s], present[s] = 1, true
+		}
+
+		builder.Append(dims, meas, present)
+		report.Rows++
+	}
+
+	return builder.Build(), report, nil
+}
+
+// csvColumn maps a CSV column to a schema key.
+type csvColumn struct {
+	schemaKey   string
+	isDimension bool
+	isMeasure   bool
+	decimal     string  // measure's decimal separator (MeasureMeta.DecimalSeparator)
+	durationMS  float64 // size of the measure's duration unit in ms; 0 if it has none
+}
+
+// parse parses a measure cell. Duration cells ("30 mins") are converted
+// into the measure's declared duration unit, so an "hours" measure stores
+// 0.5 for "30 mins" — the unit the engine formats it in. Measures without
+// one keep cleanAndParseNumeric's milliseconds.
+func (c csvColumn) parse(val string) (float64, bool) {
+	if c.durationMS > 0 {
+		if ms, ok := parseDurationMS(val, c.decimal); ok {
+			return ms / c.durationMS, true
+		}
+	}
+	return cleanAndParseNumeric(val, c.decimal)
+}
+
+// mapColumns maps CSV headers to schema dimensions and measures, and
+// records headers whose key an earlier header already took.
+// Unmapped columns are silently skipped.
+func (r *ParseReport) mapColumns(headers []string, sch schema.Config, cfg parseConfig) ([]csvColumn, error) {
+	r.headerWidth = len(headers)
+	dimSet := make(map[string]bool)
+	for _, d := range sch.Dimensions {
+		dimSet[d.Key] = true
+	}
+	measSet := make(map[string]bool)
+	measures := make(map[string]csvColumn)
+	for _, m := range sch.Measures {
+		if !m.IsSynthetic {
+			measSet[m.Key] = true
+			col := csvColumn{schemaKey: m.Key, isMeasure: true, decimal: m.DecimalSeparator}
+			if u, ok := engine.LookupUnit(m.Unit); ok && u.Kind == engine.UnitDuration {
+				col.durationMS = u.Factor
+			}
+			measures[m.Key] = col
+		}
 	}
 
-	return records, nil
+	mappings := make([]csvColumn, len(headers))
+	seen := make(map[string]bool)
+	for i, h := range headers {
+		key := toSnakeCase(strings.TrimSpace(h))
+		if key != "" && seen[key] {
+			r.DuplicateHeaders = append(r.DuplicateHeaders, h)
+			if cfg.strict {
+				return nil, &StrictError{Column: h, Detail: fmt.Sprintf("duplicate header (key %q)", key)}
+			}
+			continue
+		}
+		seen[key] = true
+		if dimSet[key] {
+			mappings[i] = csvColumn{schemaKey: key, isDimension: true}
+		} else if measSet[key] {
+			mappings[i] = measures[key]
+		}
+	}
+	return mappings, nil
+}
+
+// next reads the next data row. Malformed and ragged rows are counted,
+// sampled and skipped, or in strict mode returned as a *StrictError.
+func (r *ParseReport) next(reader *schema.CSVReader, cfg parseConfig) ([]string, error) {
+	for {
+		row, err := reader.Read()
+		if err == nil {
+			return row, nil
+		}
+		if err == io.EOF {
+			r.FooterRows = reader.FooterRowsDropped()
+			return nil, err
+		}
+		var pe *csv.ParseError
+		if !errors.As(err, &pe) {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		issue := RowIssue{Line: pe.StartLine, Reason: "malformed", Detail: pe.Err.Error()}
+		if errors.Is(err, csv.ErrFieldCount) {
+			issue.Reason = "ragged"
+			issue.Detail = fmt.Sprintf("%d fields, header has %d", len(row), r.headerWidth)
+			issue.Values = append([]string(nil), row...)
+			r.RaggedRows++
+		} else {
+			r.MalformedRows++
+		}
+		if len(r.SkippedRows) < maxReportSamples {
+			r.SkippedRows = append(r.SkippedRows, issue)
+		}
+		if cfg.strict {
+			return nil, &StrictError{Line: issue.Line, Detail: issue.Reason + " row: " + issue.Detail}
+		}
+	}
+}
+
+// dimension records an empty dimension cell and returns the value unchanged.
+func (r *ParseReport) dimension(key, val string) string {
+	if val == "" {
+		r.column(key).Empty++
+	}
+	return val
+}
+
+// measure parses a measure cell, recording it when empty or unparseable.
+// Null markers ("N/A", "null") count as empty, as they do in discovery.
+// In strict mode an unparseable cell is a *StrictError.
+func (r *ParseReport) measure(m csvColumn, val string, cfg parseConfig) (float64, bool, error) {
+	key := m.schemaKey
+	if schema.IsNullValue(val) {
+		r.column(key).Empty++
+		return 0, false, nil
+	}
+	if f, ok := m.parse(val); ok {
+		return f, true, nil
+	}
+	c := r.column(key)
+	c.Unparseable++
+	if len(c.Samples) < maxReportSamples {
+		c.Samples = append(c.Samples, val)
+	}
+	if cfg.strict {
+		return 0, false, &StrictError{Row: r.Rows + 1, Column: key, Detail: fmt.Sprintf("%q is not a number", val)}
+	}
+	return 0, false, nil
 }
 
 // ParseCSVAuto parses CSV without a pre-existing schema.
 // Returns both the discovered records and inferred column info.
 // Consumers can use this for quick demos before refining the schema.
 func ParseCSVAuto(data []byte) ([]engine.Record, []string, error) {
-	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader := schema.SniffDialect(data).NewReader(bytes.NewReader(data))
 
 	headers, err := reader.Read()
 	if err != nil {
@@ -141,7 +517,7 @@ func ParseCSVAuto(data []byte) ([]engine.Record, []string, error) {
 			val = strings.TrimSpace(val)
 
 			// Try numeric first (handles %, commas, duration suffixes)
-			if f, ok := cleanAndParseNumeric(val); ok {
+			if f, ok := cleanAndParseNumeric(val, "."); ok {
 				rec.Measures[keys[i]] = f
 			} else {
 				rec.Dimensions[keys[i]] = val
@@ -178,8 +554,11 @@ func ParseCSVAutoView(data []byte) (engine.RecordView, []string, error) {
 }
 
 // cleanAndParseNumeric attempts to extract a numeric value from a string that
-// may contain common formatting: percentages ("72.2%"), comma separators
-// ("1,234.56"), and duration suffixes ("2.37 s", "794 ms", "4.54 mins").
+// may contain common formatting: grouping and currency in either decimal
+// convention ("1,234.56", "1.234,56 €", "USD 100", "(500.00)"; see
+// schema.ParseNumber), percentages ("72.2%"), and duration suffixes
+// ("2.37 s", "794 ms", "4.54 mins"). decimal is the column's decimal
+// separator.
 //
 // Duration normalization converts to milliseconds:
 //   "794 ms"    → 794
@@ -188,35 +567,24 @@ func ParseCSVAutoView(data []byte) (engine.RecordView, []string, error) {
 //   "1.2 hrs"   → 4320000
 //
 // Returns the parsed value and true, or 0 and false if unparseable.
-func cleanAndParseNumeric(val string) (float64, bool) {
+func cleanAndParseNumeric(val, decimal string) (float64, bool) {
 	val = strings.TrimSpace(val)
 	if val == "" {
 		return 0, false
 	}
-
-	// Fast path: try raw parse first (covers clean numbers)
-	if f, err := strconv.ParseFloat(val, 64); err == nil {
-		return f, true
-	}
-
-	// Strip commas (thousands separators): "1,234.56" → "1234.56"
-	cleaned := strings.ReplaceAll(val, ",", "")
-	if f, err := strconv.ParseFloat(cleaned, 64); err == nil {
-		return f, true
-	}
-
-	// Percentage: "72.2%" → 72.2
-	if strings.HasSuffix(cleaned, "%") {
-		numStr := strings.TrimSuffix(cleaned, "%")
-		numStr = strings.TrimSpace(numStr)
-		if f, err := strconv.ParseFloat(numStr, 64); err == nil {
-			return f, true
-		}
+	// Checked before currency codes so "5 HRS" is a duration, not an
+	// amount in "HRS".
+	if ms, ok := parseDurationMS(val, decimal); ok {
+		return ms, true
 	}
+	return schema.ParseNumber(val, decimal)
+}
 
-	// Duration suffixes — normalize to milliseconds
+// parseDurationMS parses a number with a duration suffix ("30 mins",
+// "2.37 s") into milliseconds. Values without a suffix are not durations.
+func parseDurationMS(val, decimal string) (float64, bool) {
 	// Order matters: check longer suffixes first to avoid "mins" matching "ms" partial
-	lower := strings.ToLower(cleaned)
+	lower := strings.ToLower(val)
 	durationSuffixes := []struct {
 		suffix     string
 		multiplier float64
@@ -231,13 +599,12 @@ func cleanAndParseNumeric(val string) (float64, bool) {
 
 	for _, ds := range durationSuffixes {
 		if strings.HasSuffix(lower, ds.suffix) {
-			numStr := strings.TrimSpace(cleaned[:len(cleaned)-len(ds.suffix)])
-			if f, err := strconv.ParseFloat(numStr, 64); err == nil {
+			numStr := strings.TrimSpace(val[:len(val)-len(ds.suffix)])
+			if f, ok := schema.ParseNumber(numStr, decimal); ok {
 				return f * ds.multiplier, true
 			}
 		}
 	}
-
 	return 0, false
 }
 
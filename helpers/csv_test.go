@@ -0,0 +1,347 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/schema"
+)
+
+// ============================================================================
+// CSV PARSING TESTS
+// ============================================================================
+
+// discoverAndParse parses data with the schema discovered from it, the way
+// consumers use the two together.
+func discoverAndParse(t *testing.T, data []byte) (*schema.Config, []engine.Record) {
+	t.Helper()
+	sch, err := schema.DiscoverFromCSV(data)
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	records, err := ParseCSV(data, *sch)
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	return sch, records
+}
+
+func TestParseCSVDecimalComma(t *testing.T) {
+	data := []byte("Region;Betrag;Menge\n" +
+		"Nord;1.234,56;3\n" +
+		"Süd;99,5;12\n" +
+		"Ost;2.000,00;7\n" +
+		"West;0,25;1\n")
+	sch, records := discoverAndParse(t, data)
+
+	for _, m := range sch.Measures {
+		if m.Key == "betrag" && m.DecimalSeparator != "," {
+			t.Errorf("betrag DecimalSeparator = %q, want \",\"", m.DecimalSeparator)
+		}
+	}
+	want := []float64{1234.56, 99.5, 2000, 0.25}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, rec := range records {
+		if got := rec.Measures["betrag"]; got != want[i] {
+			t.Errorf("row %d betrag = %v, want %v", i+1, got, want[i])
+		}
+	}
+	if got := records[1].Measures["menge"]; got != 12 {
+		t.Errorf("menge = %v, want 12", got)
+	}
+}
+
+func TestCleanAndParseNumeric(t *testing.T) {
+	tests := []struct {
+		val     string
+		decimal string
+		want    float64
+		ok      bool
+	}{
+		{"1,234.56", "", 1234.56, true},
+		{"1.234,56", ",", 1234.56, true},
+		{"1.234", ",", 1234, true},
+		{"$1,200", "", 1200, true},
+		{"2.37 s", "", 2370, true},
+		{"abc", "", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := cleanAndParseNumeric(tt.val, tt.decimal)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("cleanAndParseNumeric(%q, %q) = %v, %v; want %v, %v", tt.val, tt.decimal, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseCSVDurationUnit(t *testing.T) {
+	data := []byte("Task,Hours,Wait\n" +
+		"Design,30 mins,2.5 s\n" +
+		"Build,90 mins,500 ms\n" +
+		"Review,1 hrs,\n" +
+		"Deploy,2,\n")
+	sch := schema.Config{
+		Dimensions: []schema.DimensionMeta{{Key: "task"}},
+		Measures:   []schema.MeasureMeta{{Key: "hours", Unit: "hours"}, {Key: "wait"}},
+	}
+	records, err := ParseCSV(data, sch)
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	// Durations are stored in the measure's unit; unsuffixed cells already are
+	want := []float64{0.5, 1.5, 1, 2}
+	for i, rec := range records {
+		if got := rec.Measures["hours"]; got != want[i] {
+			t.Errorf("row %d = %v hours, want %v", i+1, got, want[i])
+		}
+	}
+	// A measure without a unit keeps milliseconds
+	if got := records[0].Measures["wait"]; got != 2500 {
+		t.Errorf("wait = %v, want 2500 ms", got)
+	}
+
+	spec := engine.QuerySpec{Intent: "text", Aggregation: "sum", Measure: "hours"}
+	result, err := engine.Execute(spec, engine.NewSliceView(records), EngineOptions(sch)...)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if text := result.Data.(*engine.TextData); text.RawValue != 5 || text.Value != "5 h" {
+		t.Errorf("total = %v (%q), want 5 (\"5 h\")", text.RawValue, text.Value)
+	}
+}
+
+// utf16LE encodes s as UTF-16 little-endian with a byte-order mark.
+func utf16LE(s string) []byte {
+	b := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func TestParseCSVDialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		dialect schema.Dialect
+		regions []string
+		amounts []float64
+	}{
+		{
+			name:    "utf-16le with bom",
+			data:    utf16LE("Region\tAmount\r\nNörth\t10\r\nSouth\t20.5\r\nEast\t7\r\n"),
+			dialect: schema.Dialect{Delimiter: "\t", Encoding: schema.EncodingUTF16LE},
+			regions: []string{"Nörth", "South", "East"},
+			amounts: []float64{10, 20.5, 7},
+		},
+		{
+			name: "title row and total footer",
+			data: []byte("Sales report Q1 2026,,\n" +
+				"Region,Team,Amount\n" +
+				"North,Core,100\n" +
+				"South,Web,250\n" +
+				"East,Core,50\n" +
+				"West,Data,75\n" +
+				"Total,,475\n"),
+			dialect: schema.Dialect{Delimiter: ",", Encoding: schema.EncodingUTF8, HeaderRow: 1, FooterRows: 1},
+			regions: []string{"North", "South", "East", "West"},
+			amounts: []float64{100, 250, 50, 75},
+		},
+	}
+	for _, tt := range tests {
+		sch, records := discoverAndParse(t, tt.data)
+		if sch.Dialect == nil {
+			t.Fatalf("%s: discovery recorded no dialect", tt.name)
+		}
+		d := *sch.Dialect
+		if d.Delimiter != tt.dialect.Delimiter || d.Encoding != tt.dialect.Encoding || d.HeaderRow != tt.dialect.HeaderRow {
+			t.Errorf("%s: dialect = %+v, want %+v", tt.name, d, tt.dialect)
+		}
+		if len(records) != len(tt.regions) {
+			t.Fatalf("%s: got %d records, want %d", tt.name, len(records), len(tt.regions))
+		}
+		for i, rec := range records {
+			if rec.Dimensions["region"] != tt.regions[i] || rec.Measures["amount"] != tt.amounts[i] {
+				t.Errorf("%s: row %d = %s %v, want %s %v", tt.name, i+1,
+					rec.Dimensions["region"], rec.Measures["amount"], tt.regions[i], tt.amounts[i])
+			}
+		}
+
+		// The recorded dialect also drives streamed parsing
+		var streamed int
+		report, err := StreamCSV(bytes.NewReader(tt.data), *sch, func(engine.Record) error {
+			streamed++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: StreamCSV failed: %v", tt.name, err)
+		}
+		if streamed != len(tt.regions) || report.FooterRows != tt.dialect.FooterRows {
+			t.Errorf("%s: streamed %d records with %d footer rows, want %d and %d",
+				tt.name, streamed, report.FooterRows, len(tt.regions), tt.dialect.FooterRows)
+		}
+	}
+}
+
+// ============================================================================
+// PARSE REPORT TESTS
+// ============================================================================
+
+// reportCSV has one row of every kind the report records.
+var reportCSV = []byte(`Region,Team,Amount,Region
+North,Core,10,x
+South,Web,abc,x
+Ea"st,Core,5,x
+West,Data,7
+North,Core,N/A,x
+South,Web,,x
+Total,,22,
+`)
+
+func reportSchema() schema.Config {
+	dialect := schema.DefaultDialect()
+	dialect.FooterRows = 1
+	return schema.Config{
+		Dimensions: []schema.DimensionMeta{{Key: "region"}, {Key: "team"}},
+		Measures:   []schema.MeasureMeta{{Key: "amount"}},
+		Dialect:    &dialect,
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	records, report, err := ParseCSVWithReport(reportCSV, reportSchema())
+	if err != nil {
+		t.Fatalf("ParseCSVWithReport failed: %v", err)
+	}
+
+	if len(records) != 4 || report.Rows != 4 {
+		t.Fatalf("parsed %d records, report.Rows = %d, want 4", len(records), report.Rows)
+	}
+	if records[0].Dimensions["region"] != "North" {
+		t.Errorf("region = %q, want the first Region column's North", records[0].Dimensions["region"])
+	}
+	if _, ok := records[2].Measures["amount"]; ok {
+		t.Error("N/A amount should be missing, not zero")
+	}
+	if report.MalformedRows != 1 || report.RaggedRows != 1 || report.FooterRows != 1 {
+		t.Errorf("malformed/ragged/footer = %d/%d/%d, want 1/1/1",
+			report.MalformedRows, report.RaggedRows, report.FooterRows)
+	}
+	if !reflect.DeepEqual(report.DuplicateHeaders, []string{"Region"}) {
+		t.Errorf("DuplicateHeaders = %v, want [Region]", report.DuplicateHeaders)
+	}
+
+	if len(report.SkippedRows) != 2 {
+		t.Fatalf("SkippedRows = %+v, want 2", report.SkippedRows)
+	}
+	if r := report.SkippedRows[0]; r.Line != 4 || r.Reason != "malformed" {
+		t.Errorf("first skipped row = %+v, want malformed line 4", r)
+	}
+	if r := report.SkippedRows[1]; r.Line != 5 || r.Reason != "ragged" || !reflect.DeepEqual(r.Values, []string{"West", "Data", "7"}) {
+		t.Errorf("second skipped row = %+v, want ragged line 5 with its values", r)
+	}
+
+	amount := report.Columns["amount"]
+	if amount == nil {
+		t.Fatal("no report for amount")
+	}
+	if amount.Empty != 2 || amount.Unparseable != 1 || !reflect.DeepEqual(amount.Samples, []string{"abc"}) {
+		t.Errorf("amount report = %+v, want 2 empty (blank and N/A), 1 unparseable (abc)", amount)
+	}
+	if report.Clean() {
+		t.Error("report with skipped rows should not be clean")
+	}
+
+	// Streaming reports the same
+	var streamed int
+	streamReport, err := StreamCSV(bytes.NewReader(reportCSV), reportSchema(), func(engine.Record) error {
+		streamed++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamCSV failed: %v", err)
+	}
+	if streamed != 4 || !reflect.DeepEqual(streamReport, report) {
+		t.Errorf("StreamCSV report = %+v, want %+v", streamReport, report)
+	}
+}
+
+func TestParseReportSamplesCapped(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("Region,Amount\n")
+	for i := 0; i < 20; i++ {
+		b.WriteString("North,1,extra\n")
+		b.WriteString("South,none\n")
+	}
+	sch := reportSchema()
+	sch.Dialect.FooterRows = 0
+	_, report, err := ParseCSVWithReport(b.Bytes(), sch)
+	if err != nil {
+		t.Fatalf("ParseCSVWithReport failed: %v", err)
+	}
+	if report.RaggedRows != 20 || len(report.SkippedRows) != maxReportSamples {
+		t.Errorf("ragged = %d with %d sampled, want 20 with %d", report.RaggedRows, len(report.SkippedRows), maxReportSamples)
+	}
+	if r := report.SkippedRows[1]; r.Line != 4 {
+		t.Errorf("second ragged row at line %d, want 4", r.Line)
+	}
+	if c := report.Columns["amount"]; c.Unparseable != 20 || len(c.Samples) != maxReportSamples {
+		t.Errorf("amount = %+v, want 20 unparseable with %d samples", c, maxReportSamples)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want StrictError
+	}{
+		{"duplicate header", "Region,Amount,Region\nNorth,1,x\n", StrictError{Column: "Region", Detail: `duplicate header (key "region")`}},
+		{"malformed row", "Region,Amount\nNorth,1\nSo\"uth,2\n", StrictError{Line: 3}},
+		{"ragged row", "Region,Amount\nNorth,1\nSouth,2,3\n", StrictError{Line: 3}},
+		{"unparseable number", "Region,Amount\nNorth,1\nSouth,abc\n", StrictError{Row: 2, Column: "amount", Detail: `"abc" is not a number`}},
+	}
+	sch := reportSchema()
+	sch.Dialect.FooterRows = 0
+	for _, tt := range tests {
+		_, report, err := ParseCSVWithReport([]byte(tt.data), sch, Strict())
+		var se *StrictError
+		if !errors.As(err, &se) {
+			t.Errorf("%s: error = %v, want *StrictError", tt.name, err)
+			continue
+		}
+		if se.Line != tt.want.Line || se.Row != tt.want.Row || se.Column != tt.want.Column ||
+			(tt.want.Detail != "" && se.Detail != tt.want.Detail) {
+			t.Errorf("%s: error = %+v, want %+v", tt.name, *se, tt.want)
+		}
+		if report == nil {
+			t.Errorf("%s: strict failure should still return the report", tt.name)
+		}
+		if _, err := StreamCSV(bytes.NewReader([]byte(tt.data)), sch, func(engine.Record) error { return nil }, Strict()); !errors.As(err, &se) {
+			t.Errorf("%s: StreamCSV error = %v, want *StrictError", tt.name, err)
+		}
+	}
+
+	// Empty cells and null markers are not problems
+	data := []byte("Region;Betrag\nNord;1.234,56\nSüd;N/A\nOst;\nWest;null\n" +
+		"Nord;99,5\nSüd;2.000,00\nOst;0,25\nWest;12,75\n")
+	discovered, err := schema.DiscoverFromCSV(data)
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	records, report, err := ParseCSVWithReport(data, *discovered, Strict())
+	if err != nil {
+		t.Fatalf("strict parse of null markers failed: %v", err)
+	}
+	if len(records) != 8 || records[0].Measures["betrag"] != 1234.56 {
+		t.Errorf("records = %+v", records)
+	}
+	if c := report.Columns["betrag"]; c == nil || c.Empty != 3 || c.Unparseable != 0 {
+		t.Errorf("betrag report = %+v, want 3 empty", c)
+	}
+}
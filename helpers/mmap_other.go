@@ -0,0 +1,12 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package helpers
+
+import "os"
+
+// mapFile reads the whole file where memory mapping is unavailable
+// (Windows, WASM).
+func mapFile(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	return data, func() error { return nil }, err
+}
@@ -0,0 +1,35 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package helpers
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile memory-maps a file read-only and private, so nothing written
+// through the mapping reaches the file. Pages still read from the file
+// itself: a file rewritten while mapped changes the data under the view, and
+// one truncated while mapped faults on access. Empty files are read normally.
+func mapFile(path string) ([]byte, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := info.Size()
+	if size == 0 || size != int64(int(size)) {
+		data, err := os.ReadFile(path)
+		return data, func() error { return nil }, err
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,154 @@
+package helpers
+
+import (
+	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/schema"
+)
+
+// ============================================================================
+// OPTIONS HELPER — schema.Config → engine options
+// ============================================================================
+// Schema settings that change how the engine reads data (calendar,
+// multi-value dimensions, ordinal value orders, currency measures) are
+// translated here, so consumers pass one list.
+// ============================================================================
+
+// EngineOptions returns every engine option implied by the schema.
+func EngineOptions(sch schema.Config) []engine.Option {
+	opts := CalendarOptions(sch)
+	opts = append(opts, MultiValueOptions(sch)...)
+	opts = append(opts, ValueOrderOptions(sch)...)
+	opts = append(opts, CurrencyMeasureOptions(sch)...)
+	opts = append(opts, MeasureUnitOptions(sch)...)
+	opts = append(opts, MeasureFormatOptions(sch)...)
+	return opts
+}
+
+// MultiValueDelimiters maps each multi-value dimension to its delimiter.
+// Returns nil when the schema has none.
+func MultiValueDelimiters(sch schema.Config) map[string]string {
+	var delims map[string]string
+	for _, d := range sch.Dimensions {
+		if d.MultiValueDelimiter == "" {
+			continue
+		}
+		if delims == nil {
+			delims = make(map[string]string)
+		}
+		delims[d.Key] = d.MultiValueDelimiter
+	}
+	return delims
+}
+
+// MultiValueOptions returns engine.WithMultiValue for each tag-style dimension.
+func MultiValueOptions(sch schema.Config) []engine.Option {
+	var opts []engine.Option
+	for dim, delim := range MultiValueDelimiters(sch) {
+		opts = append(opts, engine.WithMultiValue(dim, delim))
+	}
+	return opts
+}
+
+// ValueOrders maps each ordered dimension (ValueOrder or SortHint) to its
+// value order. Returns nil when the schema has none.
+func ValueOrders(sch schema.Config) map[string][]string {
+	var orders map[string][]string
+	for _, d := range sch.Dimensions {
+		order := d.Order()
+		if order == nil {
+			continue
+		}
+		if orders == nil {
+			orders = make(map[string][]string)
+		}
+		orders[d.Key] = order
+	}
+	return orders
+}
+
+// ValueOrderOptions returns engine.WithValueOrder for each ordered dimension.
+func ValueOrderOptions(sch schema.Config) []engine.Option {
+	var opts []engine.Option
+	for dim, order := range ValueOrders(sch) {
+		opts = append(opts, engine.WithValueOrder(dim, order))
+	}
+	return opts
+}
+
+// CurrencyMeasures lists the measures flagged IsCurrency. Returns nil when
+// the schema flags none, so every measure stays convertible.
+func CurrencyMeasures(sch schema.Config) []string {
+	var measures []string
+	for _, m := range sch.Measures {
+		if m.IsCurrency {
+			measures = append(measures, m.Key)
+		}
+	}
+	return measures
+}
+
+// CurrencyMeasureOptions returns engine.WithCurrencyMeasures for the
+// schema's currency measures, if it flags any.
+func CurrencyMeasureOptions(sch schema.Config) []engine.Option {
+	measures := CurrencyMeasures(sch)
+	if measures == nil {
+		return nil
+	}
+	return []engine.Option{engine.WithCurrencyMeasures(measures...)}
+}
+
+// MeasureUnits maps each measure with a non-currency unit ("hours",
+// "points", "percent", "units") to that unit. Currency measures are left to
+// CurrencyMeasures. Returns nil when the schema declares none.
+func MeasureUnits(sch schema.Config) map[string]string {
+	var units map[string]string
+	for _, m := range sch.Measures {
+		if m.Unit == "" || m.IsCurrency {
+			continue
+		}
+		if u, ok := engine.LookupUnit(m.Unit); !ok || u.Kind == engine.UnitCurrency {
+			continue
+		}
+		if units == nil {
+			units = make(map[string]string)
+		}
+		units[m.Key] = m.Unit
+	}
+	return units
+}
+
+// MeasureUnitOptions returns engine.WithMeasureUnit for each measure with a
+// non-currency unit.
+func MeasureUnitOptions(sch schema.Config) []engine.Option {
+	var opts []engine.Option
+	for measure, unit := range MeasureUnits(sch) {
+		opts = append(opts, engine.WithMeasureUnit(measure, unit))
+	}
+	return opts
+}
+
+// MeasureFormats maps each measure with a Format pattern to it. Returns nil
+// when the schema sets none.
+func MeasureFormats(sch schema.Config) map[string]string {
+	var formats map[string]string
+	for _, m := range sch.Measures {
+		if m.Format == "" {
+			continue
+		}
+		if formats == nil {
+			formats = make(map[string]string)
+		}
+		formats[m.Key] = m.Format
+	}
+	return formats
+}
+
+// MeasureFormatOptions returns engine.WithMeasureFormat for each measure
+// with a Format pattern.
+func MeasureFormatOptions(sch schema.Config) []engine.Option {
+	var opts []engine.Option
+	for measure, format := range MeasureFormats(sch) {
+		opts = append(opts, engine.WithMeasureFormat(measure, format))
+	}
+	return opts
+}
@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/schema"
+)
+
+// ============================================================================
+// SNAPSHOT HELPER — dataset + schema in one file
+// ============================================================================
+// A snapshot skips CSV parsing and schema discovery on reload:
+//
+//   f, _ := os.Create("sales.spektr")
+//   helpers.SaveSnapshot(f, view, sch)
+//
+//   snap, _ := helpers.OpenSnapshot("sales.spektr") // memory-mapped
+//   defer snap.Close()
+//   engine.Execute(spec, snap.View, helpers.EngineOptions(snap.Schema)...)
+//
+// The schema travels as the snapshot's metadata. See engine.WriteSnapshot
+// for the file format.
+// ============================================================================
+
+// Snapshot is a loaded dataset and its schema.
+type Snapshot struct {
+	Schema schema.Config
+	View   *engine.ColumnarView
+	Stats  engine.SnapshotStats
+
+	release func() error
+}
+
+// SaveSnapshot writes view and its schema as a snapshot.
+func SaveSnapshot(w io.Writer, view engine.RecordView, sch schema.Config) error {
+	meta, err := json.Marshal(sch)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return engine.WriteSnapshot(w, view, meta)
+}
+
+// LoadSnapshot decodes a snapshot held in memory. The view may reference
+// data, which must not be modified afterwards.
+func LoadSnapshot(data []byte) (*Snapshot, error) {
+	snap, err := engine.ReadSnapshot(data)
+	if err != nil {
+		return nil, err
+	}
+	var sch schema.Config
+	if err := json.Unmarshal(snap.Meta, &sch); err != nil {
+		return nil, fmt.Errorf("%w: invalid schema: %v", engine.ErrCorruptSnapshot, err)
+	}
+	return &Snapshot{Schema: sch, View: snap.View, Stats: snap.Stats}, nil
+}
+
+// OpenSnapshot loads a snapshot file, memory-mapping it where the platform
+// allows. Call Close when done with the view.
+//
+// The view reads the file in place, so the file must not be modified,
+// replaced in place or truncated until Close: the view would change or the
+// process would crash. Write new snapshots to a temporary file and rename
+// it over the old one. Where that cannot be guaranteed, copy the file into
+// memory instead: read it with os.ReadFile and pass it to LoadSnapshot.
+func OpenSnapshot(path string) (*Snapshot, error) {
+	data, release, err := mapFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	snap, err := LoadSnapshot(data)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	snap.release = release
+	return snap, nil
+}
+
+// Close releases the file mapping. The view must not be used afterwards.
+func (s *Snapshot) Close() error {
+	if s.release == nil {
+		return nil
+	}
+	release := s.release
+	s.release = nil
+	return release()
+}
+
+// IsSnapshotFile reports whether the file at path is a snapshot.
+func IsSnapshotFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 8)
+	n, _ := io.ReadFull(f, head)
+	return engine.IsSnapshot(head[:n])
+}
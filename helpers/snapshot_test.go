@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spektr-org/spektr/engine"
+)
+
+// ============================================================================
+// SNAPSHOT TESTS
+// ============================================================================
+
+var snapshotCSV = []byte(`Team,Status,Hours
+Core,Open,4
+Web,Closed,2.5
+Core,Closed,
+Data,Open,7
+`)
+
+// writeSnapshot parses snapshotCSV and saves it as a snapshot file.
+func writeSnapshot(t *testing.T) (path string, view *engine.ColumnarView) {
+	t.Helper()
+	sch, _ := discoverAndParse(t, snapshotCSV)
+	view, _, err := ParseCSVColumnar(snapshotCSV, *sch)
+	if err != nil {
+		t.Fatalf("ParseCSVColumnar failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := SaveSnapshot(&buf, view, *sch); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	path = filepath.Join(t.TempDir(), "data.spektr")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path, view
+}
+
+func TestOpenSnapshotRoundTrip(t *testing.T) {
+	path, view := writeSnapshot(t)
+	if !IsSnapshotFile(path) {
+		t.Fatalf("IsSnapshotFile(%s) = false", path)
+	}
+
+	snap, err := OpenSnapshot(path)
+	if err != nil {
+		t.Fatalf("OpenSnapshot failed: %v", err)
+	}
+	defer snap.Close()
+
+	sch, _ := discoverAndParse(t, snapshotCSV)
+	if !reflect.DeepEqual(snap.Schema.Dimensions, sch.Dimensions) || !reflect.DeepEqual(snap.Schema.Measures, sch.Measures) {
+		t.Errorf("schema = %+v, want %+v", snap.Schema, sch)
+	}
+	if snap.View.Len() != view.Len() {
+		t.Fatalf("view has %d rows, want %d", snap.View.Len(), view.Len())
+	}
+
+	spec := engine.QuerySpec{Intent: "table", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, Visualize: "table"}
+	want, err := engine.Execute(spec, view)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if want.TableData == nil || len(want.TableData.Rows) != 3 {
+		t.Fatalf("table = %+v, want one row per team", want.TableData)
+	}
+	got, err := engine.Execute(spec, snap.View)
+	if err != nil {
+		t.Fatalf("Execute over snapshot failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.TableData, want.TableData) {
+		t.Errorf("snapshot table = %+v, want %+v", got.TableData, want.TableData)
+	}
+
+	if err := snap.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if err := snap.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+}
+
+func TestOpenSnapshotTruncated(t *testing.T) {
+	path, _ := writeSnapshot(t)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{len(data) - 1, len(data) / 2, 8} {
+		if err := os.WriteFile(path, data[:n], 0o644); err != nil {
+			t.Fatal(err)
+		}
+		snap, err := OpenSnapshot(path)
+		if !errors.Is(err, engine.ErrCorruptSnapshot) {
+			if snap != nil {
+				snap.Close()
+			}
+			t.Errorf("OpenSnapshot of %d/%d bytes: err = %v, want ErrCorruptSnapshot", n, len(data), err)
+		}
+	}
+
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenSnapshot(path); !errors.Is(err, engine.ErrCorruptSnapshot) {
+		t.Errorf("OpenSnapshot of an empty file: err = %v, want ErrCorruptSnapshot", err)
+	}
+}
@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/schema"
+)
+
+// ============================================================================
+// STREAM HELPER — CSV io.Reader → engine.StreamExecutor
+// ============================================================================
+// Answers one query over a CSV stream in a single pass. Memory grows with
+// the number of result groups, not the file size. See engine.StreamExecutor
+// for which queries can be streamed.
+// ============================================================================
+
+// ExecuteCSVStream runs spec over the CSV in r. Schema options (calendar,
+// multi-value dimensions, value orders) are applied before opts; parseOpts
+// (e.g. Strict) govern reading the CSV as in StreamCSV. The report says
+// which rows the pass left out.
+func ExecuteCSVStream(r io.Reader, sch schema.Config, spec engine.QuerySpec, opts []engine.Option, parseOpts ...ParseOption) (*engine.Result, *ParseReport, error) {
+	exec, err := engine.NewStreamExecutor(spec, append(EngineOptions(sch), opts...)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot stream query: %w", err)
+	}
+	report, err := StreamCSV(r, sch, func(rec engine.Record) error {
+		exec.Add(rec)
+		return nil
+	}, parseOpts...)
+	if err != nil {
+		return nil, report, err
+	}
+	result, err := exec.Result()
+	return result, report, err
+}
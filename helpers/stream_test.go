@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/schema"
+)
+
+// ============================================================================
+// STREAM TESTS
+// ============================================================================
+
+var streamCSV = []byte(`Team,Status,Hours
+Core,Open,4
+Web,Closed,2.5
+Core,Closed,
+Data,Open,abc
+Web,Open,3,extra
+Data,Closed,7
+Core,Open,1.5
+`)
+
+var streamSchema = schema.Config{
+	Dimensions: []schema.DimensionMeta{{Key: "team"}, {Key: "status"}},
+	Measures:   []schema.MeasureMeta{{Key: "hours"}},
+}
+
+func TestExecuteCSVStreamMatchesExecute(t *testing.T) {
+	records, wantReport, err := ParseCSVWithReport(streamCSV, streamSchema)
+	if err != nil {
+		t.Fatalf("ParseCSVWithReport failed: %v", err)
+	}
+	clock := engine.WithClock(func() time.Time { return time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC) })
+	specs := []engine.QuerySpec{
+		{Intent: "chart", Aggregation: "sum", Measure: "hours", GroupBy: []string{"team"}, SortBy: "value_desc", Visualize: "bar"},
+		{Intent: "table", Aggregation: "count", Measure: "hours", GroupBy: []string{"status", "team"}, Visualize: "table"},
+		{Intent: "text", Aggregation: "avg", Measure: "hours",
+			Filters: engine.Filters{Dimensions: map[string][]string{"status": {"open"}}}},
+	}
+	for _, spec := range specs {
+		want, err := engine.Execute(spec, engine.NewSliceView(records), append(EngineOptions(streamSchema), clock)...)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		got, report, err := ExecuteCSVStream(bytes.NewReader(streamCSV), streamSchema, spec, []engine.Option{clock})
+		if err != nil {
+			t.Fatalf("ExecuteCSVStream failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s %s by %v: streamed result differs\n got: %+v\nwant: %+v",
+				spec.Intent, spec.Aggregation, spec.GroupBy, got, want)
+		}
+		if !reflect.DeepEqual(report, wantReport) {
+			t.Errorf("stream report = %+v, want %+v", report, wantReport)
+		}
+	}
+}
+
+func TestExecuteCSVStreamStrict(t *testing.T) {
+	spec := engine.QuerySpec{Intent: "text", Aggregation: "sum", Measure: "hours"}
+	_, report, err := ExecuteCSVStream(bytes.NewReader(streamCSV), streamSchema, spec, nil, Strict())
+	if err == nil {
+		t.Fatal("strict stream over an unparseable number succeeded")
+	}
+	if report == nil || report.Rows != 3 {
+		t.Errorf("report = %+v, want the 3 rows before the problem", report)
+	}
+}
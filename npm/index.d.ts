@@ -12,7 +12,15 @@ export interface SchemaConfig {
   dimensions: DimensionMeta[];
   measures: MeasureMeta[];
   skippedColumns?: SkippedColumn[];
+  /** Rows auto-discovery could not read (broken quoting). */
+  malformedRows?: number;
+  /** Rows auto-discovery left out for a field count unlike the header's. */
+  raggedRows?: number;
   currency?: CurrencyConfig;
+  calendar?: Calendar;
+  dialect?: Dialect;
+  /** Per-column statistics, present when discovery was asked to profile. */
+  profile?: Profile;
   discoveredFrom?: string;
   discoveredAt?: string;
   refinedAt?: string;
@@ -32,6 +40,10 @@ export interface DimensionMeta {
   sortHint?: string;
   temporalOrder?: string;
   cardinalityHint?: string;
+  multiValueDelimiter?: string;
+  valueOrder?: string[];
+  /** Auto-discovery's confidence in this classification, 0–1. */
+  confidence?: number;
 }
 
 export interface MeasureMeta {
@@ -43,12 +55,75 @@ export interface MeasureMeta {
   isSynthetic?: boolean;
   aggregations: string[];
   defaultAggregation: string;
+  /** Number pattern setting display precision, e.g. "#,##0.00". */
+  format?: string;
+  /** "," when cells use a comma decimal ("1.234,56"). */
+  decimalSeparator?: ",";
+  /** Auto-discovery's confidence in this classification, 0–1. */
+  confidence?: number;
 }
 
 export interface SkippedColumn {
   column: string;
   reason: string;
   recoverable: boolean;
+  /** Auto-discovery's confidence that the column should be skipped, 0–1. */
+  confidence?: number;
+}
+
+/** Per-column statistics for a dataset. */
+export interface Profile {
+  rows: number;
+  columns: ColumnProfile[];
+}
+
+export interface ColumnProfile {
+  column: string;
+  key: string;
+  role: 'dimension' | 'measure' | 'skipped';
+  /** Share of rows with no value, 0–1. */
+  nullRate: number;
+  distinct: number;
+  /** Distinct counting stopped early; topValues are approximate. */
+  distinctCapped?: boolean;
+  /** Measures only. */
+  numeric?: NumericStats;
+  /** Dimensions only, most frequent first. */
+  topValues?: { value: string; count: number }[];
+  /** Temporal dimensions only, as ISO dates. */
+  dates?: { min: string; max: string };
+}
+
+export interface NumericStats {
+  min: number;
+  max: number;
+  mean: number;
+  stdDev: number;
+  p25: number;
+  median: number;
+  p75: number;
+  p95: number;
+  /** Quantiles were computed from a sample of the values. */
+  sampledQuantiles?: boolean;
+}
+
+/** What parsing left out. Empty and unparseable cells are missing, not zero. */
+export interface ParseReport {
+  /** Records parsed. */
+  rows: number;
+  /** Keyed by schema key; only columns with gaps are listed. */
+  columns?: { [key: string]: { empty: number; unparseable: number; samples?: string[] } };
+  malformedRows?: number;
+  raggedRows?: number;
+  /** Trailing summary rows dropped (see Dialect.footerRows). */
+  footerRows?: number;
+  duplicateHeaders?: string[];
+  /** First few malformed and ragged rows. */
+  skippedRows?: { line: number; reason: 'malformed' | 'ragged'; detail: string; values?: string[] }[];
+}
+
+export interface SnapshotResult extends SpektrResult<Uint8Array> {
+  parseReport?: ParseReport;
 }
 
 export interface CurrencyConfig {
@@ -58,14 +133,31 @@ export interface CurrencyConfig {
   rates?: Record<string, number>;
 }
 
+export interface Calendar {
+  dimension?: string;
+  fiscalYearStart?: number;
+  weekStart?: string;
+  timeZone?: string;
+}
+
+export interface Dialect {
+  delimiter: string;
+  quote?: "none";
+  encoding?: "utf-8" | "utf-16le" | "utf-16be" | "windows-1252";
+  headerRow?: number;
+  footerRows?: number;
+}
+
 export interface QuerySpec {
   intent: string;
   filters: Filters;
   compareFilters?: Filters;
+  timeRange?: TimeRange;
   aggregation: string;
   measure: string;
   groupBy: string[];
   sortBy: string;
+  sortKeys?: SortKey[];
   limit: number;
   visualize: string;
   title: string;
@@ -73,6 +165,22 @@ export interface QuerySpec {
   confidence: number;
 }
 
+export interface SortKey {
+  by: 'value' | 'count' | 'label' | 'date' | 'ordinal' | 'dimension' | 'measure';
+  dimension?: string;
+  measure?: string;
+  desc?: boolean;
+}
+
+export interface TimeRange {
+  dimension?: string;
+  relative?: string;
+  last?: number;
+  unit?: string;
+  since?: string;
+  until?: string;
+}
+
 export interface Filters {
   dimensions: Record<string, string[]>;
 }
@@ -87,6 +195,29 @@ export interface ExecuteOptions {
   baseCurrency?: string;
   currencyDimension?: string;
   exchangeRates?: Record<string, number>;
+  /** Measures holding currency amounts; omit to convert every measure. */
+  currencyMeasures?: string[];
+  /** Measure → stored unit ("ms", "hours", "bytes", "percent"); values display auto-scaled. */
+  measureUnits?: Record<string, string>;
+  /** Measure → number pattern ("#,##0.00") setting display precision. */
+  measureFormats?: Record<string, string>;
+  /** Display locale: "en-US", "en-GB", "en-IN", "de-DE", "fr-FR", "es-ES", "pt-BR". */
+  locale?: string;
+  /** Dated rates as "date,currency,rate" CSV text; exchangeRates is the fallback. */
+  rateHistory?: string;
+  rateDimension?: string;
+  rateMode?: "previous" | "average";
+  calendar?: Calendar;
+  multiValue?: Record<string, string>;
+  valueOrders?: Record<string, string[]>;
+  limits?: Limits;
+  explain?: boolean;
+}
+
+export interface Limits {
+  maxRecords?: number;
+  maxGroups?: number;
+  maxRows?: number;
 }
 
 export interface EngineResult {
@@ -98,6 +229,23 @@ export interface EngineResult {
   data?: any;
   displayUnit?: string;
   shouldConvert?: boolean;
+  rateDates?: Record<string, string[]>;
+  warnings?: string[];
+  trace?: Trace;
+}
+
+export interface Trace {
+  spec: QuerySpec;
+  path: "empty" | "cube" | "ratio" | "multi-measure" | "standard";
+  measure: string;
+  records: number;
+  filters?: { set?: string; dimension: string; values: string[]; indexed?: boolean; before: number; after: number }[];
+  matched: number;
+  currency?: { base: string; dimension: string; normalized: boolean; rates?: Record<string, number>; unconverted?: string[]; rateDates?: Record<string, string[]> };
+  groups: number;
+  returned: number;
+  stages: { stage: string; ms: number }[];
+  totalMs: number;
 }
 
 export interface ChartConfig {
@@ -105,12 +253,20 @@ export interface ChartConfig {
   title: string;
   xAxis: string;
   yAxis: string;
+  yAxisUnit?: Unit;
+  /** Locale the result was formatted for; use it for axis labels. */
+  locale?: string;
   series: ChartSeries[];
   colors?: string[];
   showLegend: boolean;
   showGrid: boolean;
 }
 
+export interface Unit {
+  name: string;
+  kind: "duration" | "data" | "percent" | "count" | "currency";
+}
+
 export interface ChartSeries {
   name: string;
   data: { label: string; value: number }[];
@@ -118,7 +274,7 @@ export interface ChartSeries {
 
 export interface TableData {
   title: string;
-  columns: { key: string; label: string; type: string; align: string }[];
+  columns: { key: string; label: string; type: string; align: string; unit?: Unit }[];
   rows: string[][];
   summary?: { label: string; values: Record<string, string> };
 }
@@ -154,9 +310,9 @@ export function discover(csv: string): SpektrResult<SchemaConfig>;
 export function refine(schema: SchemaConfig, apiKey: string, model: string): SpektrResult<SchemaConfig>;
 
 /**
- * Execute a QuerySpec against records.
+ * Execute a QuerySpec against records, or against a snapshot from snapshot().
  */
-export function execute(spec: QuerySpec, records: Record[], options?: ExecuteOptions): SpektrResult<EngineResult>;
+export function execute(spec: QuerySpec, records: Record[] | Uint8Array, options?: ExecuteOptions): SpektrResult<EngineResult>;
 
 /**
  * Translate natural language to QuerySpec using an AI provider.
@@ -175,6 +331,37 @@ export function translate(
  */
 export function parseCSV(csv: string, schema: SchemaConfig): SpektrResult<Record[]>;
 
+/**
+ * Encode CSV data as a binary snapshot (schema included).
+ * Store it and pass it to execute() in place of records to skip re-parsing.
+ * parseReport lists what parsing left out of the snapshot.
+ */
+export function snapshot(csv: string, schema?: SchemaConfig): SnapshotResult;
+
+/**
+ * Compute per-column statistics for CSV data. Without a schema, one is
+ * discovered first.
+ */
+export function profile(csv: string, schema?: SchemaConfig): SpektrResult<Profile>;
+
+/**
+ * Counters for the execute result cache. Repeated execute() calls with the
+ * same spec, options and data are answered from the cache.
+ */
+export interface CacheStats {
+  hits: number;
+  misses: number;
+  evictions: number;
+  expired: number;
+  entries: number;
+  bytes: number;
+}
+
+/**
+ * Get hit/miss counters for the execute result cache.
+ */
+export function cacheStats(): SpektrResult<CacheStats>;
+
 /**
  * Get Spektr WASM version.
  */
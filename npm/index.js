@@ -81,7 +81,7 @@ function refine(schema, apiKey, model) {
 /**
  * Execute a QuerySpec against records.
  * @param {object} spec - QuerySpec object
- * @param {Array} records - Array of { dimensions: {}, measures: {} }
+ * @param {Array|Uint8Array} records - Array of { dimensions: {}, measures: {} }, or a snapshot
  * @param {object} [options] - { defaultMeasure, baseCurrency, exchangeRates }
  * @returns {{ ok: boolean, data?: object, error?: string }}
  */
@@ -89,7 +89,7 @@ function execute(spec, records, options) {
   ensureInit();
   return globalThis.__spektr.execute(
     JSON.stringify(spec),
-    JSON.stringify(records),
+    records instanceof Uint8Array ? records : JSON.stringify(records),
     options ? JSON.stringify(options) : undefined
   );
 }
@@ -127,6 +127,27 @@ function parseCSV(csv, schema) {
   return globalThis.__spektr.parseCSV(csv, JSON.stringify(schema));
 }
 
+/**
+ * Encode CSV data as a binary snapshot (schema included).
+ * Pass the bytes to execute() in place of records to skip re-parsing.
+ * @param {string} csv - CSV string
+ * @param {object} [schema] - Schema config (auto-detected when omitted)
+ * @returns {{ ok: boolean, data?: Uint8Array, parseReport?: object, error?: string }}
+ */
+function snapshot(csv, schema) {
+  ensureInit();
+  return globalThis.__spektr.snapshot(csv, schema ? JSON.stringify(schema) : undefined);
+}
+
+/**
+ * Get hit/miss counters for the execute result cache.
+ * @returns {{ ok: boolean, data?: object, error?: string }}
+ */
+function cacheStats() {
+  ensureInit();
+  return globalThis.__spektr.cacheStats();
+}
+
 /**
  * Get Spektr WASM version.
  * @returns {{ ok: boolean, data?: string, error?: string }}
@@ -143,5 +164,7 @@ module.exports = {
   execute,
   translate,
   parseCSV,
+  snapshot,
+  cacheStats,
   version,
 };
\ No newline at end of file
@@ -0,0 +1,163 @@
+// Package observe is Spektr's instrumentation boundary: structured logs,
+// spans and metrics from the engine, translator and Smart Refine go to an
+// Observer instead of the global log package.
+//
+// The default Observer discards everything. Route events to log/slog:
+//
+//	observe.SetDefault(observe.NewSlog(slog.Default()))
+//
+// or inject one per call (engine.WithObserver, translator.WithObserver,
+// schema.RefineConfig.Observer). To export spans to OpenTelemetry, implement
+// Observer in your own module — Spektr itself takes no dependency:
+//
+//	type otelObserver struct{ tracer trace.Tracer }
+//
+//	func (o otelObserver) Start(ctx context.Context, name string, attrs ...observe.Attr) (context.Context, observe.Span) {
+//	    ctx, span := o.tracer.Start(ctx, name, trace.WithAttributes(toOTel(attrs)...))
+//	    return ctx, otelSpan{span}
+//	}
+//
+// Observers must be safe for concurrent use.
+package observe
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// OBSERVER — logs, spans and metrics
+// ============================================================================
+// Span names used by Spektr:
+//
+//   spektr.execute            one engine query; child spans per stage
+//                             (spektr.execute.filter, .group, ...)
+//   spektr.translate          one natural-language translation
+//   spektr.refine             one Smart Refine call
+//   spektr.ai.complete        one AI provider call; adapters add token usage
+//                             (ai.tokens.prompt, ai.tokens.completion,
+//                             ai.tokens.total)
+//
+// Metrics: spektr.execute.records, spektr.execute.matched,
+// spektr.execute.duration_ms, spektr.ai.latency_ms, spektr.ai.tokens.
+// ============================================================================
+
+// Level is a log severity.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+// Attr is a key-value attribute on a log line, span or metric.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Attr             { return Attr{key, value} }
+func Int(key string, value int) Attr            { return Attr{key, value} }
+func Float64(key string, value float64) Attr    { return Attr{key, value} }
+func Bool(key string, value bool) Attr          { return Attr{key, value} }
+func Strings(key string, value []string) Attr   { return Attr{key, value} }
+func Duration(key string, d time.Duration) Attr { return Attr{key, d} }
+
+// Observer receives instrumentation events.
+type Observer interface {
+	// Start begins a span. The returned context carries the span for
+	// child spans; the caller ends the span.
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+	// Log records a structured message.
+	Log(ctx context.Context, level Level, msg string, attrs ...Attr)
+	// Metric records one measurement (a count, a latency, token usage).
+	Metric(ctx context.Context, name string, value float64, attrs ...Attr)
+}
+
+// Span is one timed operation.
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	RecordError(err error)
+	End()
+}
+
+// ============================================================================
+// DEFAULT OBSERVER & CONTEXT PROPAGATION
+// ============================================================================
+
+type holder struct{ o Observer }
+
+var defaultObserver atomic.Pointer[holder]
+
+// SetDefault sets the Observer used when none is injected. nil restores
+// the no-op observer.
+func SetDefault(o Observer) {
+	if o == nil {
+		o = Nop()
+	}
+	defaultObserver.Store(&holder{o})
+}
+
+// Default returns the Observer used when none is injected.
+func Default() Observer {
+	if h := defaultObserver.Load(); h != nil {
+		return h.o
+	}
+	return Nop()
+}
+
+// Or returns o, or Default() when o is nil.
+func Or(o Observer) Observer {
+	if o == nil {
+		return Default()
+	}
+	return o
+}
+
+type contextKey struct{}
+
+type active struct {
+	o    Observer
+	span Span
+}
+
+// Start begins a span on o and records o and the span in the returned
+// context, so code that only receives the context (AI adapters) can
+// annotate the span and emit metrics via FromContext.
+func Start(ctx context.Context, o Observer, name string, attrs ...Attr) (context.Context, Span) {
+	ctx, span := o.Start(ctx, name, attrs...)
+	return context.WithValue(ctx, contextKey{}, active{o, span}), span
+}
+
+// FromContext returns the observer and innermost span recorded by Start,
+// or the default observer and a no-op span.
+func FromContext(ctx context.Context) (Observer, Span) {
+	if a, ok := ctx.Value(contextKey{}).(active); ok {
+		return a.o, a.span
+	}
+	return Default(), nopSpan{}
+}
+
+// ============================================================================
+// NO-OP OBSERVER
+// ============================================================================
+
+// Nop returns an Observer that discards everything.
+func Nop() Observer { return nop{} }
+
+type nop struct{}
+
+func (nop) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+func (nop) Log(context.Context, Level, string, ...Attr)      {}
+func (nop) Metric(context.Context, string, float64, ...Attr) {}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...Attr) {}
+func (nopSpan) RecordError(error)     {}
+func (nopSpan) End()                  {}
@@ -0,0 +1,85 @@
+package observe
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// SLOG OBSERVER
+// ============================================================================
+// Log calls map to slog records at the same level. Spans are logged when
+// they end — at Debug, or at Error when an error was recorded — with their
+// attributes and duration_ms. Metrics are logged at Debug.
+// ============================================================================
+
+// NewSlog returns an Observer that writes to logger (slog.Default() if nil).
+func NewSlog(logger *slog.Logger) Observer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogObserver{logger: logger}
+}
+
+type slogObserver struct {
+	logger *slog.Logger
+}
+
+func (o *slogObserver) Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	return ctx, &slogSpan{logger: o.logger, ctx: ctx, name: name, start: time.Now(), attrs: attrs}
+}
+
+func (o *slogObserver) Log(ctx context.Context, level Level, msg string, attrs ...Attr) {
+	o.logger.LogAttrs(ctx, slog.Level(level), msg, toSlog(attrs)...)
+}
+
+func (o *slogObserver) Metric(ctx context.Context, name string, value float64, attrs ...Attr) {
+	o.logger.LogAttrs(ctx, slog.LevelDebug, "metric",
+		append([]slog.Attr{slog.String("metric", name), slog.Float64("value", value)}, toSlog(attrs)...)...)
+}
+
+type slogSpan struct {
+	logger *slog.Logger
+	ctx    context.Context
+	name   string
+	start  time.Time
+
+	mu    sync.Mutex
+	attrs []Attr
+	err   error
+}
+
+func (s *slogSpan) SetAttributes(attrs ...Attr) {
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+func (s *slogSpan) RecordError(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+func (s *slogSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	level := slog.LevelDebug
+	attrs := append([]slog.Attr{slog.String("span", s.name),
+		slog.Float64("duration_ms", float64(time.Since(s.start).Microseconds())/1000)}, toSlog(s.attrs)...)
+	if s.err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", s.err.Error()))
+	}
+	s.logger.LogAttrs(s.ctx, level, "span", attrs...)
+}
+
+func toSlog(attrs []Attr) []slog.Attr {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = slog.Any(a.Key, a.Value)
+	}
+	return out
+}
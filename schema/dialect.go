@@ -0,0 +1,657 @@
+package schema
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// CSV DIALECT — delimiter, quoting, encoding, header and footer rows
+// ============================================================================
+// Not every "CSV" is comma-separated UTF-8 with the header on line 1:
+//
+//   Name\tAmount           TSV
+//   Name;Betrag            semicolon exports (European Excel)
+//   FF FE N\0a\0...        UTF-16 Excel "Unicode text"
+//   EF BB BF Name,...      UTF-8 with a byte-order mark
+//   Sales report,,         title rows above the header
+//   Total,,1234            summary rows below the data
+//
+// A trailing row is only a summary row with evidence beyond its label
+// ("Total Gym" is a product): its other text cells are blank, or each of
+// its numbers is its column's total.
+//
+// SniffDialect works all of these out from the raw bytes. DiscoverFromCSV
+// records the result in Config.Dialect, and helpers.ParseCSV reads later
+// files with it, so every parse of a dataset splits rows the same way.
+// ============================================================================
+
+// Dialect describes how a delimited text file is laid out.
+type Dialect struct {
+	Delimiter  string `json:"delimiter"`            // ",", ";", "\t" or "|"
+	Quote      string `json:"quote,omitempty"`      // "\"" (default) or "none": quote characters are literal text
+	Encoding   string `json:"encoding,omitempty"`   // "utf-8" (default), "utf-16le", "utf-16be", "windows-1252"
+	HeaderRow  int    `json:"headerRow,omitempty"`  // Rows above the header (titles, notes) to skip
+	FooterRows int    `json:"footerRows,omitempty"` // Most trailing summary rows ("Total", "Grand total") to drop; each is checked again when read
+}
+
+// Encodings recognised by SniffDialect.
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingCP1252  = "windows-1252"
+)
+
+const (
+	sniffBytes    = 64 << 10 // prefix inspected by SniffDialect
+	sniffRows     = 50       // rows scored per delimiter candidate
+	maxHeaderRow  = 20       // title rows tolerated above the header
+	maxFooterRows = 3        // summary rows tolerated below the data
+)
+
+// delimiterCandidates in order of preference when scores tie.
+var delimiterCandidates = []rune{',', ';', '\t', '|'}
+
+// summaryLabel matches the first cell of a totals row.
+var summaryLabel = regexp.MustCompile(`(?i)^(grand\s+total|sub-?total|totals?|sum|summe|gesamt|gesamtsumme|insgesamt|total général|totale)\b`)
+
+// DefaultDialect is plain RFC 4180 CSV.
+func DefaultDialect() Dialect {
+	return Dialect{Delimiter: ",", Encoding: EncodingUTF8}
+}
+
+// SniffDialect inspects data and returns its dialect. Files it cannot make
+// sense of get DefaultDialect.
+func SniffDialect(data []byte) Dialect {
+	head := data
+	if len(head) > sniffBytes {
+		head = head[:sniffBytes]
+	}
+	d := sniff(head, len(head) == len(data))
+	if len(data) > sniffBytes {
+		// Without the rows above the tail its totals cannot be checked, so
+		// this is the most the reader may drop; it checks each one
+		d.FooterRows = countFooterRows(d, tailOf(data, d.Encoding), false)
+	}
+	return d
+}
+
+// SniffReader is SniffDialect for a stream. It inspects a prefix of r and
+// returns the dialect together with a reader that replays the whole stream.
+// When the prefix is not the whole stream, footer rows cannot be counted up
+// front; FooterRows is then the most the reader will drop, and only rows
+// that look like totals are dropped.
+func SniffReader(r io.Reader) (Dialect, io.Reader, error) {
+	br := bufio.NewReaderSize(r, sniffBytes)
+	head, err := br.Peek(sniffBytes)
+	complete := err == io.EOF
+	if err != nil && !complete {
+		return Dialect{}, nil, err
+	}
+	d := sniff(head, complete)
+	if !complete {
+		d.FooterRows = maxFooterRows
+	}
+	return d, br, nil
+}
+
+// sniff decides the dialect from a prefix of the file. complete reports
+// whether the prefix is the whole file, so its last row is whole and its
+// trailing rows are the file's footer.
+func sniff(head []byte, complete bool) Dialect {
+	d := DefaultDialect()
+	d.Encoding = detectEncoding(head, complete)
+
+	text, _ := io.ReadAll(decodeReader(bytes.NewReader(head), d.Encoding))
+	if !complete {
+		// Drop the partial last line
+		if i := bytes.LastIndexByte(text, '\n'); i >= 0 {
+			text = text[:i+1]
+		}
+	}
+
+	best, bestScore, bestWidth := ',', -1, 0
+	var bestRows [][]string
+	for _, c := range delimiterCandidates {
+		rows := readSample(text, c)
+		width, score := modalWidth(rows)
+		if width < 2 {
+			continue
+		}
+		if score > bestScore || (score == bestScore && width > bestWidth) {
+			best, bestScore, bestWidth, bestRows = c, score, width, rows
+		}
+	}
+	if bestRows == nil {
+		bestRows = readSample(text, ',')
+		bestWidth, _ = modalWidth(bestRows)
+	}
+	d.Delimiter = string(best)
+
+	// Quote characters inside unquoted fields break strict parsing. A
+	// missing closing quote at the cut of a partial prefix does not count.
+	strict := csv.NewReader(bytes.NewReader(text))
+	strict.Comma = best
+	strict.FieldsPerRecord = -1
+	if _, err := strict.ReadAll(); errors.Is(err, csv.ErrBareQuote) || (complete && errors.Is(err, csv.ErrQuote)) {
+		d.Quote = "none"
+	}
+
+	// The header is the first row as wide as the data and mostly filled in
+	for i, row := range bestRows {
+		if i >= maxHeaderRow {
+			break
+		}
+		if len(row) == bestWidth && filledCells(row)*2 >= bestWidth {
+			d.HeaderRow = i
+			break
+		}
+	}
+
+	if complete {
+		d.FooterRows = countFooterRows(d, text, true)
+	}
+	return d
+}
+
+// NewReader returns a reader for data in dialect d. The first Read returns
+// the header row (title rows above it are skipped); rows wider or narrower
+// than the header are returned with csv.ErrFieldCount, as with a plain
+// csv.Reader.
+func (d Dialect) NewReader(r io.Reader) *CSVReader {
+	cr := csv.NewReader(decodeReader(r, d.Encoding))
+	if c, _ := utf8.DecodeRuneInString(d.Delimiter); d.Delimiter != "" && c != utf8.RuneError {
+		cr.Comma = c
+	}
+	cr.LazyQuotes = d.Quote == "none"
+	cr.FieldsPerRecord = -1
+	return &CSVReader{dialect: d, r: cr}
+}
+
+// CSVReader reads rows in a Dialect: decoded, split on its delimiter, with
+// title rows and trailing summary rows removed.
+type CSVReader struct {
+	// ReuseRecord is csv.Reader.ReuseRecord. It applies to data rows only,
+	// and only when the dialect has no footer rows to hold back.
+	ReuseRecord bool
+
+	dialect Dialect
+	r       *csv.Reader
+	started bool
+	pending [][]string
+	totals  columnTotals // of the data rows returned so far
+	eof     bool
+	dropped int
+}
+
+// Read returns the next row, or io.EOF.
+func (c *CSVReader) Read() ([]string, error) {
+	if !c.started {
+		c.started = true
+		for i := 0; i < c.dialect.HeaderRow; i++ {
+			if _, err := c.r.Read(); err == io.EOF {
+				return nil, err
+			}
+		}
+		header, err := c.r.Read()
+		if err == nil {
+			c.r.FieldsPerRecord = len(header)
+			c.r.ReuseRecord = c.ReuseRecord && c.dialect.FooterRows == 0
+		}
+		return header, err
+	}
+	if c.dialect.FooterRows <= 0 {
+		return c.r.Read()
+	}
+
+	// Hold back enough rows to drop a footer once the end is reached
+	for !c.eof && len(c.pending) <= c.dialect.FooterRows {
+		row, err := c.r.Read()
+		if err == io.EOF {
+			c.eof = true
+			c.dropped = c.totals.countFooters(c.pending, c.dialect.FooterRows)
+			c.pending = c.pending[:len(c.pending)-c.dropped]
+			break
+		}
+		if err != nil {
+			return row, err
+		}
+		c.pending = append(c.pending, row)
+	}
+	if len(c.pending) == 0 {
+		return nil, io.EOF
+	}
+	row := c.pending[0]
+	c.pending = c.pending[1:]
+	if !c.eof {
+		c.totals.add(row)
+	}
+	return row, nil
+}
+
+// FooterRowsDropped reports how many summary rows were dropped at the end.
+func (c *CSVReader) FooterRowsDropped() int {
+	return c.dropped
+}
+
+// IsSummaryRow reports whether row is labelled like a totals row: its first
+// non-empty cell is "Total", "Grand total", "Sum" or similar. The label
+// alone is not enough to drop a row; see columnTotals.isFooter.
+func IsSummaryRow(row []string) bool {
+	return summaryLabelAt(row) >= 0
+}
+
+// summaryLabelAt returns the index of row's summary label, or -1.
+func summaryLabelAt(row []string) int {
+	for i, cell := range row {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			if summaryLabel.MatchString(cell) {
+				return i
+			}
+			return -1
+		}
+	}
+	return -1
+}
+
+// ============================================================================
+// FOOTER EVIDENCE
+// ============================================================================
+
+// decimalConventions are the separators numbers are summed under, since the
+// dialect does not know a column's convention.
+var decimalConventions = [2]string{".", ","}
+
+// columnTotals sums each column's numbers over the data rows above a
+// candidate footer, once per decimal convention.
+type columnTotals struct {
+	sums [len(decimalConventions)][]float64
+}
+
+func (t *columnTotals) add(row []string) {
+	for c, decimal := range decimalConventions {
+		for len(t.sums[c]) < len(row) {
+			t.sums[c] = append(t.sums[c], 0)
+		}
+		for i, cell := range row {
+			if f, ok := ParseNumber(cell, decimal); ok {
+				t.sums[c][i] += f
+			}
+		}
+	}
+}
+
+// isFooter reports whether row totals the rows added: it has a summary
+// label, and its other cells are blank or numbers with either at least one
+// blank (no dimension values) or every number equal to its column's total.
+func (t *columnTotals) isFooter(row []string) bool {
+	label := summaryLabelAt(row)
+	if label < 0 {
+		return false
+	}
+	blanks, numbers := 0, 0
+	for i, cell := range row {
+		if cell = strings.TrimSpace(cell); i == label {
+			continue
+		} else if cell == "" {
+			blanks++
+		} else if _, ok := ParseNumber(cell, "."); ok {
+			numbers++
+		} else if _, ok := ParseNumber(cell, ","); ok {
+			numbers++
+		} else {
+			return false // a dimension value: a data row
+		}
+	}
+	if blanks > 0 {
+		return true
+	}
+	if numbers == 0 {
+		return false
+	}
+	for c, decimal := range decimalConventions {
+		if t.totals(row, label, c, decimal) {
+			return true
+		}
+	}
+	return false
+}
+
+// totals reports whether every number in row equals its column's sum under
+// one decimal convention. Totals rounded for display (to 0.01) still match.
+func (t *columnTotals) totals(row []string, label, c int, decimal string) bool {
+	for i, cell := range row {
+		if i == label {
+			continue
+		}
+		f, ok := ParseNumber(cell, decimal)
+		if !ok || i >= len(t.sums[c]) {
+			return false
+		}
+		sum := t.sums[c][i]
+		if math.Abs(f-sum) > 0.005+1e-9*math.Abs(sum) {
+			return false
+		}
+	}
+	return true
+}
+
+// countFooters returns how many trailing rows of rows (at most max) are
+// footers. t holds the totals of the rows above rows; the rows above the
+// labelled candidates are added to it.
+func (t *columnTotals) countFooters(rows [][]string, max int) int {
+	k := len(rows)
+	for k > 0 && len(rows)-k < max && IsSummaryRow(rows[k-1]) {
+		k--
+	}
+	for _, row := range rows[:k] {
+		t.add(row)
+	}
+	n := 0
+	for i := len(rows) - 1; i >= k && t.isFooter(rows[i]); i-- {
+		n++
+	}
+	return n
+}
+
+// ============================================================================
+// SNIFFING HELPERS
+// ============================================================================
+
+// readSample parses up to sniffRows rows of text with delimiter c.
+func readSample(text []byte, c rune) [][]string {
+	r := csv.NewReader(bytes.NewReader(text))
+	r.Comma = c
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	var rows [][]string
+	for len(rows) < sniffRows {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// modalWidth returns the most common row width and how many rows have it.
+// Wider widths win ties, so "a;1,5;2" splits on ";" rather than ",".
+func modalWidth(rows [][]string) (width, count int) {
+	counts := make(map[int]int)
+	for _, row := range rows {
+		counts[len(row)]++
+	}
+	for w, n := range counts {
+		if n > count || (n == count && w > width) {
+			width, count = w, n
+		}
+	}
+	return width, count
+}
+
+func filledCells(row []string) int {
+	n := 0
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// countFooterRows counts the summary rows at the end of text. When text is
+// the whole file (verify), each must total the rows above it; otherwise
+// only its label is checked.
+func countFooterRows(d Dialect, text []byte, verify bool) int {
+	r := d.NewReader(bytes.NewReader(text)).r
+	var totals columnTotals
+	var tail [][]string
+	for read := 0; ; {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		tail = append(tail, row)
+		if len(tail) > maxFooterRows+1 {
+			// Rows leaving the tail are data, below the title and header rows
+			if read > d.HeaderRow {
+				totals.add(tail[0])
+			}
+			tail = tail[1:]
+		}
+		read++
+	}
+	// Keep at least one row: a file of only totals has no footer
+	max := maxFooterRows
+	if len(tail)-1 < max {
+		max = len(tail) - 1
+	}
+	if !verify {
+		n := 0
+		for i := len(tail) - 1; n < max && IsSummaryRow(tail[i]); i-- {
+			n++
+		}
+		return n
+	}
+	return totals.countFooters(tail, max)
+}
+
+// tailOf returns the last few KB of data, starting on a line boundary, in
+// the file's encoding.
+func tailOf(data []byte, encoding string) []byte {
+	const tailBytes = 8 << 10
+	if len(data) <= tailBytes {
+		return data
+	}
+	start := len(data) - tailBytes
+	if encoding == EncodingUTF16LE || encoding == EncodingUTF16BE {
+		start &^= 1
+		// Step past the next "\n" code unit
+		for ; start+1 < len(data); start += 2 {
+			if (encoding == EncodingUTF16LE && data[start] == '\n' && data[start+1] == 0) ||
+				(encoding == EncodingUTF16BE && data[start] == 0 && data[start+1] == '\n') {
+				return data[start+2:]
+			}
+		}
+		return nil
+	}
+	if i := bytes.IndexByte(data[start:], '\n'); i >= 0 {
+		return data[start+i+1:]
+	}
+	return nil
+}
+
+// ============================================================================
+// ENCODING
+// ============================================================================
+
+// detectEncoding reads the byte-order mark, or failing that the pattern of
+// NUL bytes (UTF-16 text is mostly ASCII with a zero in every other byte),
+// or failing that whether the bytes are valid UTF-8.
+func detectEncoding(head []byte, complete bool) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return EncodingUTF8
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return EncodingUTF16BE
+	}
+
+	var even, odd int
+	for i, b := range head {
+		if b == 0 {
+			if i%2 == 0 {
+				even++
+			} else {
+				odd++
+			}
+		}
+	}
+	if half := len(head) / 2; half > 0 {
+		switch {
+		case odd*10 > half*3 && even*10 < half:
+			return EncodingUTF16LE
+		case even*10 > half*3 && odd*10 < half:
+			return EncodingUTF16BE
+		}
+	}
+
+	check := head
+	if !complete {
+		// A multi-byte rune may straddle the cut
+		for i := 0; i < utf8.UTFMax-1 && len(check) > 0 && !utf8.RuneStart(check[len(check)-1]); i++ {
+			check = check[:len(check)-1]
+		}
+		if len(check) > 0 && check[len(check)-1] >= utf8.RuneSelf {
+			check = check[:len(check)-1]
+		}
+	}
+	if !utf8.Valid(check) {
+		return EncodingCP1252
+	}
+	return EncodingUTF8
+}
+
+// ContextReader returns r as a reader that fails with ctx.Err() once ctx is
+// done, so a parse or discovery pass over it stops at the next buffered read.
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	if ctx.Done() == nil {
+		return r
+	}
+	return &contextReader{ctx: ctx, r: r}
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// decodeReader converts r from encoding to UTF-8 and drops a leading
+// byte-order mark.
+func decodeReader(r io.Reader, encoding string) io.Reader {
+	return &decoder{r: bufio.NewReader(r), encoding: encoding, start: true}
+}
+
+type decoder struct {
+	r        *bufio.Reader
+	encoding string
+	start    bool
+	buf      []byte
+	err      error
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.fill()
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// fill decodes the next chunk of input into buf.
+func (d *decoder) fill() {
+	const chunk = 4096
+	switch d.encoding {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		var pair [2]byte
+		for len(d.buf) < chunk {
+			u, err := d.unit(&pair)
+			if err != nil {
+				d.err = err
+				return
+			}
+			rn := rune(u)
+			if utf16.IsSurrogate(rn) {
+				lo, err := d.unit(&pair)
+				if err != nil {
+					d.err = err
+					return
+				}
+				rn = utf16.DecodeRune(rn, rune(lo))
+			}
+			if d.start {
+				d.start = false
+				if rn == '\uFEFF' {
+					continue
+				}
+			}
+			d.buf = utf8.AppendRune(d.buf, rn)
+		}
+	case EncodingCP1252:
+		for len(d.buf) < chunk {
+			b, err := d.r.ReadByte()
+			if err != nil {
+				d.err = err
+				return
+			}
+			d.buf = utf8.AppendRune(d.buf, cp1252Rune(b))
+		}
+	default:
+		if d.start {
+			d.start = false
+			if bom, _ := d.r.Peek(3); bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+				d.r.Discard(3)
+			}
+		}
+		buf := make([]byte, chunk)
+		n, err := d.r.Read(buf)
+		d.buf = buf[:n]
+		d.err = err
+	}
+}
+
+// unit reads one UTF-16 code unit.
+func (d *decoder) unit(pair *[2]byte) (uint16, error) {
+	if _, err := io.ReadFull(d.r, pair[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF // odd trailing byte
+		}
+		return 0, err
+	}
+	if d.encoding == EncodingUTF16BE {
+		return uint16(pair[0])<<8 | uint16(pair[1]), nil
+	}
+	return uint16(pair[1])<<8 | uint16(pair[0]), nil
+}
+
+// cp1252High maps Windows-1252 bytes 0x80–0x9F; the rest match Latin-1.
+var cp1252High = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+func cp1252Rune(b byte) rune {
+	if b >= 0x80 && b < 0xA0 {
+		return cp1252High[b-0x80]
+	}
+	return rune(b)
+}
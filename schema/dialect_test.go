@@ -0,0 +1,193 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// ============================================================================
+// DIALECT TESTS — delimiters, encodings, title and summary rows
+// ============================================================================
+
+func readAllRows(t *testing.T, d Dialect, data []byte) [][]string {
+	t.Helper()
+	r := d.NewReader(bytes.NewReader(data))
+	var rows [][]string
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func utf16LE(s string, bom bool) []byte {
+	var out []byte
+	if bom {
+		out = append(out, 0xFF, 0xFE)
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(u), byte(u>>8))
+	}
+	return out
+}
+
+func TestSniffDialect(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		want   Dialect
+		header string
+		rows   int
+	}{
+		{"comma", []byte("Region,Amount\nNorth,10\nSouth,20\n"),
+			Dialect{Delimiter: ",", Encoding: EncodingUTF8}, "Region", 2},
+		{"tsv", []byte("Region\tAmount\nNorth\t1,5\nSouth\t2\n"),
+			Dialect{Delimiter: "\t", Encoding: EncodingUTF8}, "Region", 2},
+		{"semicolon", []byte("Region;Betrag;Menge\nNord;1,5;2\nSüd;2,25;3\n"),
+			Dialect{Delimiter: ";", Encoding: EncodingUTF8}, "Region", 2},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFRegion,Amount\nNorth,10\n"),
+			Dialect{Delimiter: ",", Encoding: EncodingUTF8}, "Region", 1},
+		{"utf-16le", utf16LE("Region\tAmount\r\nNörth\t10\r\nSouth\t20\r\n", true),
+			Dialect{Delimiter: "\t", Encoding: EncodingUTF16LE}, "Region", 2},
+		{"utf-16le without bom", utf16LE("Region,Amount\nNorth,10\nSouth,20\n", false),
+			Dialect{Delimiter: ",", Encoding: EncodingUTF16LE}, "Region", 2},
+		{"windows-1252", []byte("Region,Amount\nK\xF6ln,10\nNord,20\n"),
+			Dialect{Delimiter: ",", Encoding: EncodingCP1252}, "Region", 2},
+		{"title rows", []byte("Sales report,,\nGenerated 2026-01-31,,\nRegion,Product,Amount\nNorth,A,10\nSouth,B,20\n"),
+			Dialect{Delimiter: ",", Encoding: EncodingUTF8, HeaderRow: 2}, "Region", 2},
+		{"summary rows", []byte("Region,Product,Amount\nNorth,A,10\nSouth,B,20\nTotal,,30\nGrand total,,30\n"),
+			Dialect{Delimiter: ",", Encoding: EncodingUTF8, FooterRows: 2}, "Region", 2},
+		{"literal quotes", []byte("Item,Size\n12\" pipe,large\nbolt,small\n"),
+			Dialect{Delimiter: ",", Quote: "none", Encoding: EncodingUTF8}, "Item", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SniffDialect(tt.data)
+			if got != tt.want {
+				t.Fatalf("SniffDialect = %+v, want %+v", got, tt.want)
+			}
+			rows := readAllRows(t, got, tt.data)
+			if len(rows) != tt.rows+1 || rows[0][0] != tt.header {
+				t.Errorf("rows = %q", rows)
+			}
+			for _, row := range rows {
+				if IsSummaryRow(row) {
+					t.Errorf("summary row not dropped: %q", row)
+				}
+			}
+		})
+	}
+}
+
+func TestSniffReaderLargeStream(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Region;Amount\n")
+	for b.Len() < 2*sniffBytes {
+		b.WriteString("North;10\nSouth;20\n")
+	}
+	pairs := (b.Len() - len("Region;Amount\n")) / len("North;10\nSouth;20\n")
+	fmt.Fprintf(&b, "Total;%d\n", 30*pairs)
+
+	d, r, err := SniffReader(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("SniffReader failed: %v", err)
+	}
+	if d.Delimiter != ";" || d.FooterRows != maxFooterRows {
+		t.Fatalf("dialect = %+v", d)
+	}
+	reader := d.NewReader(r)
+	var last []string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		last = row
+	}
+	if last[0] != "South" || reader.FooterRowsDropped() != 1 {
+		t.Errorf("last row = %q, dropped = %d", last, reader.FooterRowsDropped())
+	}
+	if got := SniffDialect([]byte(b.String())); got.FooterRows != 1 {
+		t.Errorf("SniffDialect footer rows = %d, want 1", got.FooterRows)
+	}
+}
+
+func TestSummaryLabelNeedsEvidence(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		footer int
+		last   string
+	}{
+		{"product named like a total", "product,units\nBowflex,3\nPeloton,4\nTotal Gym,5\n", 0, "Total Gym"},
+		{"sum of a column is not its total", "product,units\nBowflex,3\nPeloton,4\nSum Bike,6\n", 0, "Sum Bike"},
+		{"total with a dimension value", "region,team,units\nNorth,A,3\nSouth,B,4\nTotal,C,7\n", 0, "Total"},
+		{"two-column total", "product,units\nBowflex,3\nPeloton,4\nTotal,7\n", 1, "Peloton"},
+		{"decimal-comma total", "Produkt;Betrag\nA;1,5\nB;2,25\nSumme;3,75\n", 1, "B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.data)
+			d := SniffDialect(data)
+			if d.FooterRows != tt.footer {
+				t.Errorf("SniffDialect footer rows = %d, want %d", d.FooterRows, tt.footer)
+			}
+			// The reader checks again, even with a footer allowance
+			d.FooterRows = maxFooterRows
+			rows := readAllRows(t, d, data)
+			if last := rows[len(rows)-1][0]; last != tt.last {
+				t.Errorf("last row = %q, want %q", last, tt.last)
+			}
+		})
+	}
+}
+
+func TestDiscoverRecordsDialect(t *testing.T) {
+	data := []byte("Quarterly export;;\n" +
+		"Region;Team;Betrag\n" +
+		"Nord;A;\"1.234,56\"\n" +
+		"Süd;B;\"2.000,10\"\n" +
+		"West;A;\"15,00\"\n" +
+		"Summe;;\"3.249,66\"\n")
+
+	cfg, err := DiscoverFromCSV(data)
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	want := Dialect{Delimiter: ";", Encoding: EncodingUTF8, HeaderRow: 1, FooterRows: 1}
+	if cfg.Dialect == nil || *cfg.Dialect != want {
+		t.Fatalf("dialect = %+v, want %+v", cfg.Dialect, want)
+	}
+	var keys []string
+	for _, d := range cfg.Dimensions {
+		keys = append(keys, d.Key)
+		if d.Key == "region" {
+			for _, v := range d.SampleValues {
+				if v == "Summe" {
+					t.Errorf("summary row sampled: %v", d.SampleValues)
+				}
+			}
+		}
+	}
+	if !strings.Contains(strings.Join(keys, ","), "region") {
+		t.Errorf("dimensions = %v", keys)
+	}
+
+	streamed, err := DiscoverFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("DiscoverFromReader failed: %v", err)
+	}
+	if streamed.Dialect == nil || *streamed.Dialect != want {
+		t.Errorf("streamed dialect = %+v, want %+v", streamed.Dialect, want)
+	}
+}
@@ -1,12 +1,13 @@
 package schema
 
 import (
-	"encoding/csv"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"math"
 	"regexp"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -21,7 +22,7 @@ import (
 // Classification pipeline per column:
 //   1. Sample values → detect type (numeric, date, bool, string)
 //   2. Type + cardinality → classify role (dimension, measure, skip)
-//   3. Pattern matching → detect special types (currency, temporal, hierarchy)
+//   3. Pattern matching → detect special types (currency, temporal, hierarchy, multi-value)
 //   4. Generate synthetic measures (record_count)
 //   5. Generate derived dimensions (date → month, quarter, year buckets)
 //
@@ -30,9 +31,10 @@ import (
 
 // DiscoverOptions controls discovery behavior.
 type DiscoverOptions struct {
-	SampleSize     int      // Max rows to inspect (0 = all). Default: 1000
+	SampleSize     int      // Rows sampled from the whole file (0 = up to 100000). Default: 1000
 	RecoverColumns []string // Force-include columns that were auto-skipped
 	Name           string   // Dataset name override (otherwise inferred)
+	Profile        bool     // Attach a data profile of the whole file (DiscoverFromCSV only)
 }
 
 // DefaultDiscoverOptions returns sensible defaults.
@@ -44,42 +46,93 @@ func DefaultDiscoverOptions() DiscoverOptions {
 
 // DiscoverFromCSV generates a schema.Config by inspecting CSV data.
 // Returns a complete Config with dimensions, measures, skipped columns, and defaults.
+// Columns are classified from a sample drawn across the whole file.
 func DiscoverFromCSV(data []byte, opts ...DiscoverOptions) (*Config, error) {
+	return DiscoverFromCSVContext(context.Background(), data, opts...)
+}
+
+// DiscoverFromCSVContext is DiscoverFromCSV with cancellation: it returns an
+// error wrapping ctx.Err() once ctx is cancelled or its deadline passes.
+func DiscoverFromCSVContext(ctx context.Context, data []byte, opts ...DiscoverOptions) (*Config, error) {
 	opt := DefaultDiscoverOptions()
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
-	reader := csv.NewReader(strings.NewReader(string(data)))
+	dialect := SniffDialect(data)
+	reader := dialect.NewReader(ContextReader(ctx, bytes.NewReader(data)))
 
 	// 1. Read headers
-	headers, err := reader.Read()
+	headers, err := readDiscoveryHeaders(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV headers: %w", err)
+		return nil, err
 	}
 
-	if len(headers) == 0 {
-		return nil, fmt.Errorf("CSV has no columns")
+	// 2. Sample rows from the whole file
+	sample, err := sampleRows(reader, len(headers), opt)
+	if err != nil {
+		return nil, err
 	}
 
-	// 2. Read sample rows
-	var rows [][]string
-	limit := opt.SampleSize
-	if limit <= 0 {
-		limit = 100000 // safety cap
+	config, err := discoverFromRows(headers, sample, dialect, opt)
+	if err != nil {
+		return nil, err
+	}
+	if !opt.Profile {
+		return config, nil
 	}
 
-	for i := 0; i < limit; i++ {
-		row, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			continue // skip malformed rows
-		}
-		rows = append(rows, row)
+	// 3. Optional profile: a second pass over every row
+	if config.Profile, err = ProfileCSVContext(ctx, data, *config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// DiscoverFromReader is DiscoverFromCSV for a stream too large to hold in
+// memory. Only the sampled rows are kept (see rowSampler). The stream is
+// read once, so DiscoverOptions.Profile is ignored; run ProfileReader over
+// the data again with the returned schema instead.
+func DiscoverFromReader(r io.Reader, opts ...DiscoverOptions) (*Config, error) {
+	opt := DefaultDiscoverOptions()
+	if len(opts) > 0 {
+		opt = opts[0]
 	}
 
+	dialect, r, err := SniffReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	reader := dialect.NewReader(r)
+	headers, err := readDiscoveryHeaders(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	sample, err := sampleRows(reader, len(headers), opt)
+	if err != nil {
+		return nil, err
+	}
+	dialect.FooterRows = reader.FooterRowsDropped()
+
+	return discoverFromRows(headers, sample, dialect, opt)
+}
+
+// readDiscoveryHeaders reads and checks the header row.
+func readDiscoveryHeaders(reader *CSVReader) ([]string, error) {
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV headers: %w", err)
+	}
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("CSV has no columns")
+	}
+	return headers, nil
+}
+
+// discoverFromRows classifies columns from sampled rows and builds the schema.
+func discoverFromRows(headers []string, sample discoverySample, dialect Dialect, opt DiscoverOptions) (*Config, error) {
+	rows := sample.rows
 	totalRows := len(rows)
 	if totalRows == 0 {
 		return nil, fmt.Errorf("CSV has no data rows")
@@ -88,7 +141,7 @@ func DiscoverFromCSV(data []byte, opts ...DiscoverOptions) (*Config, error) {
 	// 3. Analyze each column
 	columns := make([]columnAnalysis, len(headers))
 	for i, header := range headers {
-		columns[i] = analyzeColumn(header, i, rows, totalRows)
+		columns[i] = analyzeColumn(header, i, rows, totalRows, sample.filled[i])
 	}
 
 	// 4. Apply recovery overrides
@@ -99,8 +152,10 @@ func DiscoverFromCSV(data []byte, opts ...DiscoverOptions) (*Config, error) {
 
 	// 5. Build schema
 	config := &Config{
-		Name:    opt.Name,
-		Version: "1.0",
+		Name:          opt.Name,
+		Version:       "1.0",
+		MalformedRows: sample.malformed,
+		RaggedRows:    sample.ragged,
 	}
 
 	if config.Name == "" {
@@ -111,7 +166,18 @@ func DiscoverFromCSV(data []byte, opts ...DiscoverOptions) (*Config, error) {
 	var measures []MeasureMeta
 	var skipped []SkippedColumn
 
+	seen := make(map[string]bool)
 	for _, col := range columns {
+		// A later header with a key already taken would duplicate it
+		if col.key != "" && seen[col.key] {
+			skipped = append(skipped, SkippedColumn{
+				Column: col.header,
+				Reason: fmt.Sprintf("Duplicate header — key %q is already used by an earlier column", col.key),
+			})
+			continue
+		}
+		seen[col.key] = true
+
 		// Check recovery override
 		recovered := recoverSet[strings.ToLower(col.header)] || recoverSet[col.key]
 
@@ -131,6 +197,7 @@ func DiscoverFromCSV(data []byte, opts ...DiscoverOptions) (*Config, error) {
 					Column:      col.header,
 					Reason:      col.skipReason,
 					Recoverable: col.recoverable,
+					Confidence:  col.confidence(),
 				})
 			}
 		}
@@ -157,6 +224,7 @@ func DiscoverFromCSV(data []byte, opts ...DiscoverOptions) (*Config, error) {
 	config.SkippedColumns = skipped
 	config.Currency = currency
 	config.DiscoveredFrom = "CSV"
+	config.Dialect = &dialect
 	config.DiscoveredAt = time.Now().Format(time.RFC3339)
 
 	// 9. Set defaults
@@ -206,11 +274,19 @@ type columnAnalysis struct {
 	temporalFormat string
 	isCurrencyCode bool
 	hasDecimals    bool
+	decimalSeparator string
+	multiValueDelimiter string
 	cardinalityHint string
+
+	// Classification confidence inputs
+	typeShare    float64 // share of non-empty values that fit colType
+	ruleStrength float64 // how clear-cut the role rule that fired is
 }
 
 // analyzeColumn inspects all values in a column and classifies it.
-func analyzeColumn(header string, index int, rows [][]string, totalRows int) columnAnalysis {
+// analyzeColumn classifies one column from the sampled rows. filled reports
+// whether the column holds any value in the whole file, sampled or not.
+func analyzeColumn(header string, index int, rows [][]string, totalRows int, filled bool) columnAnalysis {
 	col := columnAnalysis{
 		header:     header,
 		key:        toSnakeCase(header),
@@ -228,7 +304,7 @@ func analyzeColumn(header string, index int, rows [][]string, totalRows int) col
 			continue
 		}
 		val := strings.TrimSpace(row[index])
-		if val == "" || val == "null" || val == "NULL" || val == "N/A" || val == "n/a" {
+		if IsNullValue(val) {
 			col.nullCount++
 			continue
 		}
@@ -240,8 +316,16 @@ func analyzeColumn(header string, index int, rows [][]string, totalRows int) col
 
 	if len(values) == 0 {
 		col.role = roleSkipped
+		if filled {
+			// Only possible if the sampler dropped every row with a value
+			col.skipReason = "Too sparse to classify — no sampled row has a value"
+			col.recoverable = true
+			col.typeShare, col.ruleStrength = 1, 0.5
+			return col
+		}
 		col.skipReason = "All values are empty/null"
 		col.recoverable = false
+		col.typeShare, col.ruleStrength = 1, 1
 		return col
 	}
 
@@ -250,12 +334,14 @@ func analyzeColumn(header string, index int, rows [][]string, totalRows int) col
 
 	// Step 1: Detect type
 	col.colType = detectType(values)
+	col.typeShare = typeShare(values, col.colType)
 
 	// Detect decimals in numeric columns (signals continuous data → measure)
 	if col.colType == typeNumeric {
+		col.decimalSeparator = DetectDecimalSeparator(values)
 		for _, v := range values {
 			clean := strings.TrimSuffix(strings.TrimSpace(v), "%")
-			if strings.Contains(clean, ".") {
+			if strings.Contains(clean, col.decimalSeparator) {
 				col.hasDecimals = true
 				break
 			}
@@ -264,6 +350,12 @@ func analyzeColumn(header string, index int, rows [][]string, totalRows int) col
 
 	// Step 2: Detect special patterns BEFORE role classification
 	if col.colType == typeString {
+		// Tag-style cells ("backend;api") — cardinality and samples count tags, not combinations
+		if delim, tags := detectMultiValue(values); delim != "" {
+			col.multiValueDelimiter = delim
+			col.uniqueCount = len(tags)
+			col.sampleVals = collectSamples(tags, 10)
+		}
 		col.isCurrencyCode = detectCurrencyCodes(col.sampleVals)
 		col.isTemporal, col.temporalFormat = detectTemporalPattern(col.sampleVals)
 	}
@@ -302,11 +394,13 @@ func (col *columnAnalysis) classifyRole(totalRows int) {
 		// Check if values contain decimals (continuous data → always a measure)
 		if col.hasDecimals {
 			col.role = roleMeasure
+			col.ruleStrength = 1
 			return
 		}
 		// Check if column name signals a measure (points, hours, cost, etc.)
 		if isMeasureNameHint(col.key) {
 			col.role = roleMeasure
+			col.ruleStrength = 0.9
 			return
 		}
 		// Ratio-based: if few unique values AND low ratio → coded dimension (e.g., priority 1-5)
@@ -314,18 +408,22 @@ func (col *columnAnalysis) classifyRole(totalRows int) {
 		uniqueRatio := float64(col.uniqueCount) / float64(totalRows)
 		if col.uniqueCount < 20 && uniqueRatio < 0.3 {
 			col.role = roleDimension
+			col.ruleStrength = 0.7 // codes and small counts look alike
 			return
 		}
 		// Many unique numeric values or high ratio → measure
 		col.role = roleMeasure
+		col.ruleStrength = 0.8
 
 	case typeDate:
 		// Dates are always temporal dimensions
 		col.role = roleDimension
 		col.isTemporal = true
+		col.ruleStrength = 1
 
 	case typeBool:
 		col.role = roleDimension
+		col.ruleStrength = 1
 
 	case typeString:
 		if col.uniqueCount == totalRows && totalRows > 10 {
@@ -334,6 +432,7 @@ func (col *columnAnalysis) classifyRole(totalRows int) {
 			// in ops, security, and product data even when every value is unique.
 			if isIdentifierName(col.key) {
 				col.role = roleDimension
+				col.ruleStrength = 0.8
 				return
 			}
 			// Otherwise likely free-text or a true surrogate key — skip but mark
@@ -341,6 +440,7 @@ func (col *columnAnalysis) classifyRole(totalRows int) {
 			col.role = roleSkipped
 			col.skipReason = "Unique per row — likely an identifier or free text"
 			col.recoverable = true
+			col.ruleStrength = 0.9
 			return
 		}
 		if col.uniqueCount > totalRows/2 && col.uniqueCount > 50 {
@@ -348,9 +448,11 @@ func (col *columnAnalysis) classifyRole(totalRows int) {
 			col.role = roleSkipped
 			col.skipReason = fmt.Sprintf("High cardinality (%d unique values) — not useful for grouping", col.uniqueCount)
 			col.recoverable = true
+			col.ruleStrength = 0.7
 			return
 		}
 		col.role = roleDimension
+		col.ruleStrength = 1
 	}
 }
 
@@ -395,16 +497,63 @@ func detectType(values []string) columnType {
 	return typeString
 }
 
+// typeShare returns the share of values that fit t. For strings it is the
+// share that fits no other type, so a column of mostly numbers that missed
+// the numeric threshold scores low.
+func typeShare(values []string, t columnType) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var num, date, boolean int
+	for _, v := range values {
+		if isNumeric(v) {
+			num++
+		}
+		if isDate(v) {
+			date++
+		}
+		if isBool(v) {
+			boolean++
+		}
+	}
+	n := float64(len(values))
+	switch t {
+	case typeNumeric:
+		return float64(num) / n
+	case typeDate:
+		return float64(date) / n
+	case typeBool:
+		return float64(boolean) / n
+	}
+	other := num
+	if date > other {
+		other = date
+	}
+	if boolean > other {
+		other = boolean
+	}
+	return 1 - float64(other)/n
+}
+
+// confidence scores the classification from 0 to 1: how uniformly the
+// sampled values fit the detected type, how clear-cut the role rule was,
+// and how many non-empty values it rests on.
+func (col *columnAnalysis) confidence() float64 {
+	c := col.typeShare * col.ruleStrength
+	if n := float64(col.totalCount - col.nullCount); n > 0 {
+		c *= n / (n + 5)
+	}
+	return math.Round(c*100) / 100
+}
+
+// isNumeric reports whether s reads as a number in either decimal
+// convention ("1,234.56", "1.234,56", "$5", "(500)", "11.7%").
 func isNumeric(s string) bool {
-	s = strings.TrimSpace(s)
-	s = strings.ReplaceAll(s, ",", "") // handle "1,234.56"
-	s = strings.TrimPrefix(s, "$")
-	s = strings.TrimPrefix(s, "€")
-	s = strings.TrimPrefix(s, "£")
-	s = strings.TrimPrefix(s, "-")
-	s = strings.TrimSuffix(s, "%") // handle "11.7%" from percentage columns
-	_, err := strconv.ParseFloat(s, 64)
-	return err == nil
+	if _, ok := ParseNumber(s, "."); ok {
+		return true
+	}
+	_, ok := ParseNumber(s, ",")
+	return ok
 }
 
 var dateFormats = []string{
@@ -579,6 +728,66 @@ func detectTemporalPattern(samples []string) (bool, string) {
 	return false, ""
 }
 
+// multiValueDelimiters are the tag separators recognized in one cell, in
+// order of preference. Commas need stronger evidence (see detectMultiValue).
+var multiValueDelimiters = []string{";", "|", ","}
+
+// detectMultiValue checks if values are delimiter-separated tag sets such as
+// "backend;api;urgent". Returns the delimiter and the set of distinct tags,
+// or "" when the column holds single values.
+//
+// A column qualifies when at least 20% of values contain the delimiter, the
+// pieces are short labels rather than prose, and the tags are reused across
+// rows (fewer distinct tags than distinct combinations).
+func detectMultiValue(values []string) (string, map[string]bool) {
+	if len(values) < 4 {
+		return "", nil
+	}
+	combos := make(map[string]bool)
+	for _, v := range values {
+		combos[v] = true
+	}
+
+	for _, delim := range multiValueDelimiters {
+		withDelim := 0
+		occurrences := 0
+		longTags := 0
+		tags := make(map[string]bool)
+		for _, v := range values {
+			if strings.Contains(v, delim) {
+				withDelim++
+			}
+			for _, t := range strings.Split(v, delim) {
+				t = strings.TrimSpace(t)
+				if t == "" {
+					continue
+				}
+				occurrences++
+				tags[t] = true
+				if len(t) > 40 || len(strings.Fields(t)) > 4 {
+					longTags++
+				}
+			}
+		}
+
+		if withDelim < 2 || float64(withDelim)/float64(len(values)) < 0.2 {
+			continue
+		}
+		if float64(longTags)/float64(occurrences) > 0.1 {
+			continue
+		}
+		if len(tags) >= len(combos) {
+			continue
+		}
+		// "Smith, John" also splits on commas — require each tag to recur
+		if delim == "," && occurrences < 2*len(tags) {
+			continue
+		}
+		return delim, tags
+	}
+	return "", nil
+}
+
 // ============================================================================
 // HIERARCHY DETECTION
 // ============================================================================
@@ -594,7 +803,9 @@ func detectHierarchies(dimensions []DimensionMeta, rows [][]string, headers []st
 	dimIsNumericOrigin := make(map[string]bool) // key → was originally numeric
 
 	for _, col := range columns {
-		if col.role == roleDimension {
+		// Tag sets have no single parent value — leave them out of hierarchies.
+		// Of duplicate headers, the first column is the dimension.
+		if _, dup := dimIndices[col.key]; col.role == roleDimension && col.multiValueDelimiter == "" && !dup {
 			dimIndices[col.key] = col.index
 			dimUniques[col.key] = col.uniqueCount
 			dimIsNumericOrigin[col.key] = col.colType == typeNumeric
@@ -718,6 +929,8 @@ func (col *columnAnalysis) toDimension() DimensionMeta {
 		TemporalOrder:   "chronological",
 		IsCurrencyCode:  col.isCurrencyCode,
 		CardinalityHint: col.cardinalityHint,
+		MultiValueDelimiter: col.multiValueDelimiter,
+		Confidence:      col.confidence(),
 	}
 }
 
@@ -728,6 +941,10 @@ func (col *columnAnalysis) toMeasure() MeasureMeta {
 		DisplayName:        toDisplayName(col.header),
 		Aggregations:       []string{"sum", "avg", "min", "max", "count"},
 		DefaultAggregation: "sum",
+		Confidence:         col.confidence(),
+	}
+	if col.decimalSeparator == "," {
+		m.DecimalSeparator = ","
 	}
 
 	// Auto-detect unit from column name
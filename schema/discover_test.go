@@ -1,8 +1,11 @@
 package schema
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -244,6 +247,52 @@ func TestTemporalDetection(t *testing.T) {
 	}
 }
 
+func TestMultiValueDetection(t *testing.T) {
+	tests := []struct {
+		values []string
+		delim  string
+	}{
+		{[]string{"backend;api", "backend;urgent", "frontend", "api;frontend", "backend"}, ";"},
+		{[]string{"ops|infra", "ops", "infra|security", "security|ops"}, "|"},
+		{[]string{"red, green", "green, blue", "red", "blue, red", "green"}, ","},
+		{[]string{"Smith, John", "Doe, Jane", "Lee, Ann", "Kim, Bo"}, ""},
+		{[]string{"Backend", "Frontend", "Mobile", "Backend"}, ""},
+		{[]string{"Fixed the login flow; see ticket for details", "Refactored the payment module; tests pending", "Updated docs", "Minor tweak"}, ""},
+	}
+
+	for _, tt := range tests {
+		got, _ := detectMultiValue(tt.values)
+		if got != tt.delim {
+			t.Errorf("detectMultiValue(%v) = %q, want %q", tt.values, got, tt.delim)
+		}
+	}
+}
+
+func TestDiscoverMultiValueColumn(t *testing.T) {
+	data := []byte(`Key,Labels,Points
+A-1,backend;api,3
+A-2,backend;urgent,5
+A-3,frontend,2
+A-4,api;frontend,1
+A-5,backend,8
+`)
+	config, err := DiscoverFromCSV(data)
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	for _, d := range config.Dimensions {
+		if d.Key != "labels" {
+			continue
+		}
+		if d.MultiValueDelimiter != ";" {
+			t.Errorf("labels delimiter = %q, want \";\"", d.MultiValueDelimiter)
+		}
+		assertContains(t, d.SampleValues, "urgent", "samples should list individual tags")
+		return
+	}
+	t.Error("labels should be a dimension")
+}
+
 // ============================================================================
 // HELPERS
 // ============================================================================
@@ -257,3 +306,218 @@ func assertContains(t *testing.T, slice []string, item string, msg string) {
 	}
 	t.Errorf("%s: %q not found in %v", msg, item, slice)
 }
+
+func TestDiscoverFromReaderMatchesCSV(t *testing.T) {
+	want, err := DiscoverFromCSV(financeCSV)
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	got, err := DiscoverFromReader(bytes.NewReader(financeCSV))
+	if err != nil {
+		t.Fatalf("DiscoverFromReader failed: %v", err)
+	}
+	got.DiscoveredAt = want.DiscoveredAt
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reader discovery differs from CSV discovery")
+	}
+}
+
+func TestDiscoverSamplesWholeStream(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Region,Amount,Channel\n")
+	for i := 0; i < 5000; i++ {
+		region, channel := "North", ""
+		if i >= 4000 {
+			region = "South" // only in the tail of the stream
+		}
+		if i == 4990 {
+			region = "East" // a single late row
+		}
+		if i >= 2000 {
+			channel = []string{"web", "store"}[i%2] // blank at the top
+		}
+		fmt.Fprintf(&b, "%s,%d,%s\n", region, i%97, channel)
+	}
+	opt := DiscoverOptions{SampleSize: 500}
+
+	fromCSV, err := DiscoverFromCSV([]byte(b.String()), opt)
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	fromReader, err := DiscoverFromReader(strings.NewReader(b.String()), opt)
+	if err != nil {
+		t.Fatalf("DiscoverFromReader failed: %v", err)
+	}
+	fromReader.DiscoveredAt = fromCSV.DiscoveredAt
+	if !reflect.DeepEqual(fromCSV, fromReader) {
+		t.Errorf("reader discovery differs from CSV discovery")
+	}
+
+	values := dimensionSamples(fromCSV, "region")
+	if !containsString(values, "South") || !containsString(values, "East") {
+		t.Errorf("region sample values = %v, want South and East included", values)
+	}
+	if values := dimensionSamples(fromCSV, "channel"); !containsString(values, "web") {
+		t.Errorf("channel = %v, want discovered from the rows where it fills in", values)
+	}
+
+	// Same input, same schema
+	again, _ := DiscoverFromCSV([]byte(b.String()), opt)
+	again.DiscoveredAt = fromCSV.DiscoveredAt
+	if !reflect.DeepEqual(fromCSV, again) {
+		t.Errorf("sampling is not deterministic")
+	}
+}
+
+func TestDiscoverSamplesTopOfFile(t *testing.T) {
+	// Values only in the first rows of a file far larger than SampleSize:
+	// the reservoir evicts those rows, the sampler must keep them anyway
+	var b strings.Builder
+	b.WriteString("Region,Status,Amount,Legacy Code\n")
+	for i := 0; i < 20000; i++ {
+		region, status, legacy := "North", "Open", ""
+		if i < 3 {
+			region = "Antarctica"
+		}
+		if i < 5 {
+			status = "Escalated"
+			legacy = fmt.Sprintf("LC-%d", i)
+		}
+		fmt.Fprintf(&b, "%s,%s,%d,%s\n", region, status, i%97, legacy)
+	}
+	opt := DiscoverOptions{SampleSize: 1000}
+
+	for name, discover := range map[string]func() (*Config, error){
+		"csv":    func() (*Config, error) { return DiscoverFromCSV([]byte(b.String()), opt) },
+		"reader": func() (*Config, error) { return DiscoverFromReader(strings.NewReader(b.String()), opt) },
+	} {
+		cfg, err := discover()
+		if err != nil {
+			t.Fatalf("%s: discovery failed: %v", name, err)
+		}
+		if values := dimensionSamples(cfg, "region"); !containsString(values, "Antarctica") {
+			t.Errorf("%s: region samples = %v, want Antarctica", name, values)
+		}
+		if values := dimensionSamples(cfg, "status"); !containsString(values, "Escalated") {
+			t.Errorf("%s: status samples = %v, want Escalated", name, values)
+		}
+		if values := dimensionSamples(cfg, "legacy_code"); !containsString(values, "LC-0") {
+			t.Errorf("%s: legacy_code samples = %v, want LC-0 (skipped: %+v)", name, values, cfg.SkippedColumns)
+		}
+	}
+}
+
+func TestAnalyzeColumnFilledOutsideSample(t *testing.T) {
+	rows := [][]string{{"a", ""}, {"b", ""}}
+	if col := analyzeColumn("Notes", 1, rows, len(rows), false); col.skipReason != "All values are empty/null" || col.recoverable {
+		t.Errorf("empty column = %q recoverable %v, want unrecoverable empty", col.skipReason, col.recoverable)
+	}
+	col := analyzeColumn("Notes", 1, rows, len(rows), true)
+	if col.role != roleSkipped || !col.recoverable || strings.Contains(col.skipReason, "empty") || col.confidence() >= 1 {
+		t.Errorf("column with values outside the sample = %q recoverable %v confidence %v",
+			col.skipReason, col.recoverable, col.confidence())
+	}
+}
+
+func TestDiscoverConfidence(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Team,Cost,Priority,Code\n")
+	for i := 0; i < 200; i++ {
+		code := fmt.Sprint(i)
+		if i%4 == 0 {
+			code = fmt.Sprintf("X%d", i) // a quarter do not parse as numbers
+		}
+		fmt.Fprintf(&b, "%s,%d.%02d,%d,%s\n", []string{"A", "B", "C"}[i%3], i, i%100, i%3+1, code)
+	}
+	cfg, err := DiscoverFromCSV([]byte(b.String()))
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+
+	conf := make(map[string]float64)
+	for _, d := range cfg.Dimensions {
+		conf[d.Key] = d.Confidence
+	}
+	for _, m := range cfg.Measures {
+		conf[m.Key] = m.Confidence
+	}
+	for _, s := range cfg.SkippedColumns {
+		conf[toSnakeCase(s.Column)] = s.Confidence
+	}
+	if conf["team"] < 0.95 || conf["cost"] < 0.95 {
+		t.Errorf("clear-cut columns: team = %v, cost = %v", conf["team"], conf["cost"])
+	}
+	if conf["priority"] >= conf["team"] {
+		t.Errorf("coded numeric priority = %v, want below team %v", conf["priority"], conf["team"])
+	}
+	if conf["code"] == 0 || conf["code"] > 0.5 {
+		t.Errorf("mixed code column = %v, want low", conf["code"])
+	}
+}
+
+func dimensionSamples(c *Config, key string) []string {
+	for _, d := range c.Dimensions {
+		if d.Key == key {
+			return d.SampleValues
+		}
+	}
+	return nil
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDiscoverCountsUnreadRows(t *testing.T) {
+	// Bad rows past the sniffed prefix, so the dialect keeps strict quoting
+	var b strings.Builder
+	b.WriteString("Region,Team,Amount\n")
+	for i := 0; b.Len() <= sniffBytes; i++ {
+		fmt.Fprintf(&b, "%s,Core,%d\n", []string{"North", "South"}[i%2], i%50)
+	}
+	b.WriteString("\"Ea\"st\",Core,5\n")  // broken quoting
+	b.WriteString("West,Data\n")          // ragged
+	b.WriteString("South,Web,20,extra\n") // ragged
+	b.WriteString("East,Core,30\n")
+	data := b.String()
+	for name, discover := range map[string]func() (*Config, error){
+		"csv":    func() (*Config, error) { return DiscoverFromCSV([]byte(data)) },
+		"reader": func() (*Config, error) { return DiscoverFromReader(strings.NewReader(data)) },
+	} {
+		cfg, err := discover()
+		if err != nil {
+			t.Fatalf("%s: discovery failed: %v", name, err)
+		}
+		if cfg.MalformedRows != 1 || cfg.RaggedRows != 2 {
+			t.Errorf("%s: malformed = %d, ragged = %d, want 1 and 2", name, cfg.MalformedRows, cfg.RaggedRows)
+		}
+	}
+}
+
+func TestDiscoverDuplicateHeaders(t *testing.T) {
+	data := []byte("Region,Amount,region\nNorth,10,x\nSouth,20,y\nWest,30,z\n")
+	cfg, err := DiscoverFromCSV(data)
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	count := 0
+	for _, d := range cfg.Dimensions {
+		if d.Key == "region" {
+			count++
+			if !containsString(d.SampleValues, "North") {
+				t.Errorf("region samples = %v, want the first column's values", d.SampleValues)
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("region dimensions = %d, want 1", count)
+	}
+	if len(cfg.SkippedColumns) != 1 || cfg.SkippedColumns[0].Column != "region" {
+		t.Errorf("skipped = %+v, want the later region column", cfg.SkippedColumns)
+	}
+}
@@ -0,0 +1,221 @@
+package schema
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// NUMERIC PARSING — locale-aware number cells
+// ============================================================================
+// Exports write numbers many ways. ParseNumber reads them all, given the
+// column's decimal separator:
+//
+//   "1,234.56"  "1.234,56"  "1 234,56"  "1'234.56"   grouped
+//   "$1,234"    "1.234 €"   "USD 100"   "100 EUR"    currency symbols, ISO codes
+//   "(500.00)"  "500-"      "-$5"                    accounting and trailing negatives
+//   "72.2%"     "1.2E+05"                            percent, scientific notation
+//
+// DiscoverFromCSV detects each measure column's decimal separator
+// (DetectDecimalSeparator) and records it in MeasureMeta.DecimalSeparator,
+// so helpers.ParseCSV reads "1.234" as 1234 in a German export and as
+// 1.234 in an English one.
+// ============================================================================
+
+// currencySymbolRunes are stripped wherever they appear in a number cell.
+const currencySymbolRunes = "$€£¥₹₩₽₺₫₱₪¢"
+
+var (
+	isoPrefix = regexp.MustCompile(`^[A-Z]{3}\s+`)
+	isoSuffix = regexp.MustCompile(`\s+[A-Z]{3}$`)
+	exponent  = regexp.MustCompile(`[eE][+-]?\d+$`)
+)
+
+// IsNullValue reports whether a trimmed cell is empty or a null marker
+// ("null", "N/A"). Discovery, profiles and helpers.ParseCSV all treat these
+// cells as missing values rather than text.
+func IsNullValue(val string) bool {
+	return val == "" || val == "null" || val == "NULL" || val == "N/A" || val == "n/a"
+}
+
+// ParseNumber reads a formatted number cell. decimal is the column's decimal
+// separator: "," for "1.234,56", anything else for "1,234.56". Percent
+// cells keep their number ("72.2%" → 72.2). Negatives are "-5", "5-" or
+// "(5)"; a cell with two signs, such as "(-5)", is not a number.
+func ParseNumber(s, decimal string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if decimal != "," {
+		decimal = "."
+		// Fast path: clean numbers and scientific notation
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+
+	// Accounting parentheses are the sign: "(-5)" is ambiguous, not 5
+	negative, parens := false, false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative, parens, s = true, true, strings.TrimSpace(s[1:len(s)-1])
+	}
+	if strings.HasSuffix(s, "-") {
+		if parens {
+			return 0, false
+		}
+		negative, s = true, strings.TrimSpace(strings.TrimSuffix(s, "-"))
+	}
+
+	s = isoPrefix.ReplaceAllString(s, "")
+	s = isoSuffix.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "R$", "")
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(currencySymbolRunes, r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+
+	s = strings.Replace(s, "\u2212", "-", 1) // minus sign
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		if parens || (negative && s[0] == '-') {
+			return 0, false
+		}
+		negative, s = negative || s[0] == '-', strings.TrimSpace(s[1:])
+	}
+
+	exp := exponent.FindString(s)
+	mantissa := strings.TrimSuffix(s, exp)
+	intPart, frac := mantissa, ""
+	if i := strings.LastIndex(mantissa, decimal); i >= 0 {
+		intPart, frac = mantissa[:i], mantissa[i+len(decimal):]
+	}
+	if !allDigits(frac) || (intPart == "" && frac == "") {
+		return 0, false
+	}
+	digits, ok := ungroup(intPart, decimal)
+	if !ok {
+		return 0, false
+	}
+
+	num := digits
+	if num == "" {
+		num = "0"
+	}
+	if frac != "" {
+		num += "." + frac
+	}
+	f, err := strconv.ParseFloat(num+exp, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		f = -f
+	}
+	return f, true
+}
+
+// ungroup removes thousands separators from an integer part. With a "."
+// decimal, commas are dropped wherever they are (the long-standing
+// behaviour); every other separator must split the digits into groups of
+// three, or two for Indian lakh grouping ("12,34,567").
+func ungroup(s, decimal string) (string, bool) {
+	if decimal == "." {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	if allDigits(s) {
+		return s, true
+	}
+	sep := ""
+	for _, candidate := range []string{".", ",", " ", "\u00a0", "\u202f", "'", "\u2019"} {
+		if candidate != decimal && strings.Contains(s, candidate) {
+			if sep != "" {
+				return "", false // mixed separators
+			}
+			sep = candidate
+		}
+	}
+	if sep == "" {
+		return "", false
+	}
+	parts := strings.Split(s, sep)
+	for i, p := range parts {
+		if !allDigits(p) || p == "" {
+			return "", false
+		}
+		switch {
+		case i == 0:
+			if len(p) > 3 {
+				return "", false
+			}
+		case i == len(parts)-1:
+			if len(p) != 3 {
+				return "", false
+			}
+		default:
+			if len(p) != 2 && len(p) != 3 {
+				return "", false
+			}
+		}
+	}
+	return strings.Join(parts, ""), true
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectDecimalSeparator decides from a column's values whether "," or "."
+// is the decimal separator. Values where the last separator has other than
+// three digits after it, or where both separators appear, decide; values
+// like "1,234" or "1.234" are ambiguous and do not. Returns "." unless ","
+// wins.
+func DetectDecimalSeparator(values []string) string {
+	comma, dot := 0, 0
+	for _, v := range values {
+		v = exponent.ReplaceAllString(strings.TrimSpace(v), "")
+		lastComma, lastDot := strings.LastIndex(v, ","), strings.LastIndex(v, ".")
+		switch {
+		case lastComma >= 0 && lastDot >= 0:
+			if lastComma > lastDot {
+				comma++
+			} else {
+				dot++
+			}
+		case lastComma >= 0:
+			if strings.Count(v, ",") > 1 {
+				dot++ // "1,234,567": commas group
+			} else if digitsAfter(v[lastComma+1:]) != 3 {
+				comma++
+			}
+		case lastDot >= 0:
+			if strings.Count(v, ".") > 1 {
+				comma++ // "1.234.567": dots group
+			} else if digitsAfter(v[lastDot+1:]) != 3 {
+				dot++
+			}
+		}
+	}
+	if comma > dot {
+		return ","
+	}
+	return "."
+}
+
+// digitsAfter counts the digits a separator is followed by: those at the
+// start of s.
+func digitsAfter(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"math"
+	"testing"
+)
+
+// ============================================================================
+// NUMERIC PARSING TESTS
+// ============================================================================
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in      string
+		decimal string
+		want    float64
+	}{
+		{"1234.56", ".", 1234.56},
+		{"1,234.56", ".", 1234.56},
+		{"1.234,56", ",", 1234.56},
+		{"1 234,56", ",", 1234.56},
+		{"1 234,56", ",", 1234.56},
+		{"1'234.56", ".", 1234.56},
+		{"12,34,567", ".", 1234567},
+		{"1.234", ",", 1234},
+		{"1.234", ".", 1.234},
+		{"$1,234", ".", 1234},
+		{"1.234,56 €", ",", 1234.56},
+		{"€ 99", ".", 99},
+		{"USD 100", ".", 100},
+		{"100 EUR", ".", 100},
+		{"R$ 1.234,56", ",", 1234.56},
+		{"(500.00)", ".", -500},
+		{"($1,200)", ".", -1200},
+		{"500-", ".", -500},
+		{"-$5", ".", -5},
+		{"+7", ".", 7},
+		{"72.2%", ".", 72.2},
+		{"12,5 %", ",", 12.5},
+		{"1.2E+05", ".", 120000},
+		{"1,5e3", ",", 1500},
+	}
+	for _, tt := range tests {
+		got, ok := ParseNumber(tt.in, tt.decimal)
+		if !ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ParseNumber(%q, %q) = %v, %v; want %v", tt.in, tt.decimal, got, ok, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "PROJ-101", "2026-01-15", "12.03.2026", "1.2.3", "12 34", "USD", "Sprint 17",
+		"(-5)", "(+5)", "(5-)", "($-5)", "(\u22125)", "-5-"} {
+		if got, ok := ParseNumber(in, "."); ok {
+			t.Errorf("ParseNumber(%q, \".\") = %v, want no number", in, got)
+		}
+		if got, ok := ParseNumber(in, ","); ok {
+			t.Errorf("ParseNumber(%q, \",\") = %v, want no number", in, got)
+		}
+	}
+}
+
+func TestDetectDecimalSeparator(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{[]string{"1,234.56", "99.5", "12"}, "."},
+		{[]string{"1.234,56", "99,5", "12"}, ","},
+		{[]string{"1 234,56", "3,2"}, ","},
+		{[]string{"1.234.567", "1.234"}, ","},
+		{[]string{"1,234,567", "1,234"}, "."},
+		{[]string{"1,234", "2,500"}, "."}, // ambiguous: keep "."
+		{[]string{"12", "7"}, "."},
+	}
+	for _, tt := range tests {
+		if got := DetectDecimalSeparator(tt.values); got != tt.want {
+			t.Errorf("DetectDecimalSeparator(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverEuropeanNumbers(t *testing.T) {
+	csv := []byte("Region,Betrag\n" +
+		"Nord,\"1.234,56\"\n" +
+		"Süd,\"2.000,10\"\n" +
+		"West,\"(15,00)\"\n" +
+		"Ost,\"987,65\"\n")
+
+	cfg, err := DiscoverFromCSV(csv, DiscoverOptions{})
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	var found bool
+	for _, m := range cfg.Measures {
+		if m.Key == "betrag" {
+			found = true
+			if m.DecimalSeparator != "," {
+				t.Errorf("betrag decimalSeparator = %q, want \",\"", m.DecimalSeparator)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("betrag not discovered as a measure: %+v", cfg.Measures)
+	}
+}
@@ -0,0 +1,405 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// DATA PROFILE — per-column statistics
+// ============================================================================
+// A profile summarizes every column of a file without keeping its rows:
+//
+//   all columns   null rate, distinct count
+//   measures      min, max, mean, standard deviation, quartiles and p95
+//   dimensions    the most frequent values
+//   temporal      earliest and latest date
+//
+// ProfileCSV and ProfileReader read the whole file in one pass; discovery
+// attaches a profile to Config.Profile when DiscoverOptions.Profile is set.
+// The translator reads date ranges from it, so "last quarter" can be
+// checked against the data without sending raw values to the AI.
+// ============================================================================
+
+// Profile is the statistical summary of a dataset.
+type Profile struct {
+	Rows    int             `json:"rows"`
+	Columns []ColumnProfile `json:"columns"`
+}
+
+// ColumnProfile summarizes one CSV column.
+type ColumnProfile struct {
+	Column         string        `json:"column"`                   // Header as it appears in the file
+	Key            string        `json:"key"`                      // Schema key
+	Role           string        `json:"role"`                     // "dimension", "measure" or "skipped"
+	NullRate       float64       `json:"nullRate"`                 // Share of rows with no value, 0–1
+	Distinct       int           `json:"distinct"`                 // Distinct non-empty values (tags for multi-value dimensions)
+	DistinctCapped bool          `json:"distinctCapped,omitempty"` // Distinct stopped counting at maxProfileDistinct; TopValues are approximate
+	Numeric        *NumericStats `json:"numeric,omitempty"`        // Measures only
+	TopValues      []ValueCount  `json:"topValues,omitempty"`      // Dimensions only, most frequent first
+	Dates          *DateRange    `json:"dates,omitempty"`          // Temporal dimensions only
+}
+
+// NumericStats describes a measure's values. Cells that do not parse as
+// numbers are counted as nulls.
+type NumericStats struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"` // Population standard deviation
+	P25    float64 `json:"p25"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	P95    float64 `json:"p95"`
+
+	// SampledQuantiles is set when the quantiles come from a sample of
+	// maxQuantileValues values rather than every value.
+	SampledQuantiles bool `json:"sampledQuantiles,omitempty"`
+}
+
+// ValueCount is one value and how many rows hold it.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// DateRange is the span of a temporal column, as ISO dates. Month, quarter
+// and year labels count from their first day ("Q2-2026" → 2026-04-01).
+type DateRange struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+const (
+	profileTopK        = 10
+	maxProfileDistinct = 100000
+	maxQuantileValues  = 10000
+)
+
+// ProfileCSV profiles CSV data against a schema, usually the one discovered
+// from the same data.
+func ProfileCSV(data []byte, cfg Config) (*Profile, error) {
+	return ProfileCSVContext(context.Background(), data, cfg)
+}
+
+// ProfileCSVContext is ProfileCSV with cancellation.
+func ProfileCSVContext(ctx context.Context, data []byte, cfg Config) (*Profile, error) {
+	if cfg.Dialect == nil {
+		d := SniffDialect(data)
+		cfg.Dialect = &d
+	}
+	return ProfileReader(ContextReader(ctx, bytes.NewReader(data)), cfg)
+}
+
+// ProfileReader is ProfileCSV for a stream. Memory grows with the number of
+// distinct values, not the file size.
+func ProfileReader(r io.Reader, cfg Config) (*Profile, error) {
+	var dialect Dialect
+	if cfg.Dialect != nil {
+		dialect = *cfg.Dialect
+	} else {
+		var err error
+		if dialect, r, err = SniffReader(r); err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+	}
+	reader := dialect.NewReader(r)
+	reader.ReuseRecord = true
+	headers, err := readDiscoveryHeaders(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	profilers := newColumnProfilers(headers, cfg)
+	rows := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			continue // skip malformed rows
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		rows++
+		for i, p := range profilers {
+			if p == nil {
+				continue
+			}
+			val := ""
+			if i < len(row) {
+				val = row[i]
+			}
+			p.add(val)
+		}
+	}
+
+	profile := &Profile{Rows: rows}
+	for _, p := range profilers {
+		if p != nil {
+			profile.Columns = append(profile.Columns, p.result(rows))
+		}
+	}
+	return profile, nil
+}
+
+// ColumnProfile returns the profile of the column with the given schema
+// key, or nil.
+func (p *Profile) ColumnProfile(key string) *ColumnProfile {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Columns {
+		if p.Columns[i].Key == key {
+			return &p.Columns[i]
+		}
+	}
+	return nil
+}
+
+// DateRange returns the span of a temporal dimension from the schema's
+// profile, following DerivedFrom for auto-bucketed dimensions. Returns nil
+// without a profile.
+func (c Config) DateRange(key string) *DateRange {
+	if col := c.Profile.ColumnProfile(key); col != nil && col.Dates != nil {
+		return col.Dates
+	}
+	for _, d := range c.Dimensions {
+		if d.Key == key && d.DerivedFrom != "" {
+			if col := c.Profile.ColumnProfile(toSnakeCase(d.DerivedFrom)); col != nil {
+				return col.Dates
+			}
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// COLUMN PROFILER
+// ============================================================================
+
+type columnProfiler struct {
+	profile   ColumnProfile
+	decimal   string
+	multi     string
+	temporal  bool
+	nulls     int
+	counts    map[string]int
+	numbers   int
+	mean, m2  float64 // Welford running mean and sum of squared deviations
+	quantiles []float64
+	rng       *rand.Rand
+	minDate   time.Time
+	maxDate   time.Time
+}
+
+// newColumnProfilers returns one profiler per header; nil for headers that
+// repeat an earlier key.
+func newColumnProfilers(headers []string, cfg Config) []*columnProfiler {
+	dims := make(map[string]DimensionMeta)
+	for _, d := range cfg.Dimensions {
+		dims[d.Key] = d
+	}
+	measures := make(map[string]MeasureMeta)
+	for _, m := range cfg.Measures {
+		if !m.IsSynthetic {
+			measures[m.Key] = m
+		}
+	}
+
+	seen := make(map[string]bool)
+	profilers := make([]*columnProfiler, len(headers))
+	for i, h := range headers {
+		key := toSnakeCase(strings.TrimSpace(h))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		p := &columnProfiler{
+			profile: ColumnProfile{Column: h, Key: key, Role: "skipped"},
+			counts:  make(map[string]int),
+		}
+		if d, ok := dims[key]; ok {
+			p.profile.Role = "dimension"
+			p.multi = d.MultiValueDelimiter
+			p.temporal = d.IsTemporal
+		} else if m, ok := measures[key]; ok {
+			p.profile.Role = "measure"
+			p.decimal = m.DecimalSeparator
+			p.rng = rand.New(rand.NewSource(sampleSeed))
+		}
+		profilers[i] = p
+	}
+	return profilers
+}
+
+func (p *columnProfiler) add(val string) {
+	val = strings.TrimSpace(val)
+	if IsNullValue(val) {
+		p.nulls++
+		return
+	}
+
+	switch p.profile.Role {
+	case "measure":
+		f, ok := ParseNumber(val, p.decimal)
+		if !ok {
+			p.nulls++
+			return
+		}
+		p.addNumber(f)
+	case "dimension":
+		if p.temporal {
+			p.addDate(val)
+		}
+	}
+
+	if p.multi != "" {
+		for _, tag := range strings.Split(val, p.multi) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				p.count(tag)
+			}
+		}
+		return
+	}
+	p.count(val)
+}
+
+func (p *columnProfiler) count(val string) {
+	if _, ok := p.counts[val]; ok || len(p.counts) < maxProfileDistinct {
+		p.counts[val]++
+		return
+	}
+	p.profile.DistinctCapped = true
+}
+
+func (p *columnProfiler) addNumber(f float64) {
+	p.numbers++
+	if p.numbers == 1 {
+		p.profile.Numeric = &NumericStats{Min: f, Max: f}
+	}
+	stats := p.profile.Numeric
+	stats.Min = math.Min(stats.Min, f)
+	stats.Max = math.Max(stats.Max, f)
+
+	delta := f - p.mean
+	p.mean += delta / float64(p.numbers)
+	p.m2 += delta * (f - p.mean)
+
+	// Reservoir of values for the quantiles
+	if len(p.quantiles) < maxQuantileValues {
+		p.quantiles = append(p.quantiles, f)
+	} else if j := p.rng.Intn(p.numbers); j < maxQuantileValues {
+		p.quantiles[j] = f
+		stats.SampledQuantiles = true
+	}
+}
+
+func (p *columnProfiler) addDate(val string) {
+	t, ok := parseTemporal(val)
+	if !ok {
+		return
+	}
+	if p.minDate.IsZero() || t.Before(p.minDate) {
+		p.minDate = t
+	}
+	if p.maxDate.IsZero() || t.After(p.maxDate) {
+		p.maxDate = t
+	}
+}
+
+func (p *columnProfiler) result(rows int) ColumnProfile {
+	out := p.profile
+	if rows > 0 {
+		out.NullRate = roundTo(float64(p.nulls)/float64(rows), 4)
+	}
+	out.Distinct = len(p.counts)
+
+	if stats := out.Numeric; stats != nil {
+		stats.Mean = p.mean
+		stats.StdDev = math.Sqrt(p.m2 / float64(p.numbers))
+		sort.Float64s(p.quantiles)
+		stats.P25 = quantile(p.quantiles, 0.25)
+		stats.Median = quantile(p.quantiles, 0.5)
+		stats.P75 = quantile(p.quantiles, 0.75)
+		stats.P95 = quantile(p.quantiles, 0.95)
+	}
+
+	if out.Role == "dimension" {
+		out.TopValues = topValues(p.counts, profileTopK)
+	}
+	if !p.minDate.IsZero() {
+		out.Dates = &DateRange{Min: p.minDate.Format("2006-01-02"), Max: p.maxDate.Format("2006-01-02")}
+	}
+	return out
+}
+
+// quantile interpolates linearly between the closest ranks of sorted.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// topValues returns the k most frequent values, ties broken alphabetically.
+func topValues(counts map[string]int, k int) []ValueCount {
+	values := make([]ValueCount, 0, len(counts))
+	for v, n := range counts {
+		values = append(values, ValueCount{Value: v, Count: n})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if len(values) > k {
+		values = values[:k]
+	}
+	return values
+}
+
+func roundTo(f float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(f*scale) / scale
+}
+
+// ============================================================================
+// TEMPORAL VALUES
+// ============================================================================
+
+var quarterLabel = regexp.MustCompile(`^Q([1-4])[-\s]+(\d{4})$`)
+
+// parseTemporal reads a date, month, quarter or year label as the first day
+// it covers.
+func parseTemporal(s string) (time.Time, bool) {
+	for _, layout := range append([]string{"2006-01"}, dateFormats...) {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if m := quarterLabel.FindStringSubmatch(s); m != nil {
+		q, _ := strconv.Atoi(m[1])
+		y, _ := strconv.Atoi(m[2])
+		return time.Date(y, time.Month(3*q-2), 1, 0, 0, 0, 0, time.UTC), true
+	}
+	return time.Time{}, false
+}
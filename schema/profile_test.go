@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// PROFILE TESTS
+// ============================================================================
+
+var profileCSV = []byte(`Month,Region,Amount,Tags
+2026-01,North,10,a|b
+2026-02,South,20,a
+2026-03,North,,b
+2026-04,East,40,N/A
+2026-05,North,n/a,a
+`)
+
+func profileConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := DiscoverFromCSV(profileCSV, DiscoverOptions{SampleSize: 1000, Profile: true})
+	if err != nil {
+		t.Fatalf("DiscoverFromCSV failed: %v", err)
+	}
+	if cfg.Profile == nil {
+		t.Fatal("expected a profile with DiscoverOptions.Profile")
+	}
+	return cfg
+}
+
+func TestProfileColumns(t *testing.T) {
+	cfg := profileConfig(t)
+	p := cfg.Profile
+	if p.Rows != 5 || len(p.Columns) != 4 {
+		t.Fatalf("rows = %d, columns = %d, want 5 and 4", p.Rows, len(p.Columns))
+	}
+
+	amount := p.ColumnProfile("amount")
+	if amount == nil || amount.Role != "measure" || amount.Numeric == nil {
+		t.Fatalf("amount = %+v, want a measure with numeric stats", amount)
+	}
+	if amount.NullRate != 0.4 {
+		t.Errorf("amount null rate = %v, want 0.4", amount.NullRate)
+	}
+	n := amount.Numeric
+	if n.Min != 10 || n.Max != 40 || math.Abs(n.Mean-70.0/3) > 1e-9 {
+		t.Errorf("amount min/max/mean = %v/%v/%v", n.Min, n.Max, n.Mean)
+	}
+	if n.Median != 20 || n.P25 != 15 || n.P75 != 30 {
+		t.Errorf("amount quartiles = %v/%v/%v, want 15/20/30", n.P25, n.Median, n.P75)
+	}
+	if n.SampledQuantiles {
+		t.Error("quantiles of 3 values should not be sampled")
+	}
+
+	region := p.ColumnProfile("region")
+	if region == nil || region.Distinct != 3 || region.Numeric != nil {
+		t.Fatalf("region = %+v, want 3 distinct values and no numeric stats", region)
+	}
+	if len(region.TopValues) == 0 || region.TopValues[0] != (ValueCount{Value: "North", Count: 3}) {
+		t.Errorf("region top values = %v, want North (3) first", region.TopValues)
+	}
+
+	tags := p.ColumnProfile("tags")
+	if tags == nil || tags.NullRate != 0.2 {
+		t.Fatalf("tags = %+v, want null rate 0.2", tags)
+	}
+
+	multi := Config{Dimensions: []DimensionMeta{{Key: "tags", MultiValueDelimiter: "|"}}}
+	mp, err := ProfileCSV(profileCSV, multi)
+	if err != nil {
+		t.Fatalf("ProfileCSV failed: %v", err)
+	}
+	if got := mp.ColumnProfile("tags"); got.Distinct != 2 || got.TopValues[0] != (ValueCount{Value: "a", Count: 3}) {
+		t.Errorf("multi-value tags = %+v, want 2 distinct tags with a (3) first", got)
+	}
+
+	month := p.ColumnProfile("month")
+	if month == nil || month.Dates == nil {
+		t.Fatalf("month = %+v, want a date range", month)
+	}
+	if *month.Dates != (DateRange{Min: "2026-01-01", Max: "2026-05-01"}) {
+		t.Errorf("month dates = %+v", *month.Dates)
+	}
+}
+
+func TestProfileReaderMatchesCSV(t *testing.T) {
+	cfg := profileConfig(t)
+	fromReader, err := ProfileReader(strings.NewReader(string(profileCSV)), *cfg)
+	if err != nil {
+		t.Fatalf("ProfileReader failed: %v", err)
+	}
+	if !reflect.DeepEqual(fromReader, cfg.Profile) {
+		t.Errorf("ProfileReader = %+v\nProfileCSV = %+v", fromReader, cfg.Profile)
+	}
+}
+
+func TestProfileQuantilesSampled(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Team,Cost\n")
+	for i := 1; i <= maxQuantileValues*2; i++ {
+		fmt.Fprintf(&b, "%s,%d\n", []string{"A", "B"}[i%2], i)
+	}
+	cfg := Config{Measures: []MeasureMeta{{Key: "cost"}}, Dimensions: []DimensionMeta{{Key: "team"}}}
+	p, err := ProfileCSV([]byte(b.String()), cfg)
+	if err != nil {
+		t.Fatalf("ProfileCSV failed: %v", err)
+	}
+	n := p.ColumnProfile("cost").Numeric
+	if !n.SampledQuantiles {
+		t.Error("expected sampled quantiles past maxQuantileValues")
+	}
+	if n.Min != 1 || n.Max != maxQuantileValues*2 || n.Mean != maxQuantileValues+0.5 {
+		t.Errorf("min/max/mean must be exact: %v/%v/%v", n.Min, n.Max, n.Mean)
+	}
+	if math.Abs(n.Median-maxQuantileValues) > maxQuantileValues*0.05 {
+		t.Errorf("sampled median = %v, want about %d", n.Median, maxQuantileValues)
+	}
+}
+
+func TestConfigDateRange(t *testing.T) {
+	cfg := Config{
+		Dimensions: []DimensionMeta{
+			{Key: "created", IsTemporal: true},
+			{Key: "created_quarter", IsTemporal: true, DerivedFrom: "Created"},
+			{Key: "region"},
+		},
+		Profile: &Profile{Columns: []ColumnProfile{
+			{Key: "created", Role: "dimension", Dates: &DateRange{Min: "2025-07-01", Max: "2026-03-31"}},
+			{Key: "region", Role: "dimension"},
+		}},
+	}
+	if r := cfg.DateRange("created_quarter"); r == nil || r.Min != "2025-07-01" {
+		t.Errorf("derived range = %+v, want the source column's", r)
+	}
+	if r := cfg.DateRange("region"); r != nil {
+		t.Errorf("non-temporal range = %+v, want nil", r)
+	}
+	if r := (Config{}).DateRange("created"); r != nil {
+		t.Errorf("range without profile = %+v, want nil", r)
+	}
+}
+
+func TestParseTemporal(t *testing.T) {
+	cases := map[string]string{
+		"2026-03-15": "2026-03-15",
+		"2026-03":    "2026-03-01",
+		"Q3-2025":    "2025-07-01",
+		"Q1 2026":    "2026-01-01",
+	}
+	for in, want := range cases {
+		got, ok := parseTemporal(in)
+		if !ok || got.Format("2006-01-02") != want {
+			t.Errorf("parseTemporal(%q) = %v, %v; want %s", in, got, ok, want)
+		}
+	}
+	if _, ok := parseTemporal("North"); ok {
+		t.Error("parseTemporal accepted a non-date")
+	}
+}
@@ -2,13 +2,15 @@ package schema
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/spektr-org/spektr/observe"
 )
 
 // ============================================================================
@@ -45,6 +47,10 @@ type RefineConfig struct {
 	APIKey   string // Gemini API key (consumer's key)
 	Model    string // Model name (default: "gemini-2.5-flash-lite")
 	Endpoint string // API endpoint (default: Gemini v1beta)
+
+	// Observer receives the spektr.refine and spektr.ai.complete spans,
+	// logs, AI latency and token usage. nil uses observe.Default().
+	Observer observe.Observer
 }
 
 // DefaultRefineConfig returns sensible defaults for Gemini.
@@ -60,6 +66,12 @@ func DefaultRefineConfig(apiKey string) RefineConfig {
 // The original Config is NOT mutated — a new enriched Config is returned.
 // If the AI call fails, returns the original Config unchanged with the error.
 func Refine(draft *Config, cfg RefineConfig) (*Config, error) {
+	return RefineContext(context.Background(), draft, cfg)
+}
+
+// RefineContext is Refine with cancellation: the AI call is abandoned once
+// ctx ends, and the draft is returned with an error wrapping ctx.Err().
+func RefineContext(ctx context.Context, draft *Config, cfg RefineConfig) (result *Config, err error) {
 	if draft == nil {
 		return nil, fmt.Errorf("draft schema is nil")
 	}
@@ -75,34 +87,43 @@ func Refine(draft *Config, cfg RefineConfig) (*Config, error) {
 		cfg.Endpoint = "https://generativelanguage.googleapis.com/v1beta/models"
 	}
 
+	o := observe.Or(cfg.Observer)
+	ctx, span := observe.Start(ctx, o, "spektr.refine", observe.String("model", cfg.Model))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
 	// 1. Build the lightweight metadata payload
 	payload := buildRefinePayload(draft)
 
 	// 2. Build the prompt
 	prompt := buildRefinePrompt(payload)
 
-	log.Printf("🧠 Spektr Smart Refine: sending %d columns, %d bytes metadata",
-		len(payload.Columns), len(prompt))
+	o.Log(ctx, observe.LevelInfo, "smart refine: sending metadata",
+		observe.Int("columns", len(payload.Columns)), observe.Int("bytes", len(prompt)))
 
 	// 3. Call Gemini
-	response, err := callRefineGemini(prompt, cfg)
+	response, err := callRefineGemini(ctx, o, prompt, cfg)
 	if err != nil {
-		log.Printf("⚠️ Smart Refine: AI call failed: %v — returning draft unchanged", err)
+		o.Log(ctx, observe.LevelWarn, "smart refine: AI call failed, returning draft unchanged", observe.String("error", err.Error()))
 		return draft, fmt.Errorf("smart refine AI call failed: %w", err)
 	}
 
 	// 4. Parse AI response
 	enrichment, err := parseRefineResponse(response)
 	if err != nil {
-		log.Printf("⚠️ Smart Refine: Parse failed: %v — returning draft unchanged", err)
+		o.Log(ctx, observe.LevelWarn, "smart refine: parse failed, returning draft unchanged", observe.String("error", err.Error()))
 		return draft, fmt.Errorf("smart refine parse failed: %w", err)
 	}
 
 	// 5. Apply enrichments to a copy of the draft
-	result := applyEnrichments(draft, enrichment)
+	result = applyEnrichments(draft, enrichment)
 
-	log.Printf("✅ Spektr Smart Refine: enriched %d dimensions, %d measures",
-		len(result.Dimensions), len(result.Measures))
+	o.Log(ctx, observe.LevelInfo, "smart refine: schema enriched",
+		observe.Int("dimensions", len(result.Dimensions)), observe.Int("measures", len(result.Measures)))
 
 	return result, nil
 }
@@ -401,13 +422,30 @@ type geminiRefineResponse struct {
 			} `json:"parts"`
 		} `json:"content"`
 	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
 	Error *struct {
 		Message string `json:"message"`
 		Code    int    `json:"code"`
 	} `json:"error"`
 }
 
-func callRefineGemini(prompt string, cfg RefineConfig) (string, error) {
+// callRefineGemini calls Gemini inside a spektr.ai.complete span, recording
+// latency and token usage on o.
+func callRefineGemini(ctx context.Context, o observe.Observer, prompt string, cfg RefineConfig) (text string, err error) {
+	ctx, span := observe.Start(ctx, o, "spektr.ai.complete", observe.Int("prompt.bytes", len(prompt)))
+	start := time.Now()
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+		o.Metric(ctx, "spektr.ai.latency_ms", float64(time.Since(start).Microseconds())/1000, observe.Bool("error", err != nil))
+	}()
+
 	url := fmt.Sprintf("%s/%s:generateContent?key=%s",
 		cfg.Endpoint, cfg.Model, cfg.APIKey)
 
@@ -422,8 +460,14 @@ func callRefineGemini(prompt string, cfg RefineConfig) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
 	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Post(url, "application/json", bytes.NewReader(jsonBody))
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -450,6 +494,13 @@ func callRefineGemini(prompt string, cfg RefineConfig) (string, error) {
 	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
 		return "", fmt.Errorf("Gemini returned empty response")
 	}
+	if u := geminiResp.UsageMetadata; u != nil {
+		span.SetAttributes(observe.Int("ai.tokens.prompt", u.PromptTokenCount),
+			observe.Int("ai.tokens.completion", u.CandidatesTokenCount),
+			observe.Int("ai.tokens.total", u.TotalTokenCount))
+		o.Metric(ctx, "spektr.ai.tokens", float64(u.PromptTokenCount), observe.String("provider", "gemini"), observe.String("kind", "prompt"))
+		o.Metric(ctx, "spektr.ai.tokens", float64(u.CandidatesTokenCount), observe.String("provider", "gemini"), observe.String("kind", "completion"))
+	}
 
 	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
 }
@@ -465,6 +516,8 @@ func deepCopyConfig(src *Config) *Config {
 		Description:    src.Description,
 		DiscoveredFrom: src.DiscoveredFrom,
 		DiscoveredAt:   src.DiscoveredAt,
+		MalformedRows:  src.MalformedRows,
+		RaggedRows:     src.RaggedRows,
 	}
 
 	// Deep copy dimensions
@@ -474,6 +527,9 @@ func deepCopyConfig(src *Config) *Config {
 		// Deep copy slices
 		dst.Dimensions[i].SampleValues = make([]string, len(d.SampleValues))
 		copy(dst.Dimensions[i].SampleValues, d.SampleValues)
+		if d.ValueOrder != nil {
+			dst.Dimensions[i].ValueOrder = append([]string(nil), d.ValueOrder...)
+		}
 	}
 
 	// Deep copy measures
@@ -498,6 +554,22 @@ func deepCopyConfig(src *Config) *Config {
 		dst.Currency = &currency
 	}
 
+	if src.Calendar != nil {
+		calendar := *src.Calendar
+		dst.Calendar = &calendar
+	}
+
+	if src.Dialect != nil {
+		dialect := *src.Dialect
+		dst.Dialect = &dialect
+	}
+
+	if src.Profile != nil {
+		profile := *src.Profile
+		profile.Columns = append([]ColumnProfile(nil), src.Profile.Columns...)
+		dst.Profile = &profile
+	}
+
 	return dst
 }
 
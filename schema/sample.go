@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// SAMPLING — which rows discovery looks at
+// ============================================================================
+// Discovery classifies columns from a sample, not the whole file. The first
+// N rows are a poor sample of a file sorted by date or category: a column
+// blank at the top looks empty, and a category that only appears lower down
+// never reaches SampleValues. rowSampler draws from the whole stream:
+//
+//   1. A uniform reservoir of SampleSize rows, seeded so the same input
+//      always yields the same schema.
+//   2. Plus every row that first shows a value in a column, wherever it
+//      appears, so rare categories and columns filled in only at the top or
+//      bottom are seen. Columns stop adding rows after maxTrackedValues
+//      distinct values, which bounds these extras.
+//
+// The sampler also notes which columns hold any value in the whole file, so
+// discovery never calls a column empty that is not. Files with no more rows
+// than SampleSize are sampled whole, in order.
+// ============================================================================
+
+const (
+	// maxTrackedValues is the distinct-value count past which a column is
+	// high-cardinality and its new values stop adding rows to the sample.
+	maxTrackedValues = 50
+	sampleSeed       = 1
+)
+
+type sampledRow struct {
+	index int
+	row   []string
+}
+
+type rowSampler struct {
+	limit int
+	rng   *rand.Rand
+	seen  int
+
+	reservoir []sampledRow
+	extra     []sampledRow
+	distinct  []map[string]bool // per column; nil once past maxTrackedValues
+	filled    []bool            // per column; a non-null value was seen
+}
+
+func newRowSampler(limit, columns int) *rowSampler {
+	s := &rowSampler{
+		limit:    limit,
+		rng:      rand.New(rand.NewSource(sampleSeed)),
+		distinct: make([]map[string]bool, columns),
+		filled:   make([]bool, columns),
+	}
+	for i := range s.distinct {
+		s.distinct[i] = make(map[string]bool)
+	}
+	return s
+}
+
+// add offers the next row of the stream to the sample.
+func (s *rowSampler) add(row []string) {
+	r := sampledRow{index: s.seen, row: row}
+	s.seen++
+	if len(s.reservoir) < s.limit {
+		s.reservoir = append(s.reservoir, r)
+	} else if j := s.rng.Intn(s.seen); j < s.limit {
+		s.reservoir[j] = r
+	}
+
+	novel := false
+	for i, set := range s.distinct {
+		if i >= len(row) {
+			break
+		}
+		v := strings.TrimSpace(row[i])
+		if IsNullValue(v) {
+			continue
+		}
+		s.filled[i] = true
+		if set == nil || set[v] {
+			continue
+		}
+		set[v] = true
+		novel = true
+		if len(set) > maxTrackedValues {
+			s.distinct[i] = nil
+		}
+	}
+	// Kept even while the row is in the reservoir, which may evict it later
+	if novel {
+		s.extra = append(s.extra, r)
+	}
+}
+
+// rows returns the sample in file order.
+func (s *rowSampler) rows() [][]string {
+	picked := make(map[int]bool, len(s.reservoir)+len(s.extra))
+	all := make([]sampledRow, 0, len(s.reservoir)+len(s.extra))
+	for _, set := range [][]sampledRow{s.reservoir, s.extra} {
+		for _, r := range set {
+			if !picked[r.index] {
+				picked[r.index] = true
+				all = append(all, r)
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].index < all[j].index })
+
+	rows := make([][]string, len(all))
+	for i, r := range all {
+		rows[i] = r.row
+	}
+	return rows
+}
+
+// discoverySample is what discovery learns from one pass over the rows.
+type discoverySample struct {
+	rows      [][]string
+	filled    []bool // per column: a non-null value appears somewhere in the file
+	malformed int    // rows with broken quoting
+	ragged    int    // rows with a field count unlike the header's
+}
+
+// sampleRows reads every data row from reader and returns the sample.
+// Rows that cannot be read are skipped and counted; an error from the
+// underlying reader (a cancelled ContextReader, say) stops sampling.
+func sampleRows(reader *CSVReader, columns int, opt DiscoverOptions) (discoverySample, error) {
+	limit := opt.SampleSize
+	if limit <= 0 {
+		limit = 100000 // safety cap
+	}
+	s := newRowSampler(limit, columns)
+	var out discoverySample
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, csv.ErrFieldCount) {
+			out.ragged++
+			continue
+		}
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			out.malformed++
+			continue
+		}
+		if err != nil {
+			return out, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		s.add(row)
+	}
+	out.rows, out.filled = s.rows(), s.filled
+	return out, nil
+}
@@ -1,5 +1,7 @@
 package schema
 
+import "strings"
+
 // ============================================================================
 // SCHEMA — Describes the shape of a dataset for the engine + AI translator
 // ============================================================================
@@ -24,6 +26,15 @@ type Config struct {
 	// Optional: currency conversion settings
 	Currency *CurrencyConfig   `json:"currency,omitempty"`
 
+	// Optional: fiscal calendar, week start, and time zone for temporal logic
+	Calendar *CalendarConfig   `json:"calendar,omitempty"`
+
+	// Optional: CSV layout (delimiter, encoding, header/footer rows) found at discovery
+	Dialect *Dialect `json:"dialect,omitempty"`
+
+	// Optional: per-column statistics (see ProfileCSV)
+	Profile *Profile `json:"profile,omitempty"`
+
 	// Auto-discovery metadata
 	DiscoveredFrom string       `json:"discoveredFrom,omitempty"`
 	DiscoveredAt   string       `json:"discoveredAt,omitempty"`
@@ -31,6 +42,11 @@ type Config struct {
 	// Columns skipped during auto-discovery
 	SkippedColumns []SkippedColumn `json:"skippedColumns,omitempty"`
 
+	// Rows auto-discovery could not read and left out of its sample:
+	// broken quoting (malformed) or a field count unlike the header's (ragged)
+	MalformedRows int `json:"malformedRows,omitempty"`
+	RaggedRows    int `json:"raggedRows,omitempty"`
+
 	// Smart Refine metadata (set after AI enrichment)
 	RefinedAt string `json:"refinedAt,omitempty"`
 	RefinedBy string `json:"refinedBy,omitempty"` // "gemini", "openai", "manual"
@@ -52,6 +68,31 @@ type DimensionMeta struct {
 	CardinalityHint string  `json:"cardinalityHint,omitempty"` // "low", "medium", "high"
 	DerivedFrom    string   `json:"derivedFrom,omitempty"`     // Original column if auto-bucketed
 	SortHint       string   `json:"sortHint,omitempty"`        // Ordinal ordering (e.g., "P1 > P2 > P3 > P4") — set by Smart Refine
+	MultiValueDelimiter string `json:"multiValueDelimiter,omitempty"` // Set for tag-style cells ("backend;api") — values split on this
+	ValueOrder     []string `json:"valueOrder,omitempty"`      // Explicit ordinal order; overrides SortHint
+	Confidence     float64  `json:"confidence,omitempty"`      // Auto-discovery's confidence in this classification, 0–1
+}
+
+// Order returns the dimension's ordinal value order: ValueOrder when set,
+// otherwise SortHint split on ">" ("P1 > P2 > P3" → [P1 P2 P3]).
+// Returns nil for unordered dimensions.
+func (d DimensionMeta) Order() []string {
+	if len(d.ValueOrder) > 0 {
+		return d.ValueOrder
+	}
+	if d.SortHint == "" {
+		return nil
+	}
+	var order []string
+	for _, v := range strings.Split(d.SortHint, ">") {
+		if v = strings.TrimSpace(v); v != "" {
+			order = append(order, v)
+		}
+	}
+	if len(order) < 2 {
+		return nil
+	}
+	return order
 }
 
 // MeasureMeta describes a numeric field used for aggregation.
@@ -64,7 +105,9 @@ type MeasureMeta struct {
 	IsSynthetic        bool     `json:"isSynthetic,omitempty"` // Auto-generated (e.g., record_count)
 	Aggregations       []string `json:"aggregations,omitempty"`
 	DefaultAggregation string   `json:"defaultAggregation,omitempty"`
-	Format             string   `json:"format,omitempty"` // "#,##0.00", "0.0%"
+	Format             string   `json:"format,omitempty"`           // "#,##0.00", "0.0%"
+	DecimalSeparator   string   `json:"decimalSeparator,omitempty"` // "," when cells read "1.234,56"; empty = "."
+	Confidence         float64  `json:"confidence,omitempty"`       // Auto-discovery's confidence in this classification, 0–1
 }
 
 // CurrencyConfig enables multi-currency normalization.
@@ -75,11 +118,21 @@ type CurrencyConfig struct {
 	Rates         map[string]float64 `json:"rates"` // Foreign → base rate
 }
 
+// CalendarConfig controls how temporal values are bucketed and how relative
+// time ranges ("last quarter", "YTD") are resolved.
+type CalendarConfig struct {
+	Dimension       string `json:"dimension,omitempty"`       // Temporal dimension to bucket (default "month")
+	FiscalYearStart int    `json:"fiscalYearStart,omitempty"` // Month the fiscal year begins, 1–12 (e.g., 4 = April)
+	WeekStart       string `json:"weekStart,omitempty"`       // "monday" (ISO, default), "sunday", "saturday"
+	TimeZone        string `json:"timeZone,omitempty"`        // IANA zone, e.g. "Asia/Singapore"
+}
+
 // SkippedColumn records why a column was excluded during auto-discovery.
 type SkippedColumn struct {
-	Column      string `json:"column"`
-	Reason      string `json:"reason"`
-	Recoverable bool   `json:"recoverable"` // Can be restored if consumer overrides
+	Column      string  `json:"column"`
+	Reason      string  `json:"reason"`
+	Recoverable bool    `json:"recoverable"`          // Can be restored if consumer overrides
+	Confidence  float64 `json:"confidence,omitempty"` // Auto-discovery's confidence that the column should be skipped, 0–1
 }
 
 // DefaultDimension creates a DimensionMeta with sensible defaults.
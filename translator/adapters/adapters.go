@@ -1,6 +1,11 @@
 package adapters
 
-import "github.com/spektr-org/spektr/translator"
+import (
+	"context"
+
+	"github.com/spektr-org/spektr/observe"
+	"github.com/spektr-org/spektr/translator"
+)
 
 // New returns the appropriate AIProvider adapter based on the endpoint.
 // Consumers who want explicit control can instantiate NewGeminiAdapter
@@ -52,4 +57,21 @@ func containsStr(s, sub string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// reportUsage annotates the spektr.ai.complete span in ctx with the
+// provider's token counts and emits them as spektr.ai.tokens metrics.
+func reportUsage(ctx context.Context, provider string, prompt, completion, total int) {
+	if total == 0 && prompt == 0 && completion == 0 {
+		return
+	}
+	o, span := observe.FromContext(ctx)
+	span.SetAttributes(
+		observe.Int("ai.tokens.prompt", prompt),
+		observe.Int("ai.tokens.completion", completion),
+		observe.Int("ai.tokens.total", total),
+	)
+	p := observe.String("provider", provider)
+	o.Metric(ctx, "spektr.ai.tokens", float64(prompt), p, observe.String("kind", "prompt"))
+	o.Metric(ctx, "spektr.ai.tokens", float64(completion), p, observe.String("kind", "completion"))
+}
@@ -8,6 +8,9 @@
 //	    Complete(prompt string) (string, error)
 //	}
 //
+// Adapters that can abort an in-flight request should also implement
+// translator.ContextAIProvider (CompleteContext), as the built-in ones do.
+//
 // Your adapter receives the fully-formed prompt string from Spektr and
 // must return the raw text response from your AI provider. All HTTP
 // transport, authentication, and request/response shaping is yours to own.
@@ -15,6 +18,7 @@ package adapters
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -62,6 +66,12 @@ func (a *GeminiAdapter) WithEndpoint(endpoint string) *GeminiAdapter {
 // Complete sends the prompt to Gemini and returns the text response.
 // Implements translator.AIProvider.
 func (a *GeminiAdapter) Complete(prompt string) (string, error) {
+	return a.CompleteContext(context.Background(), prompt)
+}
+
+// CompleteContext is Complete, abandoning the request when ctx ends.
+// Implements translator.ContextAIProvider.
+func (a *GeminiAdapter) CompleteContext(ctx context.Context, prompt string) (string, error) {
 	url := fmt.Sprintf("%s/%s:generateContent?key=%s", a.endpoint, a.model, a.apiKey)
 
 	body, err := json.Marshal(geminiRequest{
@@ -73,7 +83,13 @@ func (a *GeminiAdapter) Complete(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := a.client.Post(url, "application/json", bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -98,6 +114,9 @@ func (a *GeminiAdapter) Complete(prompt string) (string, error) {
 	if len(parsed.Candidates) == 0 || len(parsed.Candidates[0].Content.Parts) == 0 {
 		return "", fmt.Errorf("Gemini returned empty response")
 	}
+	if u := parsed.UsageMetadata; u != nil {
+		reportUsage(ctx, "gemini", u.PromptTokenCount, u.CandidatesTokenCount, u.TotalTokenCount)
+	}
 
 	return parsed.Candidates[0].Content.Parts[0].Text, nil
 }
@@ -121,6 +140,11 @@ type geminiResponse struct {
 			} `json:"parts"`
 		} `json:"content"`
 	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
 	Error *struct {
 		Message string `json:"message"`
 		Code    int    `json:"code"`
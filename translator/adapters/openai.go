@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -67,6 +68,12 @@ func (a *OpenAIAdapter) WithEndpoint(endpoint string) *OpenAIAdapter {
 // and returns the text response.
 // Implements translator.AIProvider.
 func (a *OpenAIAdapter) Complete(prompt string) (string, error) {
+	return a.CompleteContext(context.Background(), prompt)
+}
+
+// CompleteContext is Complete, abandoning the request when ctx ends.
+// Implements translator.ContextAIProvider.
+func (a *OpenAIAdapter) CompleteContext(ctx context.Context, prompt string) (string, error) {
 	url := fmt.Sprintf("%s/chat/completions", a.endpoint)
 
 	body, err := json.Marshal(openAIRequest{
@@ -79,7 +86,7 @@ func (a *OpenAIAdapter) Complete(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -112,6 +119,9 @@ func (a *OpenAIAdapter) Complete(prompt string) (string, error) {
 	if len(parsed.Choices) == 0 {
 		return "", fmt.Errorf("OpenAI returned empty response")
 	}
+	if u := parsed.Usage; u != nil {
+		reportUsage(ctx, "openai", u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+	}
 
 	return parsed.Choices[0].Message.Content, nil
 }
@@ -134,6 +144,11 @@ type openAIResponse struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Code    string `json:"code"`
@@ -1,11 +1,13 @@
 package translator
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"strings"
+	"time"
 
 	"github.com/spektr-org/spektr/engine"
+	"github.com/spektr-org/spektr/observe"
 	"github.com/spektr-org/spektr/schema"
 )
 
@@ -25,6 +27,19 @@ import (
 // supplied by the consumer.
 type AITranslator struct {
 	provider AIProvider
+	observer observe.Observer
+}
+
+// Option configures an AITranslator.
+type Option func(*AITranslator)
+
+// WithObserver sends the translator's logs, spans (spektr.translate,
+// spektr.ai.complete) and AI latency metrics to o instead of
+// observe.Default().
+func WithObserver(o observe.Observer) Option {
+	return func(t *AITranslator) {
+		t.observer = o
+	}
 }
 
 // NewTranslator creates an AITranslator backed by the given AIProvider.
@@ -39,20 +54,47 @@ type AITranslator struct {
 // Example using a custom adapter (e.g. TPL's relay, OpenAI, local LLM):
 //
 //	t := translator.NewTranslator(myCustomAdapter)
-func NewTranslator(provider AIProvider) *AITranslator {
-	return &AITranslator{provider: provider}
+func NewTranslator(provider AIProvider, opts ...Option) *AITranslator {
+	t := &AITranslator{provider: provider}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
 // Translate converts a natural language query into a QuerySpec.
 // Implements the Translator interface.
 func (t *AITranslator) Translate(query string, sch schema.Config) (*TranslateResult, error) {
-	return t.TranslateWithSummary(query, sch, nil)
+	return t.TranslateWithSummaryContext(context.Background(), query, sch, nil)
 }
 
 // TranslateWithSummary converts a query using a pre-built DataSummary.
 // Prefer this over Translate when records are already parsed — the summary
 // gives the AI better context for filtering and value matching.
 func (t *AITranslator) TranslateWithSummary(query string, sch schema.Config, summary *DataSummary) (*TranslateResult, error) {
+	return t.TranslateWithSummaryContext(context.Background(), query, sch, summary)
+}
+
+// TranslateContext is Translate with cancellation.
+func (t *AITranslator) TranslateContext(ctx context.Context, query string, sch schema.Config) (*TranslateResult, error) {
+	return t.TranslateWithSummaryContext(ctx, query, sch, nil)
+}
+
+// TranslateWithSummaryContext is TranslateWithSummary with cancellation: the
+// AI call is abandoned once ctx ends, and the error wraps ctx.Err().
+func (t *AITranslator) TranslateWithSummaryContext(ctx context.Context, query string, sch schema.Config, summary *DataSummary) (result *TranslateResult, err error) {
+	o := observe.Or(t.observer)
+	ctx, span := observe.Start(ctx, o, "spektr.translate", observe.String("schema", sch.Name))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		} else {
+			span.SetAttributes(observe.String("intent", result.QuerySpec.Intent),
+				observe.Float64("confidence", result.QuerySpec.Confidence))
+		}
+		span.End()
+	}()
+
 	// 1. Build schema-driven prompt
 	prompt := BuildPrompt(sch, summary)
 
@@ -67,18 +109,18 @@ func (t *AITranslator) TranslateWithSummary(query string, sch schema.Config, sum
 
 	prompt += "\n\nUSER QUERY: " + query + "\n\nRespond with valid JSON only:"
 
-	log.Printf("🔄 Spektr Translator: query=\"%s\" schema=\"%s\"", truncate(query, 80), sch.Name)
+	o.Log(ctx, observe.LevelInfo, "translating query", observe.String("query", truncate(query, 80)), observe.String("schema", sch.Name))
 
 	// 3. Delegate to the consumer's AI provider — Spektr never touches HTTP here
-	response, err := t.provider.Complete(prompt)
+	response, err := completeObserved(ctx, o, t.provider, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("AI provider error: %w", err)
 	}
 
 	// 4. Parse response into QuerySpec
-	result, err := parseResponse(response)
-	if err != nil {
-		log.Printf("⚠️ Spektr Translator: parse failed, using fallback: %v", err)
+	result, perr := parseResponse(response)
+	if perr != nil {
+		o.Log(ctx, observe.LevelWarn, "translation parse failed, using fallback", observe.String("error", perr.Error()))
 		return &TranslateResult{
 			QuerySpec: engine.QuerySpec{
 				Intent:      "table",
@@ -91,12 +133,57 @@ func (t *AITranslator) TranslateWithSummary(query string, sch schema.Config, sum
 		}, nil
 	}
 
-	log.Printf("✅ Spektr Translator: intent=%s, visualize=%s, confidence=%.2f",
-		result.QuerySpec.Intent, result.QuerySpec.Visualize, result.QuerySpec.Confidence)
+	o.Log(ctx, observe.LevelInfo, "query translated", observe.String("intent", result.QuerySpec.Intent),
+		observe.String("visualize", result.QuerySpec.Visualize), observe.Float64("confidence", result.QuerySpec.Confidence))
 
 	return result, nil
 }
 
+// completeObserved wraps complete in a spektr.ai.complete span, whose
+// context adapters use to report token usage, and records its latency.
+func completeObserved(ctx context.Context, o observe.Observer, provider AIProvider, prompt string) (string, error) {
+	ctx, span := observe.Start(ctx, o, "spektr.ai.complete", observe.Int("prompt.bytes", len(prompt)))
+	start := time.Now()
+	response, err := complete(ctx, provider, prompt)
+	latency := float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+	o.Metric(ctx, "spektr.ai.latency_ms", latency, observe.Bool("error", err != nil))
+	return response, err
+}
+
+// complete calls the provider, honouring ctx even when the provider does
+// not implement ContextAIProvider.
+func complete(ctx context.Context, provider AIProvider, prompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if cp, ok := provider.(ContextAIProvider); ok {
+		return cp.CompleteContext(ctx, prompt)
+	}
+	if ctx.Done() == nil {
+		return provider.Complete(prompt)
+	}
+
+	type reply struct {
+		text string
+		err  error
+	}
+	done := make(chan reply, 1) // buffered: an abandoned call must not block
+	go func() {
+		text, err := provider.Complete(prompt)
+		done <- reply{text, err}
+	}()
+	select {
+	case r := <-done:
+		return r.text, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
 // BuildDataSummaryFromRecords creates a lightweight DataSummary from records.
 // Only dimension sample values and record count are included.
 // Measure values and raw data are never sent to the AI.
@@ -106,14 +193,26 @@ func BuildDataSummaryFromRecords(records []engine.Record, sch schema.Config) *Da
 	}
 
 	seen := make(map[string]map[string]bool)
+	delimiters := make(map[string]string)
 	for _, d := range sch.Dimensions {
 		seen[d.Key] = make(map[string]bool)
+		if d.MultiValueDelimiter != "" {
+			delimiters[d.Key] = d.MultiValueDelimiter
+		}
 	}
 	for _, r := range records {
 		for key, val := range r.Dimensions {
-			if _, ok := seen[key]; ok && val != "" {
-				seen[key][val] = true
+			if _, ok := seen[key]; !ok || val == "" {
+				continue
 			}
+			// Multi-value cells list their individual tags, not the combination
+			if delim, ok := delimiters[key]; ok {
+				for _, tag := range engine.SplitMultiValue(val, delim) {
+					seen[key][tag] = true
+				}
+				continue
+			}
+			seen[key][val] = true
 		}
 	}
 
@@ -131,6 +230,48 @@ func BuildDataSummaryFromRecords(records []engine.Record, sch schema.Config) *Da
 	return summary
 }
 
+// BuildDataSummaryFromView is BuildDataSummaryFromRecords for a RecordView.
+// Dictionary-encoded views (columnar, snapshots) are summarized from their
+// dictionaries without scanning rows.
+func BuildDataSummaryFromView(view engine.RecordView, sch schema.Config) *DataSummary {
+	summary := &DataSummary{RecordCount: view.Len(), Dimensions: make(map[string][]string)}
+	if view.Len() == 0 {
+		return summary
+	}
+
+	cv, coded := view.(engine.CodedView)
+	for _, d := range sch.Dimensions {
+		var values []string
+		var ok bool
+		if coded {
+			values, ok = cv.Dictionary(d.Key)
+		}
+		if !ok {
+			for i := 0; i < view.Len(); i++ {
+				values = append(values, view.Dimension(i, d.Key))
+			}
+		}
+
+		seen := make(map[string]bool)
+		vals := []string{}
+		for _, val := range values {
+			// Multi-value cells list their individual tags, not the combination
+			tags := []string{val}
+			if d.MultiValueDelimiter != "" {
+				tags = engine.SplitMultiValue(val, d.MultiValueDelimiter)
+			}
+			for _, tag := range tags {
+				if tag != "" && !seen[tag] {
+					seen[tag] = true
+					vals = append(vals, tag)
+				}
+			}
+		}
+		summary.Dimensions[d.Key] = vals
+	}
+	return summary
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -25,6 +25,7 @@ import (
 //   - Hierarchies → parent/child relationships explained
 //   - Temporal → identified for date-based queries
 //   - Currency → if enabled, conversion rules included
+//   - Calendar → fiscal year / week start, if configured
 //
 // Total data sent to AI: ~500-2000 bytes of metadata per query. Never raw data.
 // ============================================================================
@@ -78,6 +79,22 @@ Cross-group queries will be normalized to %s by the engine.
 `, sch.Currency.BaseCurrency, sch.Currency.CodeDimension, sch.Currency.BaseCurrency))
 	}
 
+	// ── Calendar Rules ────────────────────────────────────────────────────
+	if cal := sch.Calendar; cal != nil && (cal.FiscalYearStart > 1 || cal.WeekStart != "") {
+		b.WriteString("CALENDAR:\n")
+		if cal.FiscalYearStart > 1 {
+			b.WriteString(fmt.Sprintf(`Fiscal year starts in %s. "year", "quarter", "this_quarter", "last_year", "ytd" etc. are FISCAL.
+Fiscal years are named by the year they end in (e.g., "FY26"); fiscal quarters as "FY26 Q1".
+`, time.Month(cal.FiscalYearStart)))
+		}
+		if cal.WeekStart != "" {
+			b.WriteString(fmt.Sprintf("Weeks start on %s.\n", cal.WeekStart))
+		}
+		b.WriteString(`Group by "year", "quarter", or "week" to bucket by calendar period — the engine derives them.
+
+`)
+	}
+
 	// ── Response Format ───────────────────────────────────────────────────
 	b.WriteString(buildResponseFormat(sch))
 
@@ -121,10 +138,19 @@ func buildDimensionDescription(sch schema.Config) string {
 		}
 		if d.IsTemporal {
 			b.WriteString(" [TEMPORAL — use for time-based queries]")
+			if r := sch.DateRange(d.Key); r != nil {
+				b.WriteString(fmt.Sprintf(" [DATA FROM %s TO %s]", r.Min, r.Max))
+			}
 		}
 		if d.IsCurrencyCode {
 			b.WriteString(" [CURRENCY CODE]")
 		}
+		if order := d.Order(); order != nil {
+			b.WriteString(fmt.Sprintf(" [ORDER: %s]", strings.Join(order, " > ")))
+		}
+		if d.MultiValueDelimiter != "" {
+			b.WriteString(" [MULTI-VALUE — a record can have several; filter by single values, groups overlap]")
+		}
 		b.WriteString("\n")
 	}
 	return b.String()
@@ -212,11 +238,13 @@ func buildResponseFormat(sch schema.Config) string {
       "dimensions": %s
     },
     "compareFilters": null,
+    "timeRange": null,
     "aggregation": "sum|count|avg|max|min|list|growth|ratio|none",
     "measure": "%s",
     "measures": [],
     "groupBy": [],
-    "sortBy": "value_desc|value_asc|date_asc|date_desc|alpha_asc",
+    "sortBy": "value_desc|value_asc|date_asc|date_desc|alpha_asc|ordinal",
+    "sortKeys": [],
     "limit": 0,
     "visualize": "bar|line|pie|stacked_bar|area|table|text",
     "title": "Chart or table title",
@@ -249,6 +277,12 @@ func buildQuerySpecRules(sch schema.Config) string {
 TEMPORAL DIMENSIONS: %s
 - Use these for time-series queries, trends, and growth analysis.
 - Sort by "date_asc" for chronological, "date_desc" for reverse.
+- For relative time ("last quarter", "YTD", "past 30 days", "since March") set "timeRange" — do NOT compute dates or list months in "filters".
+  The engine resolves timeRange against the current date.
+  - {"relative": "today|yesterday|this_week|last_week|this_month|last_month|this_quarter|last_quarter|this_year|last_year|mtd|qtd|ytd"}
+  - {"last": 30, "unit": "day|week|month|quarter|year"} — the current unit plus the N-1 before it
+  - {"since": "March"} or {"since": "2026-03", "until": "2026-06"} — until is inclusive
+  - Add "dimension": "<key>" when the temporal dimension is not "month"
 `, strings.Join(temporalDims, ", "))
 	}
 
@@ -264,11 +298,14 @@ TEMPORAL DIMENSIONS: %s
    - Empty array = no filter (include all values for that dimension)
    - Values must match from the DATA SUMMARY above
    - Filters are AND across dimensions, OR within a dimension
+   - Use "(blank)" to select records where the dimension is empty
 
 3. "aggregation" — how to combine records:
    - "sum" → total (default for "how much" queries)
    - "count" → number of records ("how many")
-   - "avg" → average value
+   - "distinct" → number of different values of "measure", which may name a dimension ("how many different", "unique")
+   - "avg" → average value (blank values are skipped)
+   - "median" → middle value ("median", "typical")
    - "max" → largest value ("biggest", "highest", "largest")
    - "min" → smallest value ("smallest", "lowest")
    - "list" → no aggregation, show individual records ("show all", "list")
@@ -294,6 +331,11 @@ TEMPORAL DIMENSIONS: %s
    - "date_asc" → chronological (for time series)
    - "date_desc" → reverse chronological
    - "alpha_asc" → alphabetical
+   - "ordinal" → natural order of the grouped dimension (see [ORDER] on dimensions, e.g. P1 before P2)
+   - "sortKeys" (optional, overrides sortBy) → several keys in turn, e.g.
+     [{"by":"dimension","dimension":"<parent>"}, {"by":"value","desc":true}] — by parent, then by value
+     [{"by":"measure","measure":"<other measure>","desc":true}] — order by a measure other than the plotted one
+     "by": "value|count|label|date|ordinal|dimension|measure"
 
 8. "limit" — max results (0 = all)
 
@@ -358,6 +400,12 @@ func buildExampleTranslations(sch schema.Config) string {
 		b.WriteString(fmt.Sprintf("- \"trend over time\" → groupBy:[\"%s\"], intent:\"chart\", visualize:\"line\", sortBy:\"date_asc\"\n",
 			temporalDim))
 		b.WriteString(fmt.Sprintf("- \"has it increased?\" → intent:\"text\", aggregation:\"growth\"\n"))
+		timeRangeDim := ""
+		if temporalDim != "month" {
+			timeRangeDim = fmt.Sprintf(", \"dimension\":\"%s\"", temporalDim)
+		}
+		b.WriteString(fmt.Sprintf("- \"total last quarter\" → intent:\"text\", aggregation:\"sum\", timeRange:{\"relative\":\"last_quarter\"%s}\n", timeRangeDim))
+		b.WriteString(fmt.Sprintf("- \"past 30 days\" → timeRange:{\"last\":30, \"unit\":\"day\"%s}\n", timeRangeDim))
 	}
 	if firstDim != "" {
 		b.WriteString(fmt.Sprintf("- \"total %s\" → intent:\"text\", aggregation:\"sum\", measure:\"%s\"\n",
@@ -366,6 +414,13 @@ func buildExampleTranslations(sch schema.Config) string {
 		b.WriteString(fmt.Sprintf("- \"top 5 by %s\" → groupBy:[\"%s\"], sortBy:\"value_desc\", limit:5\n",
 			firstDim, firstDim))
 	}
+	for _, d := range sch.Dimensions {
+		if d.Order() != nil {
+			b.WriteString(fmt.Sprintf("- \"count by %s\" → groupBy:[\"%s\"], aggregation:\"count\", sortBy:\"ordinal\"\n",
+				d.Key, d.Key))
+			break
+		}
+	}
 	if firstDim != "" && secondDim != "" {
 		b.WriteString(fmt.Sprintf("- \"compare %s across %s\" → groupBy:[\"%s\", \"%s\"], intent:\"chart\", visualize:\"stacked_bar\"\n",
 			firstDim, secondDim, secondDim, firstDim))
@@ -1,6 +1,8 @@
 package translator
 
 import (
+	"context"
+
 	"github.com/spektr-org/spektr/engine"
 	"github.com/spektr-org/spektr/schema"
 )
@@ -36,6 +38,15 @@ type AIProvider interface {
 	Complete(prompt string) (string, error)
 }
 
+// ContextAIProvider is an AIProvider that can abandon a request when its
+// context is cancelled or its deadline passes. The built-in adapters
+// implement it. For providers that don't, the translator stops waiting when
+// the context ends, but the Complete call itself runs to completion.
+type ContextAIProvider interface {
+	AIProvider
+	CompleteContext(ctx context.Context, prompt string) (string, error)
+}
+
 // Translator translates natural language queries into QuerySpecs.
 type Translator interface {
 	Translate(query string, sch schema.Config) (*TranslateResult, error)